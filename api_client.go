@@ -17,41 +17,100 @@ package genai
 import (
 	"bufio"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"iter"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type apiClient struct {
 	clientConfig *ClientConfig
+	// modelInfo caches the *ModelInfo returned by Models.Get by model resource name.
+	modelInfo sync.Map
+	// modelNames caches the resource names returned by tModel by model, up to
+	// maxCachedModelNames of them.
+	modelNames    sync.Map
+	modelNamesLen atomic.Int32
+
+	closeCtx    context.Context    // Done when the client is closed. Nil in tests.
+	cancelClose context.CancelFunc // Cancels closeCtx.
+	closeOnce   sync.Once          // Runs Client.Close once.
+
+	backgroundMu     sync.Mutex     // Guards backgroundClosed and background.Add.
+	backgroundClosed bool           // Set by Client.Close. No goroutine is started after.
+	background       sync.WaitGroup // Goroutines stopped by Client.Close.
+}
+
+// closed reports whether Client.Close was called.
+func (ac *apiClient) closed() bool {
+	return ac.closeCtx != nil && ac.closeCtx.Err() != nil
+}
+
+// goBackground runs f in a goroutine that Client.Close waits for, and reports
+// whether it was started. It is not started once the client is closed.
+func (ac *apiClient) goBackground(f func()) bool {
+	ac.backgroundMu.Lock()
+	defer ac.backgroundMu.Unlock()
+	if ac.backgroundClosed {
+		return false
+	}
+	ac.background.Add(1)
+	go func() {
+		defer ac.background.Done()
+		f()
+	}()
+	return true
 }
 
 // sendStreamRequest issues an server streaming API request and returns a map of the response contents.
-func sendStreamRequest[T responseStream[R], R any](ctx context.Context, ac *apiClient, path string, method string, body any, output *responseStream[R]) error {
-	req, err := buildRequest(ac, path, body, method)
+func sendStreamRequest[T responseStream[R], R any](ctx context.Context, ac *apiClient, path string, method string, body any, httpOptions *HTTPOptions, output *responseStream[R]) error {
+	if httpOptions == nil {
+		httpOptions = mergeHTTPOptions(ac.clientConfig, nil)
+	}
+	ctx, cancel := withRequestTimeout(ctx, httpOptions)
+	req, err := buildRequest(ctx, ac, path, body, method, httpOptions)
 	if err != nil {
+		cancel()
 		return err
 	}
 
 	resp, err := doRequest(ctx, ac, req)
 	if err != nil {
+		cancel()
 		return err
 	}
+	// The request context must stay alive until the stream is consumed, so it is
+	// released together with the response body.
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
 
 	// resp.Body will be closed by the iterator
-	return deserializeStreamResponse(resp, output)
+	if err := deserializeStreamResponse(ctx, resp, httpOptions.StreamFormat, output); err != nil {
+		resp.Body.Close()
+		return err
+	}
+	output.logger = ac.logger()
+	return nil
 }
 
 // sendRequest issues an API request and returns a map of the response contents.
-func sendRequest(ctx context.Context, ac *apiClient, path string, method string, body any) (map[string]any, error) {
-	req, err := buildRequest(ac, path, body, method)
+func sendRequest(ctx context.Context, ac *apiClient, path string, method string, body any, httpOptions *HTTPOptions) (map[string]any, error) {
+	if httpOptions == nil {
+		httpOptions = mergeHTTPOptions(ac.clientConfig, nil)
+	}
+	ctx, cancel := withRequestTimeout(ctx, httpOptions)
+	defer cancel()
+	req, err := buildRequest(ctx, ac, path, body, method, httpOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -65,6 +124,62 @@ func sendRequest(ctx context.Context, ac *apiClient, path string, method string,
 	return deserializeUnaryResponse(resp)
 }
 
+// mergeHTTPOptions returns the client-level HTTP options of cc overridden by the
+// non-zero fields of the per-request options. ExtraHeaders are merged, with the
+// per-request value winning for a header set at both levels.
+func mergeHTTPOptions(cc *ClientConfig, requestOptions *HTTPOptions) *HTTPOptions {
+	merged := cc.HTTPOptions
+	if requestOptions == nil {
+		return &merged
+	}
+	if requestOptions.BaseURL != "" {
+		merged.BaseURL = requestOptions.BaseURL
+	}
+	if requestOptions.APIVersion != "" {
+		merged.APIVersion = requestOptions.APIVersion
+	}
+	if requestOptions.Timeout > 0 {
+		merged.Timeout = requestOptions.Timeout
+	}
+	if requestOptions.StreamFormat != "" {
+		merged.StreamFormat = requestOptions.StreamFormat
+	}
+	if requestOptions.CompressRequests {
+		merged.CompressRequests = true
+	}
+	if len(requestOptions.ExtraHeaders) > 0 {
+		headers := make(map[string]string, len(cc.HTTPOptions.ExtraHeaders)+len(requestOptions.ExtraHeaders))
+		for k, v := range cc.HTTPOptions.ExtraHeaders {
+			headers[http.CanonicalHeaderKey(k)] = v
+		}
+		for k, v := range requestOptions.ExtraHeaders {
+			headers[http.CanonicalHeaderKey(k)] = v
+		}
+		merged.ExtraHeaders = headers
+	}
+	return &merged
+}
+
+// withRequestTimeout derives a context that expires after the timeout in httpOptions,
+// if any.
+func withRequestTimeout(ctx context.Context, httpOptions *HTTPOptions) (context.Context, context.CancelFunc) {
+	if httpOptions.Timeout > 0 {
+		return context.WithTimeout(ctx, time.Duration(httpOptions.Timeout)*time.Millisecond)
+	}
+	return context.WithCancel(ctx)
+}
+
+// cancelOnCloseBody cancels the request context once the response body is closed.
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
 func mapToStruct[R any](input map[string]any, output *R) error {
 	b := new(bytes.Buffer)
 	err := json.NewEncoder(b).Encode(input)
@@ -78,18 +193,22 @@ func mapToStruct[R any](input map[string]any, output *R) error {
 	return nil
 }
 
-func (ac *apiClient) createAPIURL(suffix string) (*url.URL, error) {
+func (ac *apiClient) createAPIURL(suffix string, httpOptions *HTTPOptions) (*url.URL, error) {
+	apiVersion := httpOptions.APIVersion
+	if apiVersion == "" {
+		apiVersion = defaultAPIVersion(ac.clientConfig.Backend)
+	}
 	if ac.clientConfig.Backend == BackendVertexAI {
 		if !strings.HasPrefix(suffix, "projects/") {
 			suffix = fmt.Sprintf("projects/%s/locations/%s/%s", ac.clientConfig.Project, ac.clientConfig.Location, suffix)
 		}
-		u, err := url.Parse(fmt.Sprintf("%s/%s/%s", ac.clientConfig.HTTPOptions.BaseURL, ac.clientConfig.HTTPOptions.APIVersion, suffix))
+		u, err := url.Parse(fmt.Sprintf("%s/%s/%s", httpOptions.BaseURL, apiVersion, suffix))
 		if err != nil {
 			return nil, fmt.Errorf("createAPIURL: error parsing Vertex AI URL: %w", err)
 		}
 		return u, nil
 	} else {
-		u, err := url.Parse(fmt.Sprintf("%s/%s/%s", ac.clientConfig.HTTPOptions.BaseURL, ac.clientConfig.HTTPOptions.APIVersion, suffix))
+		u, err := url.Parse(fmt.Sprintf("%s/%s/%s", httpOptions.BaseURL, apiVersion, suffix))
 		if err != nil {
 			return nil, fmt.Errorf("createAPIURL: error parsing ML Dev URL: %w", err)
 		}
@@ -97,22 +216,58 @@ func (ac *apiClient) createAPIURL(suffix string) (*url.URL, error) {
 	}
 }
 
-func buildRequest(ac *apiClient, path string, body any, method string) (*http.Request, error) {
-	url, err := ac.createAPIURL(path)
+func buildRequest(ctx context.Context, ac *apiClient, path string, body any, method string, httpOptions *HTTPOptions) (*http.Request, error) {
+	url, err := ac.createAPIURL(path, httpOptions)
 	if err != nil {
 		return nil, err
 	}
 	b := new(bytes.Buffer)
-	if err := json.NewEncoder(b).Encode(body); err != nil {
+	if httpOptions.CompressRequests {
+		zw := gzip.NewWriter(b)
+		if err := json.NewEncoder(zw).Encode(body); err != nil {
+			return nil, fmt.Errorf("buildRequest: error encoding body %#v: %w", body, err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("buildRequest: error compressing body: %w", err)
+		}
+	} else if err := json.NewEncoder(b).Encode(body); err != nil {
 		return nil, fmt.Errorf("buildRequest: error encoding body %#v: %w", body, err)
 	}
 	// Create a new HTTP request
-	req, err := http.NewRequest(method, url.String(), b)
+	req, err := http.NewRequestWithContext(ctx, method, url.String(), b)
 	if err != nil {
 		return nil, err
 	}
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
+	if httpOptions.CompressRequests {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	setClientHeaders(req, ac)
+	setExtraHeaders(req.Header, httpOptions.ExtraHeaders)
+	return req, nil
+}
+
+// reservedHeaders are the headers set by the SDK that ExtraHeaders cannot overwrite.
+var reservedHeaders = map[string]bool{
+	"Content-Type":     true,
+	"Content-Encoding": true,
+	"Authorization":    true,
+	"X-Goog-Api-Key":   true,
+}
+
+// setExtraHeaders adds the user-provided headers to header, skipping the reserved ones.
+func setExtraHeaders(header http.Header, extraHeaders map[string]string) {
+	for k, v := range extraHeaders {
+		if reservedHeaders[http.CanonicalHeaderKey(k)] {
+			continue
+		}
+		header.Set(k, v)
+	}
+}
+
+// setClientHeaders sets the authentication and SDK identification headers on req.
+func setClientHeaders(req *http.Request, ac *apiClient) {
 	if ac.clientConfig.APIKey != "" {
 		req.Header.Set("x-goog-api-key", ac.clientConfig.APIKey)
 	}
@@ -132,17 +287,62 @@ func buildRequest(ac *apiClient, path string, body any, method string) (*http.Re
 	} else {
 		req.Header["x-goog-api-client"] = []string{versionHeaderValue}
 	}
-	return req, nil
 }
 
 func doRequest(ctx context.Context, ac *apiClient, req *http.Request) (*http.Response, error) {
+	if ac.closed() {
+		return nil, ErrClientClosed
+	}
 	// Create a new HTTP client and send the request
 	client := ac.clientConfig.HTTPClient
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("doRequest: error sending request: %w", err)
+	retryConfig := ac.clientConfig.RetryConfig
+	middlewares := ac.clientConfig.Middlewares
+	for attempt := 1; ; attempt++ {
+		if limiter := ac.clientConfig.RateLimiter; limiter != nil {
+			if err := limiter.Wait(ctx); err != nil {
+				return nil, fmt.Errorf("doRequest: rate limiter: %w", err)
+			}
+		}
+		if err := processRequest(middlewares, req); err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			ac.logger().DebugContext(ctx, "genai: HTTP request failed", "method", req.Method, "url", req.URL.String(), "error", err)
+			return nil, fmt.Errorf("doRequest: error sending request: %w", err)
+		}
+		ac.logger().DebugContext(ctx, "genai: HTTP request", "method", req.Method, "url", req.URL.String(), "status_code", resp.StatusCode)
+		if err := processResponse(middlewares, resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if retryConfig == nil || attempt >= retryConfig.maxAttempts() || !isRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		// Drain the body so that the underlying connection can be reused.
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := sleepContext(ctx, retryConfig.delay(attempt)); err != nil {
+			return nil, fmt.Errorf("doRequest: retry interrupted: %w", err)
+		}
+		req, err = rewindRequest(req)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return resp, nil
+}
+
+// rewindRequest returns a copy of req with a fresh body so that it can be sent again.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("rewindRequest: error resetting request body: %w", err)
+		}
+		r.Body = body
+	}
+	return r, nil
 }
 
 func deserializeUnaryResponse(resp *http.Response) (map[string]any, error) {
@@ -165,6 +365,11 @@ func deserializeUnaryResponse(resp *http.Response) (map[string]any, error) {
 type responseStream[R any] struct {
 	r  *bufio.Scanner
 	rc io.ReadCloser
+	// ctx is the context of the request. The iteration stops once it is done.
+	ctx context.Context
+	// ndjson is set if each chunk is a bare JSON object rather than a server-sent event.
+	ndjson bool
+	logger *slog.Logger
 }
 
 func iterateResponseStream[R any](rs *responseStream[R], responseConverter func(responseMap map[string]any) (*R, error)) iter.Seq2[*R, error] {
@@ -172,15 +377,30 @@ func iterateResponseStream[R any](rs *responseStream[R], responseConverter func(
 		defer func() {
 			// Close the response body range over function is done.
 			if err := rs.rc.Close(); err != nil {
-				log.Printf("Error closing response body: %v", err)
+				logger := rs.logger
+				if logger == nil {
+					logger = slog.Default()
+				}
+				logger.Error("genai: error closing response body", "error", err)
 			}
 		}()
 		for rs.r.Scan() {
+			// The scanner may hold several buffered events when the context is
+			// canceled, which are dropped rather than yielded.
+			if err := rs.ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
 			line := rs.r.Bytes()
 			if len(line) == 0 {
 				continue
 			}
-			prefix, data, _ := bytes.Cut(line, []byte(":"))
+			var prefix, data []byte
+			if rs.ndjson {
+				prefix, data = []byte("data"), line
+			} else {
+				prefix, data, _ = bytes.Cut(line, []byte(":"))
+			}
 			switch string(prefix) {
 			case "data":
 				// Step 1: Unmarshal the JSON into a map[string]any so that we can call fromConverter
@@ -213,6 +433,10 @@ func iterateResponseStream[R any](rs *responseStream[R], responseConverter func(
 				}
 			}
 		}
+		// The stream ended early, for example because the context was canceled.
+		if err := rs.r.Err(); err != nil {
+			yield(nil, err)
+		}
 	}
 }
 
@@ -231,28 +455,51 @@ func newAPIError(resp *http.Response) error {
 	var respWithError = new(responseWithError)
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("newAPIError: error reading response body: %w. Response: %v", err, string(body))
+		return newStatusError(resp, apiError{Message: fmt.Sprintf("error reading response body: %v", err)}, err)
 	}
 
 	if len(body) > 0 {
 		if err := json.Unmarshal(body, respWithError); err != nil {
-			return fmt.Errorf("newAPIError: unmarshal response to error failed: %w. Response: %v", err, string(body))
+			return newStatusError(resp, apiError{Message: string(body)}, err)
 		}
-		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-			return ClientError{apiError: *respWithError.ErrorInfo}
+		if respWithError.ErrorInfo != nil {
+			return newStatusError(resp, *respWithError.ErrorInfo, nil)
 		}
-		return ServerError{apiError: *respWithError.ErrorInfo}
+	}
+	return newStatusError(resp, apiError{}, nil)
+}
+
+// newStatusError returns a ClientError or a ServerError depending on the status code
+// of resp. The code and status of info default to the ones of resp. underlying is the
+// error, if any, that prevented the error body from being read.
+func newStatusError(resp *http.Response, info apiError, underlying error) error {
+	if info.Code == 0 {
+		info.Code = resp.StatusCode
+	}
+	if info.Status == "" {
+		info.Status = resp.Status
 	}
 	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
-		return ClientError{apiError: apiError{Code: resp.StatusCode, Status: resp.Status}}
+		return ClientError{apiError: info, underlying: underlying}
 	}
-	return ServerError{apiError: apiError{Code: resp.StatusCode, Status: resp.Status}}
+	return ServerError{apiError: info, underlying: underlying}
+}
+
+// newInvalidArgumentError returns a ClientError for a request that the SDK rejects
+// before sending it, as the API would with an INVALID_ARGUMENT error.
+func newInvalidArgumentError(format string, args ...any) error {
+	return ClientError{apiError: apiError{
+		Code:    http.StatusBadRequest,
+		Message: fmt.Sprintf(format, args...),
+		Status:  "INVALID_ARGUMENT",
+	}}
 }
 
 // ClientError is an error that occurs when the GenAI API
 // receives an invalid request from a client.
 type ClientError struct {
 	apiError
+	underlying error
 }
 
 // Error returns a string representation of the ClientError.
@@ -263,10 +510,17 @@ func (e ClientError) Error() string {
 	)
 }
 
+// Unwrap returns the error that prevented the error response from being read, or
+// nil if it was read successfully.
+func (e ClientError) Unwrap() error {
+	return e.underlying
+}
+
 // ServerError is an error that occurs when the GenAI API
 // encounters an unexpected server problem.
 type ServerError struct {
 	apiError
+	underlying error
 }
 
 // Error returns a string representation of the ServerError.
@@ -277,20 +531,114 @@ func (e ServerError) Error() string {
 	)
 }
 
+// Unwrap returns the error that prevented the error response from being read, or
+// nil if it was read successfully.
+func (e ServerError) Unwrap() error {
+	return e.underlying
+}
+
+// Sentinel errors to match the errors returned by the API with errors.Is. They
+// match any [ClientError] or [ServerError] with the same HTTP status code.
+var (
+	ErrInvalidArgument  error = ClientError{apiError: apiError{Code: http.StatusBadRequest}}
+	ErrPermissionDenied error = ClientError{apiError: apiError{Code: http.StatusForbidden}}
+	ErrNotFound         error = ClientError{apiError: apiError{Code: http.StatusNotFound}}
+	ErrQuotaExceeded    error = ClientError{apiError: apiError{Code: http.StatusTooManyRequests}}
+	ErrInternal         error = ServerError{apiError: apiError{Code: http.StatusInternalServerError}}
+	ErrUnavailable      error = ServerError{apiError: apiError{Code: http.StatusServiceUnavailable}}
+)
+
+// ErrClientClosed is returned by the methods of a client after Client.Close.
+var ErrClientClosed = errors.New("genai: client is closed")
+
+// matches reports whether e has the code of target, and its status if target has
+// one.
+func (e apiError) matches(target apiError) bool {
+	return e.Code == target.Code && (target.Status == "" || e.Status == target.Status)
+}
+
+// Is reports whether target is a ClientError with the same code, and the same status
+// if target has one. It makes errors.Is(err, ErrNotFound) work.
+func (e ClientError) Is(target error) bool {
+	switch t := target.(type) {
+	case ClientError:
+		return e.matches(t.apiError)
+	case *ClientError:
+		return t != nil && e.matches(t.apiError)
+	}
+	return false
+}
+
+// Is reports whether target is a ServerError with the same code, and the same status
+// if target has one. It makes errors.Is(err, ErrInternal) work.
+func (e ServerError) Is(target error) bool {
+	switch t := target.(type) {
+	case ServerError:
+		return e.matches(t.apiError)
+	case *ServerError:
+		return t != nil && e.matches(t.apiError)
+	}
+	return false
+}
+
+// IsClientError reports whether any error in err's tree is a [ClientError].
+func IsClientError(err error) bool {
+	var clientError ClientError
+	return errors.As(err, &clientError)
+}
+
+// IsServerError reports whether any error in err's tree is a [ServerError].
+func IsServerError(err error) bool {
+	var serverError ServerError
+	return errors.As(err, &serverError)
+}
+
+// logger returns ClientConfig.Logger, or slog.Default() if it is nil.
+func (ac *apiClient) logger() *slog.Logger {
+	if ac.clientConfig.Logger != nil {
+		return ac.clientConfig.Logger
+	}
+	return slog.Default()
+}
+
 func httpStatusOk(resp *http.Response) bool {
 	return resp.StatusCode >= 200 && resp.StatusCode < 300
 }
 
-func deserializeStreamResponse[T responseStream[R], R any](resp *http.Response, output *responseStream[R]) error {
+func deserializeStreamResponse[T responseStream[R], R any](ctx context.Context, resp *http.Response, format StreamFormat, output *responseStream[R]) error {
 	if !httpStatusOk(resp) {
 		return newAPIError(resp)
 	}
-	output.r = bufio.NewScanner(resp.Body)
-	output.r.Split(scan)
+	output.r = bufio.NewScanner(&contextReader{ctx: ctx, r: resp.Body})
+	if format == StreamFormatNDJSON {
+		output.r.Split(bufio.ScanLines)
+		output.ndjson = true
+	} else {
+		output.r.Split(scan)
+	}
 	output.rc = resp.Body
+	output.ctx = ctx
 	return nil
 }
 
+// contextReader is a reader that fails with the error of ctx once ctx is done, so
+// that a stream read interrupted by the cancellation of the request reports why.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n, err := r.r.Read(p)
+	if err != nil && r.ctx.Err() != nil {
+		return n, r.ctx.Err()
+	}
+	return n, err
+}
+
 // dropCR drops a terminal \r from the data.
 func dropCR(data []byte) []byte {
 	if len(data) > 0 && data[len(data)-1] == '\r' {
@@ -1,12 +1,20 @@
 package genai
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -110,7 +118,7 @@ func TestSendRequest(t *testing.T) {
 				},
 			}
 
-			got, err := sendRequest(ctx, ac, tt.path, tt.method, tt.requestBody)
+			got, err := sendRequest(ctx, ac, tt.path, tt.method, tt.requestBody, nil)
 
 			if (err != nil) != (tt.wantErr != nil) {
 				t.Errorf("sendRequest() error = %v, wantErr %v", err, tt.wantErr)
@@ -148,3 +156,503 @@ func TestSendRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestSendRequestExtraHeaders(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc          string
+		clientHeaders map[string]string
+		requestOpts   *HTTPOptions
+		want          map[string]string
+	}{
+		{
+			desc:          "client headers",
+			clientHeaders: map[string]string{"X-Experiment-ID": "exp-1"},
+			want:          map[string]string{"X-Experiment-Id": "exp-1"},
+		},
+		{
+			desc:          "request headers are added to client headers",
+			clientHeaders: map[string]string{"X-Experiment-ID": "exp-1"},
+			requestOpts:   &HTTPOptions{ExtraHeaders: map[string]string{"X-Request-ID": "req-1"}},
+			want:          map[string]string{"X-Experiment-Id": "exp-1", "X-Request-Id": "req-1"},
+		},
+		{
+			desc:          "request headers take precedence",
+			clientHeaders: map[string]string{"X-Experiment-ID": "exp-1"},
+			requestOpts:   &HTTPOptions{ExtraHeaders: map[string]string{"x-experiment-id": "exp-2"}},
+			want:          map[string]string{"X-Experiment-Id": "exp-2"},
+		},
+		{
+			desc: "reserved headers are not overwritten",
+			requestOpts: &HTTPOptions{ExtraHeaders: map[string]string{
+				"content-type":   "text/plain",
+				"Authorization":  "Bearer fake",
+				"X-Goog-Api-Key": "other-key",
+			}},
+			want: map[string]string{"Content-Type": "application/json", "Authorization": "", "X-Goog-Api-Key": "test-api-key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got http.Header
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				fmt.Fprintln(w, `{}`)
+			}))
+			defer ts.Close()
+
+			ac := &apiClient{
+				clientConfig: &ClientConfig{
+					APIKey: "test-api-key",
+					HTTPOptions: HTTPOptions{
+						BaseURL:      ts.URL,
+						ExtraHeaders: tt.clientHeaders,
+					},
+					HTTPClient: ts.Client(),
+				},
+			}
+			httpOptions := mergeHTTPOptions(ac.clientConfig, tt.requestOpts)
+			if _, err := sendRequest(ctx, ac, "foo", http.MethodPost, map[string]any{}, httpOptions); err != nil {
+				t.Fatalf("sendRequest() failed: %v", err)
+			}
+			for k, want := range tt.want {
+				if v := got.Get(k); v != want {
+					t.Errorf("header %s = %q, want %q", k, v, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSendRequestCompression(t *testing.T) {
+	ctx := context.Background()
+	body := map[string]any{"contents": []any{map[string]any{"parts": []any{map[string]any{"text": strings.Repeat("Hello ", 100)}}}}}
+	tests := []struct {
+		desc          string
+		clientOptions HTTPOptions
+		requestOpts   *HTTPOptions
+		wantGzip      bool
+	}{
+		{desc: "disabled", wantGzip: false},
+		{desc: "client option", clientOptions: HTTPOptions{CompressRequests: true}, wantGzip: true},
+		{desc: "request option", requestOpts: &HTTPOptions{CompressRequests: true}, wantGzip: true},
+		{
+			desc:          "extra header cannot change the encoding",
+			clientOptions: HTTPOptions{CompressRequests: true},
+			requestOpts:   &HTTPOptions{ExtraHeaders: map[string]string{"Content-Encoding": "identity"}},
+			wantGzip:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotEncoding string
+			var gotBody map[string]any
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotEncoding = r.Header.Get("Content-Encoding")
+				var reader io.Reader = r.Body
+				if gotEncoding == "gzip" {
+					zr, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Errorf("gzip.NewReader() failed: %v", err)
+						return
+					}
+					reader = zr
+				}
+				if err := json.NewDecoder(reader).Decode(&gotBody); err != nil {
+					t.Errorf("decoding request body failed: %v", err)
+				}
+				fmt.Fprintln(w, `{}`)
+			}))
+			defer ts.Close()
+
+			tt.clientOptions.BaseURL = ts.URL
+			ac := &apiClient{clientConfig: &ClientConfig{HTTPOptions: tt.clientOptions, HTTPClient: ts.Client()}}
+			httpOptions := mergeHTTPOptions(ac.clientConfig, tt.requestOpts)
+			if _, err := sendRequest(ctx, ac, "foo", http.MethodPost, body, httpOptions); err != nil {
+				t.Fatalf("sendRequest() failed: %v", err)
+			}
+			if got := gotEncoding == "gzip"; got != tt.wantGzip {
+				t.Errorf("Content-Encoding = %q, want gzip: %v", gotEncoding, tt.wantGzip)
+			}
+			if diff := cmp.Diff(body, gotBody); diff != "" {
+				t.Errorf("request body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// BenchmarkSendRequestCompression sends a 1 MB conversation history and reports the
+// number of bytes sent on the wire per request.
+func BenchmarkSendRequestCompression(b *testing.B) {
+	ctx := context.Background()
+	var contents []any
+	for size := 0; size < 1<<20; {
+		text := fmt.Sprintf("Turn %d: tell me more about the history of the city, its landmarks and its food.", len(contents))
+		contents = append(contents, map[string]any{"role": RoleUser, "parts": []any{map[string]any{"text": text}}})
+		size += len(text) + 40
+	}
+	body := map[string]any{"contents": contents}
+
+	for _, compress := range []bool{false, true} {
+		b.Run(fmt.Sprintf("compress=%v", compress), func(b *testing.B) {
+			var wireBytes int64
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n, _ := io.Copy(io.Discard, r.Body)
+				wireBytes += n
+				fmt.Fprintln(w, `{}`)
+			}))
+			defer ts.Close()
+			ac := &apiClient{clientConfig: &ClientConfig{HTTPOptions: HTTPOptions{BaseURL: ts.URL, CompressRequests: compress}, HTTPClient: ts.Client()}}
+			httpOptions := mergeHTTPOptions(ac.clientConfig, nil)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := sendRequest(ctx, ac, "foo", http.MethodPost, body, httpOptions); err != nil {
+					b.Fatalf("sendRequest() failed: %v", err)
+				}
+			}
+			b.ReportMetric(float64(wireBytes)/float64(b.N), "wire-bytes/op")
+		})
+	}
+}
+
+func TestSendRequestRetry(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc         string
+		retryConfig  *RetryConfig
+		statusCodes  []int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			desc:         "no retry config",
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			desc:         "retries 503 until success",
+			retryConfig:  &RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond},
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			wantAttempts: 3,
+		},
+		{
+			desc:         "retries 429",
+			retryConfig:  &RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond},
+			statusCodes:  []int{http.StatusTooManyRequests, http.StatusOK},
+			wantAttempts: 2,
+		},
+		{
+			desc:         "gives up after max attempts",
+			retryConfig:  &RetryConfig{MaxAttempts: 2, InitialDelay: time.Millisecond},
+			statusCodes:  []int{http.StatusInternalServerError, http.StatusInternalServerError, http.StatusOK},
+			wantAttempts: 2,
+			wantErr:      true,
+		},
+		{
+			desc:         "does not retry client errors",
+			retryConfig:  &RetryConfig{MaxAttempts: 3, InitialDelay: time.Millisecond},
+			statusCodes:  []int{http.StatusBadRequest, http.StatusOK},
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			attempts := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if got, want := strings.TrimSpace(string(body)), `{"key":"value"}`; got != want {
+					t.Errorf("attempt %d: request body = %q, want %q", attempts+1, got, want)
+				}
+				code := tt.statusCodes[attempts]
+				attempts++
+				w.WriteHeader(code)
+				if code != http.StatusOK {
+					fmt.Fprintf(w, `{"error": {"code": %d}}`, code)
+					return
+				}
+				fmt.Fprint(w, `{"response": "ok"}`)
+			}))
+			defer ts.Close()
+
+			ac := &apiClient{
+				clientConfig: &ClientConfig{
+					HTTPOptions: HTTPOptions{BaseURL: ts.URL},
+					HTTPClient:  ts.Client(),
+					RetryConfig: tt.retryConfig,
+				},
+			}
+			_, err := sendRequest(ctx, ac, "foo", http.MethodPost, map[string]any{"key": "value"}, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("sendRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("got %d attempts, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+
+	t.Run("context cancellation stops waiting", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer ts.Close()
+
+		ac := &apiClient{
+			clientConfig: &ClientConfig{
+				HTTPOptions: HTTPOptions{BaseURL: ts.URL},
+				HTTPClient:  ts.Client(),
+				RetryConfig: &RetryConfig{MaxAttempts: 5, InitialDelay: time.Hour},
+			},
+		}
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		_, err := sendRequest(ctx, ac, "foo", http.MethodPost, map[string]any{"key": "value"}, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("sendRequest() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+	})
+}
+
+func TestRetryConfigDelay(t *testing.T) {
+	rc := &RetryConfig{InitialDelay: 100 * time.Millisecond, MaxDelay: time.Second, Multiplier: 3}
+	tests := []struct {
+		attempt int
+		want    time.Duration // upper bound before jitter
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 300 * time.Millisecond},
+		{attempt: 3, want: 900 * time.Millisecond},
+		{attempt: 4, want: time.Second},
+		{attempt: 10, want: time.Second},
+	}
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			got := rc.delay(tt.attempt)
+			if got < tt.want/2 || got > tt.want {
+				t.Fatalf("delay(%d) = %v, want in [%v, %v]", tt.attempt, got, tt.want/2, tt.want)
+			}
+		}
+	}
+
+	defaults := &RetryConfig{}
+	if got := defaults.maxAttempts(); got != defaultRetryMaxAttempts {
+		t.Errorf("maxAttempts() = %d, want %d", got, defaultRetryMaxAttempts)
+	}
+	if got := defaults.delay(1); got < defaultRetryInitialDelay/2 || got > defaultRetryInitialDelay {
+		t.Errorf("delay(1) = %v, want in [%v, %v]", got, defaultRetryInitialDelay/2, defaultRetryInitialDelay)
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	notFound := ClientError{apiError: apiError{Code: http.StatusNotFound, Message: "model not found", Status: "NOT_FOUND"}}
+	quota := ClientError{apiError: apiError{Code: http.StatusTooManyRequests, Status: "RESOURCE_EXHAUSTED"}}
+	internal := ServerError{apiError: apiError{Code: http.StatusInternalServerError, Status: "INTERNAL"}}
+
+	tests := []struct {
+		desc   string
+		err    error
+		target error
+		want   bool
+	}{
+		{desc: "not found sentinel", err: notFound, target: ErrNotFound, want: true},
+		{desc: "wrapped not found sentinel", err: fmt.Errorf("get model: %w", notFound), target: ErrNotFound, want: true},
+		{desc: "quota sentinel", err: quota, target: ErrQuotaExceeded, want: true},
+		{desc: "internal sentinel", err: internal, target: ErrInternal, want: true},
+		{desc: "invalid argument sentinel", err: ClientError{apiError: apiError{Code: http.StatusBadRequest}}, target: ErrInvalidArgument, want: true},
+		{desc: "permission denied sentinel", err: ClientError{apiError: apiError{Code: http.StatusForbidden}}, target: ErrPermissionDenied, want: true},
+		{desc: "unavailable sentinel", err: ServerError{apiError: apiError{Code: http.StatusServiceUnavailable}}, target: ErrUnavailable, want: true},
+		{desc: "different code", err: notFound, target: ErrQuotaExceeded, want: false},
+		{desc: "client error is not a server error", err: ClientError{apiError: apiError{Code: http.StatusInternalServerError}}, target: ErrInternal, want: false},
+		{desc: "matching status", err: notFound, target: ClientError{apiError: apiError{Code: http.StatusNotFound, Status: "NOT_FOUND"}}, want: true},
+		{desc: "different status", err: notFound, target: ClientError{apiError: apiError{Code: http.StatusNotFound, Status: "OTHER"}}, want: false},
+		{desc: "pointer target", err: internal, target: &ServerError{apiError: apiError{Code: http.StatusInternalServerError}}, want: true},
+		{desc: "unrelated error", err: errors.New("boom"), target: ErrNotFound, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := errors.Is(tt.err, tt.target); got != tt.want {
+				t.Errorf("errors.Is(%v, %v) = %v, want %v", tt.err, tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorUnwrap(t *testing.T) {
+	var syntaxError *json.SyntaxError
+	tests := []struct {
+		desc       string
+		statusCode int
+		body       io.Reader
+		wantClient bool
+		wantIs     error
+		wantAs     any
+		wantCode   int
+	}{
+		{
+			desc:       "truncated body",
+			statusCode: http.StatusServiceUnavailable,
+			body:       iotest.ErrReader(io.ErrUnexpectedEOF),
+			wantIs:     io.ErrUnexpectedEOF,
+			wantCode:   http.StatusServiceUnavailable,
+		},
+		{
+			desc:       "invalid json",
+			statusCode: http.StatusBadRequest,
+			body:       strings.NewReader("<html>bad gateway</html>"),
+			wantClient: true,
+			wantAs:     &syntaxError,
+			wantCode:   http.StatusBadRequest,
+		},
+		{
+			desc:       "missing error field",
+			statusCode: http.StatusNotFound,
+			body:       strings.NewReader(`{"message": "not found"}`),
+			wantClient: true,
+			wantIs:     ErrNotFound,
+			wantCode:   http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := newAPIError(&http.Response{StatusCode: tt.statusCode, Body: io.NopCloser(tt.body)})
+			wrapped := fmt.Errorf("generate content: %w", err)
+
+			var code int
+			if tt.wantClient {
+				var clientError ClientError
+				if !errors.As(wrapped, &clientError) {
+					t.Fatalf("errors.As(%v, ClientError) = false, want true", wrapped)
+				}
+				code = clientError.Code
+			} else {
+				var serverError ServerError
+				if !errors.As(wrapped, &serverError) {
+					t.Fatalf("errors.As(%v, ServerError) = false, want true", wrapped)
+				}
+				code = serverError.Code
+			}
+			if code != tt.wantCode {
+				t.Errorf("Code = %d, want %d", code, tt.wantCode)
+			}
+			if tt.wantIs != nil && !errors.Is(wrapped, tt.wantIs) {
+				t.Errorf("errors.Is(%v, %v) = false, want true", wrapped, tt.wantIs)
+			}
+			if tt.wantAs != nil && !errors.As(wrapped, tt.wantAs) {
+				t.Errorf("errors.As(%v, %T) = false, want true", wrapped, tt.wantAs)
+			}
+		})
+	}
+}
+
+func TestIsClientServerError(t *testing.T) {
+	tests := []struct {
+		desc            string
+		err             error
+		wantClientError bool
+		wantServerError bool
+	}{
+		{desc: "client error", err: ClientError{apiError: apiError{Code: http.StatusBadRequest}}, wantClientError: true},
+		{desc: "wrapped client error", err: fmt.Errorf("wrapped: %w", ClientError{apiError: apiError{Code: http.StatusNotFound}}), wantClientError: true},
+		{desc: "server error", err: ServerError{apiError: apiError{Code: http.StatusInternalServerError}}, wantServerError: true},
+		{desc: "other error", err: errors.New("boom")},
+		{desc: "nil", err: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := IsClientError(tt.err); got != tt.wantClientError {
+				t.Errorf("IsClientError() = %v, want %v", got, tt.wantClientError)
+			}
+			if got := IsServerError(tt.err); got != tt.wantServerError {
+				t.Errorf("IsServerError() = %v, want %v", got, tt.wantServerError)
+			}
+		})
+	}
+
+	t.Run("errors returned by sendRequest", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": 404, "message": "not found", "status": "NOT_FOUND"}}`)
+		}))
+		defer ts.Close()
+
+		ac := &apiClient{clientConfig: &ClientConfig{HTTPOptions: HTTPOptions{BaseURL: ts.URL}, HTTPClient: ts.Client()}}
+		_, err := sendRequest(context.Background(), ac, "foo", http.MethodGet, nil, nil)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("sendRequest() error = %v, want %v", err, ErrNotFound)
+		}
+		if !IsClientError(err) {
+			t.Errorf("IsClientError(%v) = false, want true", err)
+		}
+		var clientError ClientError
+		if errors.As(err, &clientError); clientError.Status != "NOT_FOUND" {
+			t.Errorf("ClientError.Status = %q, want %q", clientError.Status, "NOT_FOUND")
+		}
+	})
+}
+
+// errCloseBody is a response body whose Close fails.
+type errCloseBody struct {
+	io.Reader
+}
+
+func (errCloseBody) Close() error { return errors.New("close failed") }
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestClientLogger(t *testing.T) {
+	ctx := context.Background()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       errCloseBody{strings.NewReader("data: {\"candidates\":[{\"content\":{\"parts\":[{\"text\":\"hi\"}]}}]}\n\n")},
+			Request:    r,
+		}, nil
+	})
+	ac := &apiClient{clientConfig: &ClientConfig{
+		Backend:     BackendGeminiAPI,
+		HTTPOptions: HTTPOptions{BaseURL: "https://test-base-url.com", APIVersion: "v1beta"},
+		HTTPClient:  &http.Client{Transport: transport},
+		Logger:      logger,
+	}}
+	m := Models{apiClient: ac}
+	for _, err := range m.GenerateContentStream(ctx, "gemini-2.0-flash", Text("hello"), nil) {
+		if err != nil {
+			t.Fatalf("GenerateContentStream() failed: %v", err)
+		}
+	}
+
+	var records []map[string]any
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var record map[string]any
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("decoding log record failed: %v", err)
+		}
+		delete(record, "time")
+		records = append(records, record)
+	}
+	want := []map[string]any{
+		{
+			"level":       "DEBUG",
+			"msg":         "genai: HTTP request",
+			"method":      "POST",
+			"url":         "https://test-base-url.com/v1beta/models/gemini-2.0-flash:streamGenerateContent?alt=sse",
+			"status_code": float64(200),
+		},
+		{"level": "ERROR", "msg": "genai: error closing response body", "error": "close failed"},
+	}
+	if diff := cmp.Diff(want, records); diff != "" {
+		t.Errorf("log records mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const defaultBatchMaxConcurrency = 10
+
+// BatchRequest is one of the prompts sent by GenerateContentBatch.
+type BatchRequest struct {
+	// Optional. Identifies the request in the results.
+	ID string
+	// Required. The contents of the GenerateContent request.
+	Contents []*Content
+	// Optional. The config of the GenerateContent request.
+	Config *GenerateContentConfig
+}
+
+// BatchConfig configures GenerateContentBatch.
+type BatchConfig struct {
+	// Optional. The maximum number of requests in flight at the same time.
+	// Defaults to 10.
+	MaxConcurrency int
+}
+
+// BatchResult is the outcome of a BatchRequest. Exactly one of Response and Err is set.
+type BatchResult struct {
+	// The ID of the request.
+	ID string
+	// The response of the request, if it succeeded.
+	Response *GenerateContentResponse
+	// The error of the request, if it failed.
+	Err error
+}
+
+// GenerateContentBatch sends independent GenerateContent requests to the model
+// concurrently, at most config.MaxConcurrency at a time. The results are returned in
+// the order of the requests. A failed request does not stop the others; its error
+// is reported in the Err field of its result. Requests that have not started when
+// ctx is done fail with ctx.Err().
+func (m Models) GenerateContentBatch(ctx context.Context, model string, requests []*BatchRequest, config *BatchConfig) ([]*BatchResult, error) {
+	return generateContentBatch(ctx, m, model, requests, config)
+}
+
+// generateContentBatch implements GenerateContentBatch with m.GenerateContent, for
+// Models and MockModels.
+func generateContentBatch(ctx context.Context, m ModelsInterface, model string, requests []*BatchRequest, config *BatchConfig) ([]*BatchResult, error) {
+	for i, r := range requests {
+		if r == nil {
+			return nil, fmt.Errorf("GenerateContentBatch: requests[%d] is nil", i)
+		}
+	}
+	maxConcurrency := defaultBatchMaxConcurrency
+	if config != nil && config.MaxConcurrency > 0 {
+		maxConcurrency = config.MaxConcurrency
+	}
+
+	results := make([]*BatchResult, len(requests))
+	sem := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+	for i, r := range requests {
+		results[i] = &BatchResult{ID: r.ID}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Response, results[i].Err = m.GenerateContent(ctx, model, r.Contents, r.Config)
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// batchReply echoes the text of the prompt, and fails with 400 if the text is "fail".
+// It records in maxInFlight the highest number of requests that were in flight at
+// the same time.
+func batchReply(maxInFlight *atomic.Int64) func(http.ResponseWriter, *http.Request, int, []*Content) {
+	var inFlight atomic.Int64
+	return func(w http.ResponseWriter, _ *http.Request, _ int, contents []*Content) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		text := contents[0].Parts[0].Text
+		if text == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"code":400,"message":"bad prompt","status":"INVALID_ARGUMENT"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"echo: %s"}]}}]}`, text)
+	}
+}
+
+func TestGenerateContentBatch(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("preserves order and reports partial failures", func(t *testing.T) {
+		ts, _ := newRecordingTestServer(t, batchReply(new(atomic.Int64)))
+		defer ts.Close()
+
+		var requests []*BatchRequest
+		for i := 0; i < 20; i++ {
+			prompt := fmt.Sprintf("prompt %d", i)
+			if i%5 == 3 {
+				prompt = "fail"
+			}
+			requests = append(requests, &BatchRequest{ID: fmt.Sprintf("id-%d", i), Contents: Text(prompt)})
+		}
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		results, err := m.GenerateContentBatch(ctx, "gemini-2.0-flash", requests, &BatchConfig{MaxConcurrency: 4})
+		if err != nil {
+			t.Fatalf("GenerateContentBatch() error = %v", err)
+		}
+		if len(results) != len(requests) {
+			t.Fatalf("got %d results, want %d", len(results), len(requests))
+		}
+		for i, r := range results {
+			if r.ID != requests[i].ID {
+				t.Errorf("results[%d].ID = %q, want %q", i, r.ID, requests[i].ID)
+			}
+			if i%5 == 3 {
+				if r.Response != nil || !errors.Is(r.Err, ErrInvalidArgument) {
+					t.Errorf("results[%d] = {Response: %v, Err: %v}, want error %v", i, r.Response, r.Err, ErrInvalidArgument)
+				}
+				continue
+			}
+			if r.Err != nil {
+				t.Errorf("results[%d].Err = %v, want nil", i, r.Err)
+				continue
+			}
+			want := fmt.Sprintf("echo: prompt %d", i)
+			if got, _ := r.Response.Text(); got != want {
+				t.Errorf("results[%d] text = %q, want %q", i, got, want)
+			}
+		}
+	})
+
+	t.Run("bounds concurrency", func(t *testing.T) {
+		var maxInFlight atomic.Int64
+		ts, _ := newRecordingTestServer(t, batchReply(&maxInFlight))
+		defer ts.Close()
+
+		var requests []*BatchRequest
+		for i := 0; i < 12; i++ {
+			requests = append(requests, &BatchRequest{Contents: Text("hello")})
+		}
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		if _, err := m.GenerateContentBatch(ctx, "gemini-2.0-flash", requests, &BatchConfig{MaxConcurrency: 3}); err != nil {
+			t.Fatalf("GenerateContentBatch() error = %v", err)
+		}
+		if got := maxInFlight.Load(); got > 3 {
+			t.Errorf("%d requests in flight, want at most 3", got)
+		}
+	})
+
+	t.Run("all failed", func(t *testing.T) {
+		ts, _ := newRecordingTestServer(t, batchReply(new(atomic.Int64)))
+		defer ts.Close()
+
+		requests := []*BatchRequest{{ID: "a", Contents: Text("fail")}, {ID: "b", Contents: Text("fail")}}
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		results, err := m.GenerateContentBatch(ctx, "gemini-2.0-flash", requests, nil)
+		if err != nil {
+			t.Fatalf("GenerateContentBatch() error = %v", err)
+		}
+		for i, r := range results {
+			if r.Err == nil {
+				t.Errorf("results[%d].Err = nil, want error", i)
+			}
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ts, _ := newRecordingTestServer(t, batchReply(new(atomic.Int64)))
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		requests := []*BatchRequest{{ID: "a", Contents: Text("hello")}, {ID: "b", Contents: Text("hello")}}
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		results, err := m.GenerateContentBatch(ctx, "gemini-2.0-flash", requests, &BatchConfig{MaxConcurrency: 1})
+		if err != nil {
+			t.Fatalf("GenerateContentBatch() error = %v", err)
+		}
+		for i, r := range results {
+			if !errors.Is(r.Err, context.Canceled) {
+				t.Errorf("results[%d].Err = %v, want %v", i, r.Err, context.Canceled)
+			}
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		m := Models{apiClient: &apiClient{clientConfig: &ClientConfig{}}}
+		results, err := m.GenerateContentBatch(ctx, "gemini-2.0-flash", nil, nil)
+		if err != nil || len(results) != 0 {
+			t.Errorf("GenerateContentBatch() = %v, %v, want no results", results, err)
+		}
+	})
+
+	t.Run("nil request", func(t *testing.T) {
+		m := Models{apiClient: &apiClient{clientConfig: &ClientConfig{}}}
+		if _, err := m.GenerateContentBatch(ctx, "gemini-2.0-flash", []*BatchRequest{nil}, nil); err == nil {
+			t.Errorf("GenerateContentBatch() succeeded, want error")
+		}
+	})
+}
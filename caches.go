@@ -19,6 +19,7 @@ package genai
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 )
 
@@ -384,6 +385,70 @@ func updateCachedContentParametersToVertex(ac *apiClient, fromObject map[string]
 	return toObject, nil
 }
 
+func listCachedContentsConfigToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromPageSize := getValueByPath(fromObject, []string{"pageSize"})
+	if fromPageSize != nil {
+		setValueByPath(parentObject, []string{"_query", "pageSize"}, fromPageSize)
+	}
+
+	fromPageToken := getValueByPath(fromObject, []string{"pageToken"})
+	if fromPageToken != nil {
+		setValueByPath(parentObject, []string{"_query", "pageToken"}, fromPageToken)
+	}
+
+	return toObject, nil
+}
+
+func listCachedContentsConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromPageSize := getValueByPath(fromObject, []string{"pageSize"})
+	if fromPageSize != nil {
+		setValueByPath(parentObject, []string{"_query", "pageSize"}, fromPageSize)
+	}
+
+	fromPageToken := getValueByPath(fromObject, []string{"pageToken"})
+	if fromPageToken != nil {
+		setValueByPath(parentObject, []string{"_query", "pageToken"}, fromPageToken)
+	}
+
+	return toObject, nil
+}
+
+func listCachedContentsParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = listCachedContentsConfigToMldev(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func listCachedContentsParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = listCachedContentsConfigToVertex(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
 func cachedContentFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
@@ -478,6 +543,48 @@ func deleteCachedContentResponseFromVertex(ac *apiClient, fromObject map[string]
 	return toObject, nil
 }
 
+func listCachedContentsResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromNextPageToken := getValueByPath(fromObject, []string{"nextPageToken"})
+	if fromNextPageToken != nil {
+		setValueByPath(toObject, []string{"nextPageToken"}, fromNextPageToken)
+	}
+
+	fromCachedContents := getValueByPath(fromObject, []string{"cachedContents"})
+	if fromCachedContents != nil {
+		fromCachedContents, err = applyConverterToSlice(ac, fromCachedContents.([]any), cachedContentFromMldev)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"cachedContents"}, fromCachedContents)
+	}
+
+	return toObject, nil
+}
+
+func listCachedContentsResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromNextPageToken := getValueByPath(fromObject, []string{"nextPageToken"})
+	if fromNextPageToken != nil {
+		setValueByPath(toObject, []string{"nextPageToken"}, fromNextPageToken)
+	}
+
+	fromCachedContents := getValueByPath(fromObject, []string{"cachedContents"})
+	if fromCachedContents != nil {
+		fromCachedContents, err = applyConverterToSlice(ac, fromCachedContents.([]any), cachedContentFromVertex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"cachedContents"}, fromCachedContents)
+	}
+
+	return toObject, nil
+}
+
 type Caches struct {
 	apiClient *apiClient
 }
@@ -488,6 +595,13 @@ func (m Caches) Create(ctx context.Context, model string, config *CreateCachedCo
 	kwargs := map[string]any{"model": model, "config": config}
 	deepMarshal(kwargs, &parameterMap)
 
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
 	var response = new(CachedContent)
 	var responseMap map[string]any
 	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
@@ -522,7 +636,7 @@ func (m Caches) Create(ctx context.Context, model string, config *CreateCachedCo
 	if _, ok := body["config"]; ok {
 		delete(body, "config")
 	}
-	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body)
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -543,6 +657,13 @@ func (m Caches) Get(ctx context.Context, name string, config *GetCachedContentCo
 	kwargs := map[string]any{"name": name, "config": config}
 	deepMarshal(kwargs, &parameterMap)
 
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
 	var response = new(CachedContent)
 	var responseMap map[string]any
 	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
@@ -577,7 +698,7 @@ func (m Caches) Get(ctx context.Context, name string, config *GetCachedContentCo
 	if _, ok := body["config"]; ok {
 		delete(body, "config")
 	}
-	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodGet, &body)
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodGet, &body, httpOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -598,6 +719,13 @@ func (m Caches) Delete(ctx context.Context, name string, config *DeleteCachedCon
 	kwargs := map[string]any{"name": name, "config": config}
 	deepMarshal(kwargs, &parameterMap)
 
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
 	var response = new(DeleteCachedContentResponse)
 	var responseMap map[string]any
 	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
@@ -632,7 +760,7 @@ func (m Caches) Delete(ctx context.Context, name string, config *DeleteCachedCon
 	if _, ok := body["config"]; ok {
 		delete(body, "config")
 	}
-	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodDelete, &body)
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodDelete, &body, httpOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -653,6 +781,13 @@ func (m Caches) Update(ctx context.Context, name string, config *UpdateCachedCon
 	kwargs := map[string]any{"name": name, "config": config}
 	deepMarshal(kwargs, &parameterMap)
 
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
 	var response = new(CachedContent)
 	var responseMap map[string]any
 	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
@@ -687,7 +822,73 @@ func (m Caches) Update(ctx context.Context, name string, config *UpdateCachedCon
 	if _, ok := body["config"]; ok {
 		delete(body, "config")
 	}
-	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPatch, &body)
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPatch, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (m Caches) list(ctx context.Context, config *ListCachedContentsConfig) (*ListCachedContentsResponse, error) {
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(ListCachedContentsResponse)
+	var responseMap map[string]any
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = listCachedContentsParametersToVertex
+		fromConverter = listCachedContentsResponseFromVertex
+	} else {
+		toConverter = listCachedContentsParametersToMldev
+		fromConverter = listCachedContentsResponseFromMldev
+	}
+
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var path string
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		path, err = formatMap("cachedContents", urlParams)
+	} else {
+		path, err = formatMap("cachedContents", urlParams)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
+	}
+	if _, ok := body["_query"]; ok {
+		path += "?" + createURLQuery(body["_query"].(map[string]any))
+		delete(body, "_query")
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodGet, &body, httpOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -701,3 +902,24 @@ func (m Caches) Update(ctx context.Context, name string, config *UpdateCachedCon
 	}
 	return response, nil
 }
+
+// List returns an iterator over the cached contents of the project. Pages are
+// fetched lazily as the iteration advances.
+func (m Caches) List(ctx context.Context, config *ListCachedContentsConfig) iter.Seq2[*CachedContent, error] {
+	var pageToken string
+	if config != nil {
+		pageToken = config.PageToken
+	}
+	return allPages(ctx, pageToken, func(ctx context.Context, pageToken string) ([]*CachedContent, string, error) {
+		pageConfig := &ListCachedContentsConfig{}
+		if config != nil {
+			*pageConfig = *config
+		}
+		pageConfig.PageToken = pageToken
+		response, err := m.list(ctx, pageConfig)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.CachedContents, response.NextPageToken, nil
+	})
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCachesList(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc     string
+		backend  Backend
+		wantPath string
+	}{
+		{
+			desc:     "Gemini API",
+			backend:  BackendGeminiAPI,
+			wantPath: "/v1beta/cachedContents",
+		},
+		{
+			desc:     "Vertex AI",
+			backend:  BackendVertexAI,
+			wantPath: "/v1beta1/projects/test-project/locations/test-location/cachedContents",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotQuery []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("request method = %q, want %q", r.Method, http.MethodGet)
+				}
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				gotQuery = append(gotQuery, r.URL.RawQuery)
+				if r.URL.Query().Get("pageToken") == "" {
+					fmt.Fprint(w, `{"cachedContents":[{"name":"cachedContents/1","model":"models/gemini-1.5-flash"}],"nextPageToken":"page-2"}`)
+					return
+				}
+				fmt.Fprint(w, `{"cachedContents":[{"name":"cachedContents/2"}]}`)
+			}))
+			defer ts.Close()
+
+			c := Caches{apiClient: newTestAPIClient(ts, tt.backend)}
+			var got []*CachedContent
+			for cachedContent, err := range c.List(ctx, &ListCachedContentsConfig{PageSize: 1}) {
+				if err != nil {
+					t.Fatalf("List() error = %v", err)
+				}
+				got = append(got, cachedContent)
+			}
+			want := []*CachedContent{
+				{Name: "cachedContents/1", Model: "models/gemini-1.5-flash"},
+				{Name: "cachedContents/2"},
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("List() mismatch (-want +got):\n%s", diff)
+			}
+			wantQuery := []string{"pageSize=1", "pageSize=1&pageToken=page-2"}
+			if diff := cmp.Diff(wantQuery, gotQuery); diff != "" {
+				t.Errorf("List() queries mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("returns request errors", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": 404, "message": "not found"}}`)
+		}))
+		defer ts.Close()
+
+		c := Caches{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		for cachedContent, err := range c.List(ctx, nil) {
+			if err == nil {
+				t.Fatalf("List() yielded %v, want error", cachedContent)
+			}
+		}
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"iter"
+)
+
+// Chat is a multi-turn conversation with a model. It keeps the conversation history
+// and sends it along with every new message.
+//
+// A Chat is not safe for concurrent use.
+type Chat struct {
+	models  ModelsInterface
+	model   string
+	config  *GenerateContentConfig
+	history []*Content
+}
+
+// StartChat starts a new conversation with the model. The config is used for every
+// message of the conversation.
+func (m Models) StartChat(model string, config *GenerateContentConfig) *Chat {
+	return &Chat{models: m, model: model, config: config}
+}
+
+// History returns a deep copy of the conversation history.
+func (c *Chat) History() []*Content {
+	return deepCopyContents(c.history)
+}
+
+// SetHistory replaces the conversation history with a deep copy of history, for
+// example to resume a conversation restored from storage.
+func (c *Chat) SetHistory(history []*Content) {
+	c.history = deepCopyContents(history)
+}
+
+func deepCopyContents(contents []*Content) []*Content {
+	if contents == nil {
+		return nil
+	}
+	copied := make([]*Content, len(contents))
+	for i, content := range contents {
+		copied[i] = content.DeepCopy()
+	}
+	return copied
+}
+
+// SendMessage sends the parts as a user turn and returns the model response. The
+// user turn and the model turn are appended to the history only if the model
+// returned a candidate; on error the history is left unchanged. The history keeps
+// copies of the parts, so the caller may modify them afterwards.
+func (c *Chat) SendMessage(ctx context.Context, parts ...*Part) (*GenerateContentResponse, error) {
+	userContent := (&Content{Role: RoleUser, Parts: parts}).DeepCopy()
+	response, err := c.models.GenerateContent(ctx, c.model, c.contents(userContent), c.config)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Candidates) > 0 && response.Candidates[0].Content != nil {
+		c.appendTurn(userContent, response.Candidates[0].Content.Parts)
+	}
+	return response, nil
+}
+
+// SendMessageStream sends the parts as a user turn and streams the model response.
+// The model turn is buffered and appended to the history, along with the user turn,
+// only once the stream has been fully consumed without error.
+func (c *Chat) SendMessageStream(ctx context.Context, parts ...*Part) iter.Seq2[*GenerateContentResponse, error] {
+	userContent := (&Content{Role: RoleUser, Parts: parts}).DeepCopy()
+	return func(yield func(*GenerateContentResponse, error) bool) {
+		var acc StreamAccumulator
+		for response, err := range c.models.GenerateContentStream(ctx, c.model, c.contents(userContent), c.config) {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			acc.Accumulate(response)
+			if !yield(response, nil) {
+				return
+			}
+		}
+		if response := acc.Response(); len(response.Candidates) > 0 && response.Candidates[0].Content != nil {
+			c.appendTurn(userContent, response.Candidates[0].Content.Parts)
+		}
+	}
+}
+
+func (c *Chat) contents(userContent *Content) []*Content {
+	contents := make([]*Content, 0, len(c.history)+1)
+	contents = append(contents, c.history...)
+	return append(contents, userContent)
+}
+
+// appendTurn appends userContent, which must be a copy, and a copy of the model
+// parts, which are also returned to the caller, to the history.
+func (c *Chat) appendTurn(userContent *Content, modelParts []*Part) {
+	c.history = append(c.history, userContent, (&Content{Role: RoleModel, Parts: modelParts}).DeepCopy())
+}
+
+// mergeTextParts concatenates adjacent text parts of the same kind, so that a model
+// turn streamed in many chunks is stored as a few parts.
+func mergeTextParts(parts []*Part) []*Part {
+	var merged []*Part
+	for _, p := range parts {
+		if p == nil {
+			continue
+		}
+		if n := len(merged); n > 0 && isPlainText(p) && isPlainText(merged[n-1]) && merged[n-1].Thought == p.Thought {
+			merged[n-1] = &Part{Text: merged[n-1].Text + p.Text, Thought: p.Thought}
+			continue
+		}
+		merged = append(merged, p)
+	}
+	return merged
+}
+
+func isPlainText(p *Part) bool {
+	return *p == Part{Text: p.Text, Thought: p.Thought}
+}
@@ -0,0 +1,181 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// chatReply answers each generateContent request with the next of the given
+// replies. A reply starting with "error" fails the request. For a stream, the chunks
+// of a reply are separated by "|".
+func chatReply(replies []string) func(http.ResponseWriter, *http.Request, int, []*Content) {
+	return func(w http.ResponseWriter, r *http.Request, n int, _ []*Content) {
+		reply := replies[n]
+		if strings.HasPrefix(reply, "error") {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error": {"code": 400, "message": "bad request"}}`)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, ":streamGenerateContent") {
+			for _, chunk := range strings.Split(reply, "|") {
+				fmt.Fprintf(w, "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":%q}]}}]}\n\n", chunk)
+			}
+			return
+		}
+		fmt.Fprintf(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":%q}]}}]}`, reply)
+	}
+}
+
+func TestChatSendMessage(t *testing.T) {
+	ctx := context.Background()
+	ts, gotContents := newRecordingTestServer(t, chatReply([]string{"Hi there", "error", "Go is great"}))
+	defer ts.Close()
+
+	chat := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}.StartChat("gemini-2.0-flash", nil)
+
+	if _, err := chat.SendMessage(ctx, NewPartFromText("Hello")); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if _, err := chat.SendMessage(ctx, NewPartFromText("This fails")); err == nil {
+		t.Fatal("SendMessage() succeeded, want error")
+	}
+	response, err := chat.SendMessage(ctx, NewPartFromText("Tell me about Go"))
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if got, _ := response.Text(); got != "Go is great" {
+		t.Errorf("SendMessage() text = %q, want %q", got, "Go is great")
+	}
+
+	hello := &Content{Role: RoleUser, Parts: []*Part{{Text: "Hello"}}}
+	hiThere := &Content{Role: RoleModel, Parts: []*Part{{Text: "Hi there"}}}
+	aboutGo := &Content{Role: RoleUser, Parts: []*Part{{Text: "Tell me about Go"}}}
+	wantContents := [][]*Content{
+		{hello},
+		{hello, hiThere, {Role: RoleUser, Parts: []*Part{{Text: "This fails"}}}},
+		{hello, hiThere, aboutGo},
+	}
+	if diff := cmp.Diff(wantContents, gotContents()); diff != "" {
+		t.Errorf("request contents mismatch (-want +got):\n%s", diff)
+	}
+	wantHistory := []*Content{hello, hiThere, aboutGo, {Role: RoleModel, Parts: []*Part{{Text: "Go is great"}}}}
+	if diff := cmp.Diff(wantHistory, chat.History()); diff != "" {
+		t.Errorf("History() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestChatSendMessageStream(t *testing.T) {
+	ctx := context.Background()
+	ts, _ := newRecordingTestServer(t, chatReply([]string{"Hi| there", "Unfinished| answer", "error"}))
+	defer ts.Close()
+
+	chat := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}.StartChat("gemini-2.0-flash", nil)
+	wantHistory := []*Content{
+		{Role: RoleUser, Parts: []*Part{{Text: "Hello"}}},
+		{Role: RoleModel, Parts: []*Part{{Text: "Hi there"}}},
+	}
+
+	for _, err := range chat.SendMessageStream(ctx, NewPartFromText("Hello")) {
+		if err != nil {
+			t.Fatalf("SendMessageStream() error = %v", err)
+		}
+	}
+	if diff := cmp.Diff(wantHistory, chat.History()); diff != "" {
+		t.Errorf("History() after complete stream mismatch (-want +got):\n%s", diff)
+	}
+
+	// A stream abandoned by the caller does not change the history.
+	for range chat.SendMessageStream(ctx, NewPartFromText("Interrupted")) {
+		break
+	}
+	if diff := cmp.Diff(wantHistory, chat.History()); diff != "" {
+		t.Errorf("History() after interrupted stream mismatch (-want +got):\n%s", diff)
+	}
+
+	var gotErr error
+	for _, err := range chat.SendMessageStream(ctx, NewPartFromText("This fails")) {
+		gotErr = err
+	}
+	if gotErr == nil {
+		t.Error("SendMessageStream() succeeded, want error")
+	}
+	if diff := cmp.Diff(wantHistory, chat.History()); diff != "" {
+		t.Errorf("History() after failed stream mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestChatSetHistory(t *testing.T) {
+	ctx := context.Background()
+	ts, gotContents := newRecordingTestServer(t, chatReply([]string{"Blue"}))
+	defer ts.Close()
+
+	history := []*Content{
+		{Role: RoleUser, Parts: []*Part{{Text: "My favorite color is blue."}}},
+		{Role: RoleModel, Parts: []*Part{{Text: "Noted."}}},
+	}
+	chat := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}.StartChat("gemini-2.0-flash", nil)
+	chat.SetHistory(history)
+	history[0] = nil // The chat keeps its own copy.
+
+	if _, err := chat.SendMessage(ctx, NewPartFromText("What is my favorite color?")); err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+	if got, want := len(gotContents()[0]), 3; got != want {
+		t.Fatalf("request has %d contents, want %d", got, want)
+	}
+	if got, want := gotContents()[0][0].Parts[0].Text, "My favorite color is blue."; got != want {
+		t.Errorf("first content text = %q, want %q", got, want)
+	}
+	if got, want := len(chat.History()), 4; got != want {
+		t.Errorf("History() has %d contents, want %d", got, want)
+	}
+}
+
+func TestChatHistoryDoesNotAlias(t *testing.T) {
+	ctx := context.Background()
+	mock := &MockModels{}
+	mock.SetResponse(&GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{{Text: "Hi"}}}}}})
+	chat := mock.StartChat("gemini-2.0-flash", nil)
+
+	history := []*Content{{Role: RoleUser, Parts: []*Part{{Text: "Earlier"}}}}
+	chat.SetHistory(history)
+	part := NewPartFromText("Hello")
+	response, err := chat.SendMessage(ctx, part)
+	if err != nil {
+		t.Fatalf("SendMessage() error = %v", err)
+	}
+
+	// None of the values passed to or returned by the chat are part of its history.
+	history[0].Parts[0].Text = "changed"
+	part.Text = "changed"
+	response.Candidates[0].Content.Parts[0].Text = "changed"
+	chat.History()[0].Parts[0].Text = "changed"
+
+	want := []*Content{
+		{Role: RoleUser, Parts: []*Part{{Text: "Earlier"}}},
+		{Role: RoleUser, Parts: []*Part{{Text: "Hello"}}},
+		{Role: RoleModel, Parts: []*Part{{Text: "Hi"}}},
+	}
+	if diff := cmp.Diff(want, chat.History()); diff != "" {
+		t.Errorf("History() mismatch (-want +got):\n%s", diff)
+	}
+}
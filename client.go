@@ -17,8 +17,11 @@ package genai
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/url"
 	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -29,9 +32,14 @@ import (
 // Client is the GenAI client.
 type Client struct {
 	clientConfig ClientConfig
-	Models       *Models
-	Live         *Live
-	Caches       *Caches
+	apiClient    *apiClient
+	// Models is a [Models] by default. It can be replaced with a [MockModels] in
+	// tests.
+	Models     ModelsInterface
+	Live       *Live
+	Caches     *Caches
+	Files      *Files
+	TuningJobs *TuningJobs
 }
 
 // Backend is the GenAI backend to use for the client.
@@ -48,6 +56,12 @@ const (
 	BackendGeminiAPI
 	// BackendVertexAI is the Vertex AI backend.
 	BackendVertexAI
+
+	// BackendGoogleAI is the former name of BackendGeminiAPI. It has the same
+	// value, so existing code keeps working unchanged.
+	//
+	// Deprecated: Use BackendGeminiAPI.
+	BackendGoogleAI Backend = BackendGeminiAPI
 )
 
 // The Stringer interface for Backend.
@@ -71,8 +85,35 @@ type ClientConfig struct {
 	Credentials *google.Credentials // Optional. Google credentials.  If not specified, application default credentials will be used.
 	HTTPClient  *http.Client        // Optional HTTP client to use. If nil, a default client will be created. For Vertex AI, this client must handle authentication appropriately.
 	HTTPOptions HTTPOptions         // Optional HTTP options to override.
+	RetryConfig *RetryConfig        // Optional. Retry transient errors with exponential backoff. If nil, requests are not retried.
+	Middlewares []Middleware        // Optional. Middlewares run in order on every HTTP request and response.
+	RateLimiter RateLimiter         // Optional. Waited on before every HTTP request, including retries. If nil, requests are not limited.
+	Logger      *slog.Logger        // Optional. Receives a debug record for every HTTP request and stream errors. If nil, slog.Default() is used.
+
+	// Optional. Stores the embeddings returned by EmbedContent and BatchEmbedContents,
+	// so that the same content is only sent to the API once. See
+	// [NewInMemoryEmbeddingsCache]. If nil, embeddings are not cached.
+	EmbeddingsCache EmbeddingsCache
+
+	// Optional. Maximum size in bytes of the data of each inline Blob sent to
+	// GenerateContent. Defaults to 20 MB, the API request limit. A negative value
+	// disables the check.
+	MaxInlineDataBytes int64
+
+	// Optional. If true, GenerateContent calls Models.Get the first time a model is
+	// used with a Temperature, and rejects temperatures outside the range the model
+	// supports. If the model metadata is unavailable, requests are not validated.
+	ValidateModelConstraints bool
+
+	// Optional. If true, HTTPOptions.BaseURL is not checked to be an absolute URL,
+	// and HTTPOptions.APIVersion to be a version such as "v1beta", when the client
+	// is created.
+	DisableURLValidation bool
 }
 
+// defaultMaxInlineDataBytes is the request size limit of the API.
+const defaultMaxInlineDataBytes = 20 * 1024 * 1024
+
 // NewClient creates a new GenAI client.
 //
 // You can configure the client by passing in a ClientConfig struct.
@@ -143,10 +184,17 @@ func NewClient(ctx context.Context, cc *ClientConfig) (*Client, error) {
 		cc.HTTPOptions.BaseURL = "https://generativelanguage.googleapis.com/"
 	}
 
-	if cc.HTTPOptions.APIVersion == "" && cc.Backend == BackendVertexAI {
-		cc.HTTPOptions.APIVersion = "v1beta1"
-	} else if cc.HTTPOptions.APIVersion == "" {
-		cc.HTTPOptions.APIVersion = "v1beta"
+	if cc.HTTPOptions.APIVersion == "" {
+		cc.HTTPOptions.APIVersion = defaultAPIVersion(cc.Backend)
+	}
+
+	if !cc.DisableURLValidation {
+		if err := validateBaseURL(cc.HTTPOptions.BaseURL); err != nil {
+			return nil, err
+		}
+		if err := validateAPIVersion(cc.HTTPOptions.APIVersion); err != nil {
+			return nil, err
+		}
 	}
 
 	if cc.HTTPClient == nil {
@@ -157,16 +205,24 @@ func NewClient(ctx context.Context, cc *ClientConfig) (*Client, error) {
 		}
 	}
 
+	if cc.MaxInlineDataBytes == 0 {
+		cc.MaxInlineDataBytes = defaultMaxInlineDataBytes
+	}
+
 	if cc.HTTPOptions.Timeout > 0 {
 		cc.HTTPClient.Timeout = time.Duration(cc.HTTPOptions.Timeout) * time.Millisecond
 	}
 
 	ac := &apiClient{clientConfig: cc}
+	ac.closeCtx, ac.cancelClose = context.WithCancel(context.Background())
 	c := &Client{
 		clientConfig: *cc,
-		Models:       &Models{apiClient: ac},
+		apiClient:    ac,
+		Models:       Models{apiClient: ac},
 		Live:         &Live{apiClient: ac},
 		Caches:       &Caches{apiClient: ac},
+		Files:        &Files{apiClient: ac},
+		TuningJobs:   &TuningJobs{apiClient: ac},
 	}
 	return c, nil
 }
@@ -177,3 +233,80 @@ func NewClient(ctx context.Context, cc *ClientConfig) (*Client, error) {
 func (c Client) ClientConfig() ClientConfig {
 	return c.clientConfig
 }
+
+// closeTimeout is how long Client.Close waits for background goroutines to stop.
+const closeTimeout = 5 * time.Second
+
+// Close releases the resources of the client: it closes the Live sessions opened
+// with it, waits for their keepalive goroutines to stop, and closes the idle
+// connections of ClientConfig.HTTPClient. Later calls to the methods of the client
+// return ErrClientClosed. Calling Close more than once, including concurrently, is
+// safe; the calls after the first return nil.
+func (c *Client) Close() error {
+	if c == nil || c.apiClient == nil {
+		return nil
+	}
+	var err error
+	c.apiClient.closeOnce.Do(func() { err = c.apiClient.close() })
+	return err
+}
+
+// close implements Client.Close.
+func (ac *apiClient) close() error {
+	if ac.cancelClose != nil {
+		ac.cancelClose()
+	}
+	ac.backgroundMu.Lock()
+	ac.backgroundClosed = true
+	ac.backgroundMu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		ac.background.Wait()
+		close(stopped)
+	}()
+	var err error
+	select {
+	case <-stopped:
+	case <-time.After(closeTimeout):
+		err = fmt.Errorf("Close: background goroutines did not stop within %v", closeTimeout)
+	}
+	ac.clientConfig.HTTPClient.CloseIdleConnections()
+	return err
+}
+
+// defaultAPIVersion returns the API version used when HTTPOptions.APIVersion is
+// empty.
+func defaultAPIVersion(backend Backend) string {
+	if backend == BackendVertexAI {
+		return "v1beta1"
+	}
+	return "v1beta"
+}
+
+// apiVersionPattern matches API versions such as "v1", "v1beta" or "v1.2".
+var apiVersionPattern = regexp.MustCompile(`^v[a-zA-Z0-9.]+$`)
+
+// validateAPIVersion checks that version starts with "v" followed by letters,
+// digits and dots.
+func validateAPIVersion(version string) error {
+	if !apiVersionPattern.MatchString(version) {
+		return fmt.Errorf("HTTPOptions.APIVersion %q must start with \"v\" and contain only letters, digits and dots", version)
+	}
+	return nil
+}
+
+// validateBaseURL checks that baseURL is an absolute URL with a scheme and a host.
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid HTTPOptions.BaseURL %q: %w", baseURL, err)
+	}
+	if u.Scheme == "" || !strings.Contains(baseURL, "://") {
+		return fmt.Errorf("HTTPOptions.BaseURL %q must include a scheme (e.g., http:// or https://)", baseURL)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("HTTPOptions.BaseURL %q must include a host", baseURL)
+	}
+	return nil
+}
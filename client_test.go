@@ -16,8 +16,12 @@ package genai
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -86,8 +90,8 @@ func TestNewClient(t *testing.T) {
 			if err != nil {
 				t.Fatalf("Expected no error, got %v", err)
 			}
-			if client.Models.apiClient.clientConfig.Credentials != creds {
-				t.Errorf("Credentials want %#v, got %#v", creds, client.Models.apiClient.clientConfig.Credentials)
+			if client.apiClient.clientConfig.Credentials != creds {
+				t.Errorf("Credentials want %#v, got %#v", creds, client.apiClient.clientConfig.Credentials)
 			}
 		})
 
@@ -272,7 +276,7 @@ func TestNewClient(t *testing.T) {
 		opts := []cmp.Option{
 			cmpopts.IgnoreUnexported(ClientConfig{}),
 		}
-		if diff := cmp.Diff(*client.Models.apiClient.clientConfig, client.clientConfig, opts...); diff != "" {
+		if diff := cmp.Diff(*client.apiClient.clientConfig, client.clientConfig, opts...); diff != "" {
 			t.Errorf("Models.apiClient.clientConfig mismatch (-want +got):\n%s", diff)
 		}
 	})
@@ -283,8 +287,8 @@ func TestNewClient(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Expected no error, got %v", err)
 		}
-		if client.Models.apiClient.clientConfig.HTTPClient != httpClient {
-			t.Errorf("HTTPClient want %#v, got %#v", httpClient, client.Models.apiClient.clientConfig.HTTPClient)
+		if client.apiClient.clientConfig.HTTPClient != httpClient {
+			t.Errorf("HTTPClient want %#v, got %#v", httpClient, client.apiClient.clientConfig.HTTPClient)
 		}
 	})
 
@@ -299,6 +303,7 @@ func TestNewClient(t *testing.T) {
 				BaseURL:    "https://generativelanguage.googleapis.com/",
 				APIVersion: "v1beta",
 			},
+			MaxInlineDataBytes: 20 * 1024 * 1024,
 		}
 		os.Setenv("GOOGLE_CLOUD_PROJECT", want.Project)
 		t.Cleanup(func() { os.Unsetenv("GOOGLE_CLOUD_PROJECT") })
@@ -319,7 +324,7 @@ func TestNewClient(t *testing.T) {
 		opts := []cmp.Option{
 			cmpopts.IgnoreUnexported(ClientConfig{}),
 		}
-		if diff := cmp.Diff(want, *client.Models.apiClient.clientConfig, opts...); diff != "" {
+		if diff := cmp.Diff(want, *client.apiClient.clientConfig, opts...); diff != "" {
 			t.Errorf("Models.apiClient.clientConfig mismatch (-want +got):\n%s", diff)
 		}
 	})
@@ -494,3 +499,206 @@ func TestClientConfigHTTPOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestNewClientBaseURLValidation(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		baseURL       string
+		disable       bool
+		wantErrSubstr string
+	}{
+		{name: "valid https", baseURL: "https://test-base-url.com/"},
+		{name: "valid http with port", baseURL: "http://localhost:8080"},
+		{name: "valid websocket", baseURL: "ws://127.0.0.1:1234"},
+		{name: "missing scheme", baseURL: "localhost:8080", wantErrSubstr: "must include a scheme"},
+		{name: "bare host", baseURL: "test-base-url.com", wantErrSubstr: "must include a scheme"},
+		{name: "missing host", baseURL: "https:///v1", wantErrSubstr: "must include a host"},
+		{name: "unparsable", baseURL: "http://[::1", wantErrSubstr: "invalid HTTPOptions.BaseURL"},
+		{name: "validation disabled", baseURL: "localhost:8080", disable: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClient(ctx, &ClientConfig{
+				APIKey:               "test-api-key",
+				Backend:              BackendGeminiAPI,
+				HTTPOptions:          HTTPOptions{BaseURL: tt.baseURL},
+				DisableURLValidation: tt.disable,
+			})
+			if tt.wantErrSubstr == "" {
+				if err != nil {
+					t.Errorf("NewClient() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErrSubstr) {
+				t.Errorf("NewClient() error = %v, want error containing %q", err, tt.wantErrSubstr)
+			}
+		})
+	}
+}
+
+func TestBackendGoogleAI(t *testing.T) {
+	if BackendGoogleAI != BackendGeminiAPI {
+		t.Fatalf("BackendGoogleAI = %d, want BackendGeminiAPI (%d)", BackendGoogleAI, BackendGeminiAPI)
+	}
+	if got := BackendGoogleAI.String(); got != "BackendGeminiAPI" {
+		t.Errorf("BackendGoogleAI.String() = %q, want %q", got, "BackendGeminiAPI")
+	}
+	for _, backend := range []Backend{BackendGeminiAPI, BackendGoogleAI} {
+		client, err := NewClient(context.Background(), &ClientConfig{APIKey: "test-api-key", Backend: backend})
+		if err != nil {
+			t.Fatalf("NewClient(%v) failed: %v", backend, err)
+		}
+		if got := client.clientConfig.Backend; got != BackendGeminiAPI {
+			t.Errorf("NewClient(%v) backend = %v, want %v", backend, got, BackendGeminiAPI)
+		}
+		if got := client.clientConfig.HTTPOptions.BaseURL; got != "https://generativelanguage.googleapis.com/" {
+			t.Errorf("NewClient(%v) base URL = %q, want the Gemini API URL", backend, got)
+		}
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	ctx := context.Background()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name":"models/gemini-2.0-flash"}`))
+	}))
+	defer ts.Close()
+	client, err := NewClient(ctx, &ClientConfig{APIKey: "test-api-key", Backend: BackendGeminiAPI, HTTPOptions: HTTPOptions{BaseURL: ts.URL}})
+	if err != nil {
+		t.Fatalf("NewClient() failed: %v", err)
+	}
+	if _, err := client.Models.Get(ctx, "gemini-2.0-flash", nil); err != nil {
+		t.Fatalf("Models.Get() failed: %v", err)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("Close() failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Errorf("second Close() failed: %v", err)
+	}
+	if _, err := client.Models.Get(ctx, "gemini-2.0-flash", nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Models.Get() after Close error = %v, want %v", err, ErrClientClosed)
+	}
+	for _, err := range client.Models.GenerateContentStream(ctx, "gemini-2.0-flash", Text("hello"), nil) {
+		if !errors.Is(err, ErrClientClosed) {
+			t.Errorf("GenerateContentStream() after Close error = %v, want %v", err, ErrClientClosed)
+		}
+	}
+}
+
+func TestClientCloseConcurrent(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("zero value", func(t *testing.T) {
+		var client Client
+		if err := client.Close(); err != nil {
+			t.Errorf("Close() of a zero Client failed: %v", err)
+		}
+	})
+
+	t.Run("concurrent Close", func(t *testing.T) {
+		client, err := NewClient(ctx, &ClientConfig{APIKey: "test-api-key", Backend: BackendGeminiAPI})
+		if err != nil {
+			t.Fatalf("NewClient() failed: %v", err)
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := client.Close(); err != nil {
+					t.Errorf("Close() failed: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+
+	t.Run("goroutines started during Close", func(t *testing.T) {
+		client, err := NewClient(ctx, &ClientConfig{APIKey: "test-api-key", Backend: BackendGeminiAPI})
+		if err != nil {
+			t.Fatalf("NewClient() failed: %v", err)
+		}
+		ac := client.apiClient
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ac.goBackground(func() { time.Sleep(time.Millisecond) })
+			}()
+		}
+		if err := client.Close(); err != nil {
+			t.Errorf("Close() failed: %v", err)
+		}
+		wg.Wait()
+		if ac.goBackground(func() { t.Error("goroutine started after Close()") }) {
+			t.Error("goBackground() after Close() = true, want false")
+		}
+	})
+}
+
+func TestNewClientAPIVersion(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		apiVersion    string
+		wantURL       string
+		wantErrSubstr string
+	}{
+		{name: "default", wantURL: "https://test-base-url.com/v1beta/models"},
+		{name: "custom", apiVersion: "v1", wantURL: "https://test-base-url.com/v1/models"},
+		{name: "custom with dots", apiVersion: "v1.2alpha", wantURL: "https://test-base-url.com/v1.2alpha/models"},
+		{name: "missing v", apiVersion: "1beta", wantErrSubstr: `must start with "v"`},
+		{name: "path separator", apiVersion: "v1/../v2", wantErrSubstr: "contain only letters, digits and dots"},
+		{name: "v only", apiVersion: "v", wantErrSubstr: "HTTPOptions.APIVersion"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewClient(ctx, &ClientConfig{
+				APIKey:      "test-api-key",
+				Backend:     BackendGeminiAPI,
+				HTTPOptions: HTTPOptions{BaseURL: "https://test-base-url.com", APIVersion: tt.apiVersion},
+			})
+			if tt.wantErrSubstr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErrSubstr) {
+					t.Errorf("NewClient() error = %v, want error containing %q", err, tt.wantErrSubstr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClient() failed: %v", err)
+			}
+			ac := client.apiClient
+			u, err := ac.createAPIURL("models", mergeHTTPOptions(ac.clientConfig, nil))
+			if err != nil {
+				t.Fatalf("createAPIURL() failed: %v", err)
+			}
+			if got := u.String(); got != tt.wantURL {
+				t.Errorf("createAPIURL() = %q, want %q", got, tt.wantURL)
+			}
+		})
+	}
+
+	t.Run("request override", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendVertexAI, Project: "p", Location: "l", HTTPOptions: HTTPOptions{BaseURL: "https://test-base-url.com"}}}
+		for _, tc := range []struct {
+			options *HTTPOptions
+			want    string
+		}{
+			{options: nil, want: "https://test-base-url.com/v1beta1/projects/p/locations/l/models"},
+			{options: &HTTPOptions{APIVersion: "v1"}, want: "https://test-base-url.com/v1/projects/p/locations/l/models"},
+		} {
+			u, err := ac.createAPIURL("models", mergeHTTPOptions(ac.clientConfig, tc.options))
+			if err != nil {
+				t.Fatalf("createAPIURL() failed: %v", err)
+			}
+			if got := u.String(); got != tc.want {
+				t.Errorf("createAPIURL() = %q, want %q", got, tc.want)
+			}
+		}
+	})
+}
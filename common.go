@@ -19,7 +19,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 // Ptr returns a pointer to its argument.
@@ -28,6 +31,38 @@ import (
 //	genai.GenerateContentConfig{Temperature: genai.Ptr(0.5)}
 func Ptr[T any](t T) *T { return &t }
 
+// PtrFloat32 returns a pointer to v. Unlike [Ptr], it needs no type argument
+// for a literal: genai.PtrFloat32(0.5) rather than genai.Ptr[float32](0.5).
+func PtrFloat32(v float32) *float32 { return Ptr(v) }
+
+// PtrFloat64 returns a pointer to v.
+func PtrFloat64(v float64) *float64 { return Ptr(v) }
+
+// PtrInt32 returns a pointer to v.
+func PtrInt32(v int32) *int32 { return Ptr(v) }
+
+// PtrInt64 returns a pointer to v, for fields such as
+// GenerateContentConfig.CandidateCount: genai.PtrInt64(2) rather than
+// genai.Ptr[int64](2).
+func PtrInt64(v int64) *int64 { return Ptr(v) }
+
+// PtrBool returns a pointer to v.
+func PtrBool(v bool) *bool { return Ptr(v) }
+
+// PtrString returns a pointer to v.
+func PtrString(v string) *string { return Ptr(v) }
+
+// Deref returns the value p points to, or def if p is nil. It can be used to read
+// optional fields:
+//
+//	temperature := genai.Deref(config.Temperature, 1)
+func Deref[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
 type converterFunc func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 
 type transformerFunc[T any] func(*apiClient, T) (T, error)
@@ -95,12 +130,16 @@ func formatMap(template string, variables map[string]any) (string, error) {
 }
 
 // applyConverterToSlice calls converter function to each element of the slice.
+// Nil elements, which come from null entries in a JSON array, are skipped.
 func applyConverterToSlice(ac *apiClient, inputs []any, converter converterFunc) ([]map[string]any, error) {
 	var outputs []map[string]any
-	for _, object := range inputs {
+	for i, object := range inputs {
+		if object == nil {
+			continue
+		}
 		object, err := converter(ac, object.(map[string]any), nil)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("element %d: %w", i, err)
 		}
 		outputs = append(outputs, object)
 	}
@@ -110,10 +149,10 @@ func applyConverterToSlice(ac *apiClient, inputs []any, converter converterFunc)
 // applyItemTransformerToSlice calls item transformer function to each element of the slice.
 func applyItemTransformerToSlice[T any](ac *apiClient, inputs []T, itemTransformer transformerFunc[T]) ([]T, error) {
 	var outputs []T
-	for _, input := range inputs {
+	for i, input := range inputs {
 		object, err := itemTransformer(ac, input)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("element %d: %w", i, err)
 		}
 		outputs = append(outputs, object)
 	}
@@ -128,3 +167,30 @@ func deepMarshal(input any, output *map[string]any) error {
 	}
 	return nil
 }
+
+// createURLQuery encodes the query parameters collected under "_query" by the
+// converters. List values are joined with commas.
+func createURLQuery(query map[string]any) string {
+	values := url.Values{}
+	for key, value := range query {
+		values.Set(key, queryValueString(value))
+	}
+	return values.Encode()
+}
+
+func queryValueString(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = queryValueString(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestApplyConverterToSliceSkipsNil(t *testing.T) {
+	ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}
+	got, err := applyConverterToSlice(ac, []any{nil, map[string]any{"text": "ok"}}, partFromMldev)
+	if err != nil {
+		t.Fatalf("applyConverterToSlice() failed: %v", err)
+	}
+	want := []map[string]any{{"text": "ok"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("applyConverterToSlice() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPtrHelpers(t *testing.T) {
+	if got := *PtrFloat32(0.5); got != 0.5 {
+		t.Errorf("*PtrFloat32(0.5) = %v", got)
+	}
+	if got := *PtrFloat64(0.5); got != 0.5 {
+		t.Errorf("*PtrFloat64(0.5) = %v", got)
+	}
+	if got := *PtrInt32(3); got != 3 {
+		t.Errorf("*PtrInt32(3) = %v", got)
+	}
+	if got := *PtrInt64(3); got != 3 {
+		t.Errorf("*PtrInt64(3) = %v", got)
+	}
+	if got := *PtrBool(true); !got {
+		t.Errorf("*PtrBool(true) = %v", got)
+	}
+	if got := *PtrString("a"); got != "a" {
+		t.Errorf("*PtrString(%q) = %q", "a", got)
+	}
+	if PtrInt64(3) == PtrInt64(3) {
+		t.Errorf("PtrInt64 returned the same pointer twice")
+	}
+
+	tests := []struct {
+		desc string
+		p    *float64
+		want float64
+	}{
+		{desc: "nil", p: nil, want: 1},
+		{desc: "non-nil", p: PtrFloat64(0.2), want: 0.2},
+		{desc: "zero", p: PtrFloat64(0), want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := Deref(tt.p, 1); got != tt.want {
+				t.Errorf("Deref() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	var config *GenerationConfig
+	if got := Deref(config, GenerationConfig{}); got.Temperature != nil {
+		t.Errorf("Deref(nil, GenerationConfig{}) = %+v, want zero value", got)
+	}
+}
+
+func TestApplyConverterToSliceErrorIndex(t *testing.T) {
+	ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}
+	parts := []any{
+		map[string]any{"text": "a"},
+		nil,
+		map[string]any{"text": "b"},
+		map[string]any{"videoMetadata": map[string]any{"startOffset": "1s"}},
+	}
+	_, err := applyConverterToSlice(ac, parts, partToMldev)
+	if err == nil || !strings.HasPrefix(err.Error(), "element 3: ") {
+		t.Errorf("applyConverterToSlice() error = %v, want error for element 3", err)
+	}
+
+	// Errors of nested slices carry the index at each level.
+	contents := []any{map[string]any{"role": "user", "parts": parts}}
+	_, err = applyConverterToSlice(ac, contents, contentToMldev)
+	if err == nil || !strings.HasPrefix(err.Error(), "element 0: element 3: ") {
+		t.Errorf("applyConverterToSlice() error = %v, want error for element 3 of element 0", err)
+	}
+}
+
+func TestApplyItemTransformerToSliceErrorIndex(t *testing.T) {
+	ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}
+	_, err := applyItemTransformerToSlice(ac, []any{float64(0), float64(1), float64(-1)}, tCandidateIndex)
+	if err == nil || !strings.HasPrefix(err.Error(), "element 2: ") {
+		t.Errorf("applyItemTransformerToSlice() error = %v, want error for element 2", err)
+	}
+}
+
+func TestDeepMarshalBytes(t *testing.T) {
+	// Not valid UTF-8, to catch any text conversion of the bytes.
+	data := []byte{0x00, 0xff, 0xfe, 0x80, '\n', 'a'}
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	t.Run("deepMarshal", func(t *testing.T) {
+		var got map[string]any
+		if err := deepMarshal(&Blob{Data: data, MIMEType: "image/png"}, &got); err != nil {
+			t.Fatalf("deepMarshal() failed: %v", err)
+		}
+		if got["data"] != encoded {
+			t.Errorf("deepMarshal() data = %v, want %q", got["data"], encoded)
+		}
+	})
+
+	t.Run("GenerateContent round trip", func(t *testing.T) {
+		var requestData any
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct {
+				Contents []struct {
+					Parts []struct {
+						InlineData map[string]any `json:"inlineData"`
+					} `json:"parts"`
+				} `json:"contents"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decoding request body failed: %v", err)
+			}
+			requestData = body.Contents[0].Parts[0].InlineData["data"]
+			// Echo the inline data back as the response.
+			json.NewEncoder(w).Encode(map[string]any{"candidates": []any{map[string]any{
+				"content": map[string]any{"role": "model", "parts": []any{map[string]any{
+					"inlineData": map[string]any{"data": requestData, "mimeType": "image/png"},
+				}}},
+			}}})
+		}))
+		defer ts.Close()
+
+		for _, backend := range []Backend{BackendGeminiAPI, BackendVertexAI} {
+			m := Models{apiClient: newTestAPIClient(ts, backend)}
+			contents := []*Content{{Role: RoleUser, Parts: []*Part{{InlineData: &Blob{Data: data, MIMEType: "image/png"}}}}}
+			resp, err := m.GenerateContent(context.Background(), "gemini-2.0-flash", contents, nil)
+			if err != nil {
+				t.Fatalf("GenerateContent() failed: %v", err)
+			}
+			if requestData != encoded {
+				t.Errorf("%s: request inlineData.data = %v, want %q", backend, requestData, encoded)
+			}
+			got := resp.Candidates[0].Content.Parts[0].InlineData.Data
+			if diff := cmp.Diff(data, got); diff != "" {
+				t.Errorf("%s: response inlineData.data mismatch (-want +got):\n%s", backend, diff)
+			}
+		}
+	})
+}
@@ -0,0 +1,126 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// EmbeddingsCache stores embeddings so that EmbedContent and BatchEmbedContents do
+// not request the embedding of the same content twice. Set it in
+// [ClientConfig.EmbeddingsCache].
+type EmbeddingsCache interface {
+	// Get returns the embedding stored for key, if any.
+	Get(key string) ([]float32, bool)
+	// Set stores the embedding vec for key.
+	Set(key string, vec []float32)
+}
+
+// InMemoryEmbeddingsCache is an [EmbeddingsCache] that keeps up to a maximum number
+// of embeddings in memory, evicting the least recently used one when it is full. It
+// is safe for concurrent use.
+type InMemoryEmbeddingsCache struct {
+	mu      sync.Mutex
+	maxSize int
+	order   *list.List               // Front is the most recently used.
+	entries map[string]*list.Element // Values are *embeddingsCacheEntry.
+}
+
+type embeddingsCacheEntry struct {
+	key string
+	vec []float32
+}
+
+// NewInMemoryEmbeddingsCache returns a cache that holds up to maxSize embeddings.
+// It panics if maxSize is not positive.
+func NewInMemoryEmbeddingsCache(maxSize int) *InMemoryEmbeddingsCache {
+	if maxSize <= 0 {
+		panic("genai: NewInMemoryEmbeddingsCache: maxSize must be positive")
+	}
+	return &InMemoryEmbeddingsCache{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Get returns a copy of the embedding stored for key and marks it as recently used.
+func (c *InMemoryEmbeddingsCache) Get(key string) ([]float32, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return slices.Clone(e.Value.(*embeddingsCacheEntry).vec), true
+}
+
+// Set stores a copy of vec for key, evicting the least recently used embedding if
+// the cache is full.
+func (c *InMemoryEmbeddingsCache) Set(key string, vec []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*embeddingsCacheEntry).vec = slices.Clone(vec)
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&embeddingsCacheEntry{key: key, vec: slices.Clone(vec)})
+	if c.order.Len() > c.maxSize {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*embeddingsCacheEntry).key)
+	}
+}
+
+// Len returns the number of embeddings in the cache.
+func (c *InMemoryEmbeddingsCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// embeddingCacheKey returns the SHA-256 of the JSON of everything that determines
+// the embedding of content: the backend, the model, the content and the config,
+// except its HTTP options.
+func (m Models) embeddingCacheKey(model string, content *Content, config *EmbedContentConfig) (string, error) {
+	if name, err := tModel(m.apiClient, model); err == nil {
+		model = name
+	}
+	key := struct {
+		Backend string              `json:"backend"`
+		Model   string              `json:"model"`
+		Content *Content            `json:"content"`
+		Config  *EmbedContentConfig `json:"config"`
+	}{Backend: m.apiClient.clientConfig.Backend.String(), Model: model, Content: content}
+	if config != nil {
+		c := *config
+		c.HTTPOptions = nil
+		key.Config = &c
+	}
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("embeddingCacheKey: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newEmbeddingServer returns a Gemini API server whose embedding of a text is
+// [len(text)], and the texts it was asked to embed.
+func newEmbeddingServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+	var texts []string
+	embed := func(content map[string]any) map[string]any {
+		text := content["parts"].([]any)[0].(map[string]any)["text"].(string)
+		texts = append(texts, text)
+		return map[string]any{"values": []any{len(text)}}
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding request body: %v", err)
+		}
+		var response map[string]any
+		if strings.HasSuffix(r.URL.Path, ":batchEmbedContents") {
+			var embeddings []any
+			for _, req := range body["requests"].([]any) {
+				embeddings = append(embeddings, embed(req.(map[string]any)["content"].(map[string]any)))
+			}
+			response = map[string]any{"embeddings": embeddings}
+		} else {
+			response = map[string]any{"embedding": embed(body["content"].(map[string]any))}
+		}
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, &texts
+}
+
+func TestEmbeddingsCache(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("EmbedContent", func(t *testing.T) {
+		ts, texts := newEmbeddingServer(t)
+		ac := newTestAPIClient(ts, BackendGeminiAPI)
+		ac.clientConfig.EmbeddingsCache = NewInMemoryEmbeddingsCache(10)
+		m := Models{apiClient: ac}
+
+		for _, text := range []string{"a", "bb", "a", "bb", "a"} {
+			got, err := m.EmbedContent(ctx, "text-embedding-004", NewUserContent(NewPartFromText(text)), nil)
+			if err != nil {
+				t.Fatalf("EmbedContent() failed: %v", err)
+			}
+			if diff := cmp.Diff([]float32{float32(len(text))}, got.Embedding.Values); diff != "" {
+				t.Errorf("EmbedContent(%q) mismatch (-want +got):\n%s", text, diff)
+			}
+		}
+		// A different config is a different embedding.
+		if _, err := m.EmbedContent(ctx, "text-embedding-004", NewUserContent(NewPartFromText("a")), &EmbedContentConfig{TaskType: "RETRIEVAL_QUERY"}); err != nil {
+			t.Fatalf("EmbedContent() failed: %v", err)
+		}
+		if diff := cmp.Diff([]string{"a", "bb", "a"}, *texts); diff != "" {
+			t.Errorf("embedded texts mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("BatchEmbedContents", func(t *testing.T) {
+		ts, texts := newEmbeddingServer(t)
+		ac := newTestAPIClient(ts, BackendGeminiAPI)
+		ac.clientConfig.EmbeddingsCache = NewInMemoryEmbeddingsCache(10)
+		m := Models{apiClient: ac}
+
+		batch := func(texts ...string) []float32 {
+			t.Helper()
+			var requests []*EmbedContentRequest
+			for _, text := range texts {
+				requests = append(requests, &EmbedContentRequest{Content: NewUserContent(NewPartFromText(text))})
+			}
+			resp, err := m.BatchEmbedContents(ctx, "text-embedding-004", requests, nil)
+			if err != nil {
+				t.Fatalf("BatchEmbedContents() failed: %v", err)
+			}
+			var values []float32
+			for _, e := range resp.Embeddings {
+				values = append(values, e.Values...)
+			}
+			return values
+		}
+
+		if _, err := m.EmbedContent(ctx, "text-embedding-004", NewUserContent(NewPartFromText("a")), nil); err != nil {
+			t.Fatalf("EmbedContent() failed: %v", err)
+		}
+		if diff := cmp.Diff([]float32{1, 2, 1, 3, 2}, batch("a", "bb", "a", "ccc", "bb")); diff != "" {
+			t.Errorf("BatchEmbedContents() mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]float32{3, 1}, batch("ccc", "a")); diff != "" {
+			t.Errorf("BatchEmbedContents() mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]string{"a", "bb", "ccc"}, *texts); diff != "" {
+			t.Errorf("embedded texts mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestInMemoryEmbeddingsCache(t *testing.T) {
+	c := NewInMemoryEmbeddingsCache(2)
+	c.Set("a", []float32{1})
+	c.Set("b", []float32{2})
+	c.Get("a")
+	c.Set("c", []float32{3})
+
+	for _, tt := range []struct {
+		key    string
+		want   []float32
+		wantOK bool
+	}{
+		{"a", []float32{1}, true},
+		{"b", nil, false},
+		{"c", []float32{3}, true},
+	} {
+		got, ok := c.Get(tt.key)
+		if ok != tt.wantOK || !cmp.Equal(got, tt.want) {
+			t.Errorf("Get(%q) = %v, %v, want %v, %v", tt.key, got, ok, tt.want, tt.wantOK)
+		}
+	}
+	if got := c.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+
+	// The cache keeps its own copy of the embeddings.
+	vec := []float32{4}
+	c.Set("d", vec)
+	vec[0] = 0
+	got, _ := c.Get("d")
+	got[0] = 0
+	if got, _ := c.Get("d"); got[0] != 4 {
+		t.Errorf("Get(%q) = %v, want [4]", "d", got)
+	}
+}
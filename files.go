@@ -0,0 +1,575 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"iter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// uploadChunkGranularity is the size every non-final chunk of a resumable upload
+	// must be a multiple of.
+	uploadChunkGranularity = 256 * 1024
+	defaultUploadChunkSize = 8 * 1024 * 1024
+)
+
+var errFilesVertexAI = fmt.Errorf("Files API is only supported in the Gemini API")
+
+func uploadFileParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"config", "name"})
+	if fromName != nil {
+		fromName, err = tFileName(ac, fromName)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"file", "name"}, fromName)
+	}
+
+	fromMIMEType := getValueByPath(fromObject, []string{"config", "mimeType"})
+	if fromMIMEType != nil {
+		setValueByPath(toObject, []string{"file", "mimeType"}, fromMIMEType)
+	}
+
+	fromDisplayName := getValueByPath(fromObject, []string{"config", "displayName"})
+	if fromDisplayName != nil {
+		setValueByPath(toObject, []string{"file", "displayName"}, fromDisplayName)
+	}
+
+	return toObject, nil
+}
+
+func getFileParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		fromName, err = tFileName(ac, fromName)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"_url", "file"}, fromName)
+	}
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func deleteFileParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		fromName, err = tFileName(ac, fromName)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"_url", "file"}, fromName)
+	}
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func listFilesConfigToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromPageSize := getValueByPath(fromObject, []string{"pageSize"})
+	if fromPageSize != nil {
+		setValueByPath(parentObject, []string{"_query", "pageSize"}, fromPageSize)
+	}
+
+	fromPageToken := getValueByPath(fromObject, []string{"pageToken"})
+	if fromPageToken != nil {
+		setValueByPath(parentObject, []string{"_query", "pageToken"}, fromPageToken)
+	}
+
+	return toObject, nil
+}
+
+func listFilesParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = listFilesConfigToMldev(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func fileStatusFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromMessage := getValueByPath(fromObject, []string{"message"})
+	if fromMessage != nil {
+		setValueByPath(toObject, []string{"message"}, fromMessage)
+	}
+
+	fromCode := getValueByPath(fromObject, []string{"code"})
+	if fromCode != nil {
+		setValueByPath(toObject, []string{"code"}, fromCode)
+	}
+
+	return toObject, nil
+}
+
+func fileFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		setValueByPath(toObject, []string{"name"}, fromName)
+	}
+
+	fromDisplayName := getValueByPath(fromObject, []string{"displayName"})
+	if fromDisplayName != nil {
+		setValueByPath(toObject, []string{"displayName"}, fromDisplayName)
+	}
+
+	fromMIMEType := getValueByPath(fromObject, []string{"mimeType"})
+	if fromMIMEType != nil {
+		setValueByPath(toObject, []string{"mimeType"}, fromMIMEType)
+	}
+
+	fromSizeBytes := getValueByPath(fromObject, []string{"sizeBytes"})
+	if fromSizeBytes != nil {
+		setValueByPath(toObject, []string{"sizeBytes"}, fromSizeBytes)
+	}
+
+	fromCreateTime := getValueByPath(fromObject, []string{"createTime"})
+	if fromCreateTime != nil {
+		setValueByPath(toObject, []string{"createTime"}, fromCreateTime)
+	}
+
+	fromUpdateTime := getValueByPath(fromObject, []string{"updateTime"})
+	if fromUpdateTime != nil {
+		setValueByPath(toObject, []string{"updateTime"}, fromUpdateTime)
+	}
+
+	fromExpirationTime := getValueByPath(fromObject, []string{"expirationTime"})
+	if fromExpirationTime != nil {
+		setValueByPath(toObject, []string{"expirationTime"}, fromExpirationTime)
+	}
+
+	fromSHA256Hash := getValueByPath(fromObject, []string{"sha256Hash"})
+	if fromSHA256Hash != nil {
+		setValueByPath(toObject, []string{"sha256Hash"}, fromSHA256Hash)
+	}
+
+	fromURI := getValueByPath(fromObject, []string{"uri"})
+	if fromURI != nil {
+		setValueByPath(toObject, []string{"uri"}, fromURI)
+	}
+
+	fromState := getValueByPath(fromObject, []string{"state"})
+	if fromState != nil {
+		setValueByPath(toObject, []string{"state"}, fromState)
+	}
+
+	fromError := getValueByPath(fromObject, []string{"error"})
+	if fromError != nil {
+		fromError, err = fileStatusFromMldev(ac, fromError.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"error"}, fromError)
+	}
+
+	return toObject, nil
+}
+
+func deleteFileResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	return toObject, nil
+}
+
+func listFilesResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromNextPageToken := getValueByPath(fromObject, []string{"nextPageToken"})
+	if fromNextPageToken != nil {
+		setValueByPath(toObject, []string{"nextPageToken"}, fromNextPageToken)
+	}
+
+	fromFiles := getValueByPath(fromObject, []string{"files"})
+	if fromFiles != nil {
+		fromFiles, err = applyConverterToSlice(ac, fromFiles.([]any), fileFromMldev)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"files"}, fromFiles)
+	}
+
+	return toObject, nil
+}
+
+// Files manages the files uploaded to the Gemini API. Uploaded files can be referenced
+// in a prompt with [FileData]. The Files API is not available in Vertex AI.
+type Files struct {
+	apiClient *apiClient
+}
+
+// Get returns the metadata of the file with the given name.
+func (m Files) Get(ctx context.Context, name string, config *GetFileConfig) (*File, error) {
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		return nil, errFilesVertexAI
+	}
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"name": name, "config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(File)
+	body, err := getFileParametersToMldev(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	path, err := formatMap("{file}", urlParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err := sendRequest(ctx, m.apiClient, path, http.MethodGet, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fileFromMldev(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Delete deletes the file with the given name.
+func (m Files) Delete(ctx context.Context, name string, config *DeleteFileConfig) (*DeleteFileResponse, error) {
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		return nil, errFilesVertexAI
+	}
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"name": name, "config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(DeleteFileResponse)
+	body, err := deleteFileParametersToMldev(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	path, err := formatMap("{file}", urlParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err := sendRequest(ctx, m.apiClient, path, http.MethodDelete, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = deleteFileResponseFromMldev(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (m Files) list(ctx context.Context, config *ListFilesConfig) (*ListFilesResponse, error) {
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(ListFilesResponse)
+	body, err := listFilesParametersToMldev(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	path := "files"
+	if _, ok := body["_query"]; ok {
+		path += "?" + createURLQuery(body["_query"].(map[string]any))
+		delete(body, "_query")
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err := sendRequest(ctx, m.apiClient, path, http.MethodGet, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = listFilesResponseFromMldev(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// List returns an iterator over the files owned by the project. Pages are fetched
+// lazily as the iteration advances.
+func (m Files) List(ctx context.Context, config *ListFilesConfig) iter.Seq2[*File, error] {
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		return func(yield func(*File, error) bool) {
+			yield(nil, errFilesVertexAI)
+		}
+	}
+	var pageToken string
+	if config != nil {
+		pageToken = config.PageToken
+	}
+	return allPages(ctx, pageToken, func(ctx context.Context, pageToken string) ([]*File, string, error) {
+		pageConfig := &ListFilesConfig{}
+		if config != nil {
+			*pageConfig = *config
+		}
+		pageConfig.PageToken = pageToken
+		response, err := m.list(ctx, pageConfig)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Files, response.NextPageToken, nil
+	})
+}
+
+// Upload uploads the content read from r using the resumable upload protocol. The
+// content is sent in chunks of config.ChunkSize bytes, so only one chunk is held
+// in memory at a time. If config.MIMEType is empty, it is detected from the first
+// bytes of the content.
+func (m Files) Upload(ctx context.Context, r io.Reader, config *UploadFileConfig) (*File, error) {
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		return nil, errFilesVertexAI
+	}
+	uploadConfig := &UploadFileConfig{}
+	if config != nil {
+		*uploadConfig = *config
+	}
+	chunkSize := uploadConfig.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultUploadChunkSize
+	}
+	if chunkSize < 0 || chunkSize%uploadChunkGranularity != 0 {
+		return nil, fmt.Errorf("Upload: chunk size %d is not a positive multiple of %d", chunkSize, uploadChunkGranularity)
+	}
+	httpOptions := mergeHTTPOptions(m.apiClient.clientConfig, uploadConfig.HTTPOptions)
+
+	chunk := make([]byte, chunkSize)
+	n, last, err := readChunk(r, chunk)
+	if err != nil {
+		return nil, err
+	}
+	if uploadConfig.MIMEType == "" {
+		uploadConfig.MIMEType = http.DetectContentType(chunk[:n])
+	}
+
+	parameterMap := make(map[string]any)
+	kwargs := map[string]any{"config": uploadConfig}
+	deepMarshal(kwargs, &parameterMap)
+	body, err := uploadFileParametersToMldev(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	uploadURL, err := startResumableUpload(ctx, m.apiClient, body, uploadConfig.MIMEType, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	var offset int64
+	for {
+		responseMap, err := uploadChunk(ctx, m.apiClient, uploadURL, chunk[:n], offset, last, httpOptions)
+		if err != nil {
+			return nil, err
+		}
+		offset += int64(n)
+		if last {
+			fromFile, _ := responseMap["file"].(map[string]any)
+			if fromFile == nil {
+				return nil, fmt.Errorf("Upload: upload finalized without file metadata")
+			}
+			fromFile, err = fileFromMldev(m.apiClient, fromFile, nil)
+			if err != nil {
+				return nil, err
+			}
+			var response = new(File)
+			err = mapToStruct(fromFile, response)
+			if err != nil {
+				return nil, err
+			}
+			return response, nil
+		}
+		n, last, err = readChunk(r, chunk)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// readChunk fills chunk from r. It reports whether r is exhausted, in which case
+// the read chunk is the last one.
+func readChunk(r io.Reader, chunk []byte) (n int, last bool, err error) {
+	n, err = io.ReadFull(r, chunk)
+	switch err {
+	case nil:
+		return n, false, nil
+	case io.EOF, io.ErrUnexpectedEOF:
+		return n, true, nil
+	default:
+		return 0, false, fmt.Errorf("readChunk: error reading upload content: %w", err)
+	}
+}
+
+func uploadBaseURL(httpOptions *HTTPOptions) string {
+	return fmt.Sprintf("%s/upload/%s/files", strings.TrimSuffix(httpOptions.BaseURL, "/"), httpOptions.APIVersion)
+}
+
+// startResumableUpload starts a resumable upload session for the file metadata in
+// body and returns the URL the content must be uploaded to.
+func startResumableUpload(ctx context.Context, ac *apiClient, body map[string]any, mimeType string, httpOptions *HTTPOptions) (string, error) {
+	ctx, cancel := withRequestTimeout(ctx, httpOptions)
+	defer cancel()
+	b, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("startResumableUpload: error encoding body %#v: %w", body, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadBaseURL(httpOptions), bytes.NewReader(b))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Goog-Upload-Protocol", "resumable")
+	req.Header.Set("X-Goog-Upload-Command", "start")
+	req.Header.Set("X-Goog-Upload-Header-Content-Type", mimeType)
+	setClientHeaders(req, ac)
+
+	resp, err := doRequest(ctx, ac, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if !httpStatusOk(resp) {
+		return "", newAPIError(resp)
+	}
+	uploadURL := resp.Header.Get("X-Goog-Upload-URL")
+	if uploadURL == "" {
+		return "", fmt.Errorf("startResumableUpload: response is missing the upload URL")
+	}
+	return uploadURL, nil
+}
+
+// uploadChunk sends one chunk of a resumable upload starting at offset. The last
+// chunk finalizes the upload, and the returned map holds the server response.
+func uploadChunk(ctx context.Context, ac *apiClient, uploadURL string, chunk []byte, offset int64, last bool, httpOptions *HTTPOptions) (map[string]any, error) {
+	ctx, cancel := withRequestTimeout(ctx, httpOptions)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uploadURL, bytes.NewReader(chunk))
+	if err != nil {
+		return nil, err
+	}
+	command := "upload"
+	if last {
+		command = "upload, finalize"
+	}
+	req.Header.Set("X-Goog-Upload-Command", command)
+	req.Header.Set("X-Goog-Upload-Offset", strconv.FormatInt(offset, 10))
+	setClientHeaders(req, ac)
+
+	resp, err := doRequest(ctx, ac, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if !last {
+		if !httpStatusOk(resp) {
+			return nil, newAPIError(resp)
+		}
+		io.Copy(io.Discard, resp.Body)
+		return nil, nil
+	}
+	return deserializeUnaryResponse(resp)
+}
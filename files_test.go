@@ -0,0 +1,204 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type uploadedChunk struct {
+	Command string
+	Offset  string
+	Size    int
+}
+
+func TestFilesUpload(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc        string
+		contentSize int
+		config      *UploadFileConfig
+		wantStart   string
+		wantMIME    string
+		wantChunks  []uploadedChunk
+	}{
+		{
+			desc:        "single chunk with detected MIME type",
+			contentSize: 10,
+			config:      &UploadFileConfig{DisplayName: "notes"},
+			wantStart:   `{"file":{"displayName":"notes","mimeType":"text/plain; charset=utf-8"}}`,
+			wantMIME:    "text/plain; charset=utf-8",
+			wantChunks:  []uploadedChunk{{Command: "upload, finalize", Offset: "0", Size: 10}},
+		},
+		{
+			desc:        "multiple chunks",
+			contentSize: uploadChunkGranularity + 10,
+			config:      &UploadFileConfig{Name: "notes", MIMEType: "text/plain", ChunkSize: uploadChunkGranularity},
+			wantStart:   `{"file":{"mimeType":"text/plain","name":"files/notes"}}`,
+			wantMIME:    "text/plain",
+			wantChunks: []uploadedChunk{
+				{Command: "upload", Offset: "0", Size: uploadChunkGranularity},
+				{Command: "upload, finalize", Offset: fmt.Sprint(uploadChunkGranularity), Size: 10},
+			},
+		},
+		{
+			desc:        "content size is a multiple of the chunk size",
+			contentSize: uploadChunkGranularity,
+			config:      &UploadFileConfig{MIMEType: "text/plain", ChunkSize: uploadChunkGranularity},
+			wantStart:   `{"file":{"mimeType":"text/plain"}}`,
+			wantMIME:    "text/plain",
+			wantChunks: []uploadedChunk{
+				{Command: "upload", Offset: "0", Size: uploadChunkGranularity},
+				{Command: "upload, finalize", Offset: fmt.Sprint(uploadChunkGranularity), Size: 0},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotChunks []uploadedChunk
+			var ts *httptest.Server
+			ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				switch r.URL.Path {
+				case "/upload/v1beta/files":
+					if got := r.Header.Get("X-Goog-Upload-Protocol"); got != "resumable" {
+						t.Errorf("upload protocol = %q, want %q", got, "resumable")
+					}
+					if got := r.Header.Get("X-Goog-Upload-Header-Content-Type"); got != tt.wantMIME {
+						t.Errorf("upload content type = %q, want %q", got, tt.wantMIME)
+					}
+					if got := r.Header.Get("x-goog-api-key"); got != "test-api-key" {
+						t.Errorf("api key = %q, want %q", got, "test-api-key")
+					}
+					if diff := cmp.Diff(tt.wantStart, strings.TrimSpace(string(body))); diff != "" {
+						t.Errorf("start request body mismatch (-want +got):\n%s", diff)
+					}
+					w.Header().Set("X-Goog-Upload-URL", ts.URL+"/resumable/session-1")
+				case "/resumable/session-1":
+					command := r.Header.Get("X-Goog-Upload-Command")
+					gotChunks = append(gotChunks, uploadedChunk{Command: command, Offset: r.Header.Get("X-Goog-Upload-Offset"), Size: len(body)})
+					if command == "upload, finalize" {
+						fmt.Fprint(w, `{"file":{"name":"files/notes","mimeType":"text/plain","sizeBytes":"10","uri":"https://example.com/files/notes","state":"ACTIVE"}}`)
+					}
+				default:
+					t.Errorf("unexpected request path %q", r.URL.Path)
+				}
+			}))
+			defer ts.Close()
+
+			f := Files{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+			content := bytes.Repeat([]byte("a"), tt.contentSize)
+			got, err := f.Upload(ctx, bytes.NewReader(content), tt.config)
+			if err != nil {
+				t.Fatalf("Upload() error = %v", err)
+			}
+			want := &File{Name: "files/notes", MIMEType: "text/plain", SizeBytes: 10, URI: "https://example.com/files/notes", State: FileStateActive}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Upload() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantChunks, gotChunks); diff != "" {
+				t.Errorf("uploaded chunks mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid chunk size", func(t *testing.T) {
+		f := Files{apiClient: &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}}
+		_, err := f.Upload(ctx, strings.NewReader("a"), &UploadFileConfig{MIMEType: "text/plain", ChunkSize: 1000})
+		if err == nil {
+			t.Error("Upload() succeeded, want error")
+		}
+	})
+}
+
+func TestFilesGetListDelete(t *testing.T) {
+	ctx := context.Background()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1beta/files/abc":
+			fmt.Fprint(w, `{"name":"files/abc","sizeBytes":"1024","state":"PROCESSING"}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/v1beta/files/abc":
+			fmt.Fprint(w, `{}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/v1beta/files":
+			if r.URL.Query().Get("pageToken") == "" {
+				fmt.Fprint(w, `{"files":[{"name":"files/a"}],"nextPageToken":"page-2"}`)
+				return
+			}
+			fmt.Fprint(w, `{"files":[{"name":"files/b","state":"FAILED","error":{"code":3,"message":"bad file"}}]}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+	f := Files{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+
+	// Names may be given as IDs, resource names or URIs.
+	for _, name := range []string{"abc", "files/abc", "https://generativelanguage.googleapis.com/v1beta/files/abc"} {
+		got, err := f.Get(ctx, name, nil)
+		if err != nil {
+			t.Fatalf("Get(%q) error = %v", name, err)
+		}
+		if diff := cmp.Diff(&File{Name: "files/abc", SizeBytes: 1024, State: FileStateProcessing}, got); diff != "" {
+			t.Errorf("Get(%q) mismatch (-want +got):\n%s", name, diff)
+		}
+	}
+
+	if _, err := f.Delete(ctx, "abc", nil); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+
+	var got []*File
+	for file, err := range f.List(ctx, nil) {
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		got = append(got, file)
+	}
+	want := []*File{
+		{Name: "files/a"},
+		{Name: "files/b", State: FileStateFailed, Error: &FileStatus{Code: 3, Message: "bad file"}},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("List() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFilesVertexAI(t *testing.T) {
+	ctx := context.Background()
+	f := Files{apiClient: &apiClient{clientConfig: &ClientConfig{Backend: BackendVertexAI}}}
+	if _, err := f.Get(ctx, "abc", nil); err == nil {
+		t.Error("Get() succeeded, want error")
+	}
+	if _, err := f.Delete(ctx, "abc", nil); err == nil {
+		t.Error("Delete() succeeded, want error")
+	}
+	if _, err := f.Upload(ctx, strings.NewReader("a"), nil); err == nil {
+		t.Error("Upload() succeeded, want error")
+	}
+	for _, err := range f.List(ctx, nil) {
+		if err == nil {
+			t.Error("List() succeeded, want error")
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const defaultMaxFunctionCallingIterations = 10
+
+// ToolFunc implements a function declared to the model. It receives the arguments
+// of the function call and returns the function response.
+type ToolFunc func(ctx context.Context, args map[string]any) (map[string]any, error)
+
+// GenerateContentWithTools calls GenerateContent and runs the functions called by
+// the model until the model answers without calling any function. The functions
+// are looked up by name in tools, and must also be declared in config.Tools. The
+// function calls of a response run concurrently, and their responses are sent back
+// to the model in the next request. An error returned by a ToolFunc is reported to
+// the model under the "error" key of the function response.
+//
+// The loop is configured by config.AutomaticFunctionCalling. If the model still
+// calls functions after MaxIterations requests, the last response is returned.
+func (m Models) GenerateContentWithTools(ctx context.Context, model string, contents []*Content, tools map[string]ToolFunc, config *GenerateContentConfig) (*GenerateContentResponse, error) {
+	return generateContentWithTools(ctx, m, model, contents, tools, config)
+}
+
+// generateContentWithTools implements GenerateContentWithTools with
+// m.GenerateContent, for Models and MockModels.
+func generateContentWithTools(ctx context.Context, m ModelsInterface, model string, contents []*Content, tools map[string]ToolFunc, config *GenerateContentConfig) (*GenerateContentResponse, error) {
+	maxIterations := int64(defaultMaxFunctionCallingIterations)
+	var maxParallelCalls int64
+	if config != nil && config.AutomaticFunctionCalling != nil {
+		if config.AutomaticFunctionCalling.MaxIterations > 0 {
+			maxIterations = config.AutomaticFunctionCalling.MaxIterations
+		}
+		maxParallelCalls = config.AutomaticFunctionCalling.MaxParallelCalls
+	}
+
+	history := append([]*Content(nil), contents...)
+	var response *GenerateContentResponse
+	for i := int64(0); i < maxIterations; i++ {
+		var err error
+		response, err = m.GenerateContent(ctx, model, history, config)
+		if err != nil {
+			return nil, err
+		}
+		functionCalls := response.FunctionCalls()
+		if len(functionCalls) == 0 {
+			return response, nil
+		}
+		functionResponses, err := callTools(ctx, tools, functionCalls, maxParallelCalls)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, response.Candidates[0].Content, &Content{Role: RoleUser, Parts: functionResponses})
+	}
+	return response, nil
+}
+
+// callTools runs the function calls, at most maxParallelCalls at a time, and returns
+// the function response parts in the order of the calls.
+func callTools(ctx context.Context, tools map[string]ToolFunc, functionCalls []*FunctionCall, maxParallelCalls int64) ([]*Part, error) {
+	for _, fc := range functionCalls {
+		if _, ok := tools[fc.Name]; !ok {
+			return nil, fmt.Errorf("callTools: no tool registered for function %q", fc.Name)
+		}
+	}
+	if maxParallelCalls <= 0 || maxParallelCalls > int64(len(functionCalls)) {
+		maxParallelCalls = int64(len(functionCalls))
+	}
+
+	parts := make([]*Part, len(functionCalls))
+	sem := make(chan struct{}, maxParallelCalls)
+	var wg sync.WaitGroup
+	for i, fc := range functionCalls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			response, err := tools[fc.Name](ctx, fc.Args)
+			if err != nil {
+				response = map[string]any{"error": err.Error()}
+			}
+			parts[i] = &Part{FunctionResponse: &FunctionResponse{ID: fc.ID, Name: fc.Name, Response: response}}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const functionCallsResponse = `{"candidates":[{"content":{"role":"model","parts":[` +
+	`{"functionCall":{"id":"call-1","name":"getWeather","args":{"city":"Paris"}}},` +
+	`{"functionCall":{"id":"call-2","name":"getTime","args":{"city":"Paris"}}}]}}]}`
+
+// replyInOrder answers each request with the next of the given responses, repeating
+// the last one.
+func replyInOrder(responses []string) func(http.ResponseWriter, *http.Request, int, []*Content) {
+	return func(w http.ResponseWriter, _ *http.Request, n int, _ []*Content) {
+		fmt.Fprint(w, responses[min(n, len(responses)-1)])
+	}
+}
+
+func TestGenerateContentWithTools(t *testing.T) {
+	ctx := context.Background()
+	ts, gotContents := newRecordingTestServer(t, replyInOrder([]string{
+		functionCallsResponse,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"It is sunny in Paris."}]},"finishReason":"STOP"}]}`,
+	}))
+	defer ts.Close()
+
+	tools := map[string]ToolFunc{
+		"getWeather": func(ctx context.Context, args map[string]any) (map[string]any, error) {
+			return map[string]any{"weather": "sunny in " + args["city"].(string)}, nil
+		},
+		"getTime": func(ctx context.Context, args map[string]any) (map[string]any, error) {
+			return nil, errors.New("clock unavailable")
+		},
+	}
+	m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+	response, err := m.GenerateContentWithTools(ctx, "gemini-2.0-flash", Text("Weather in Paris?"), tools, nil)
+	if err != nil {
+		t.Fatalf("GenerateContentWithTools() error = %v", err)
+	}
+	if got, _ := response.Text(); got != "It is sunny in Paris." {
+		t.Errorf("GenerateContentWithTools() text = %q, want %q", got, "It is sunny in Paris.")
+	}
+
+	requests := gotContents()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	want := []*Content{
+		{Role: RoleUser, Parts: []*Part{{Text: "Weather in Paris?"}}},
+		{Role: RoleModel, Parts: []*Part{
+			{FunctionCall: &FunctionCall{ID: "call-1", Name: "getWeather", Args: map[string]any{"city": "Paris"}}},
+			{FunctionCall: &FunctionCall{ID: "call-2", Name: "getTime", Args: map[string]any{"city": "Paris"}}},
+		}},
+		{Role: RoleUser, Parts: []*Part{
+			{FunctionResponse: &FunctionResponse{ID: "call-1", Name: "getWeather", Response: map[string]any{"weather": "sunny in Paris"}}},
+			{FunctionResponse: &FunctionResponse{ID: "call-2", Name: "getTime", Response: map[string]any{"error": "clock unavailable"}}},
+		}},
+	}
+	if diff := cmp.Diff(want, requests[1]); diff != "" {
+		t.Errorf("second request contents mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGenerateContentWithToolsLimits(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("max iterations", func(t *testing.T) {
+		ts, gotContents := newRecordingTestServer(t, replyInOrder([]string{functionCallsResponse}))
+		defer ts.Close()
+
+		noop := func(ctx context.Context, args map[string]any) (map[string]any, error) { return nil, nil }
+		tools := map[string]ToolFunc{"getWeather": noop, "getTime": noop}
+		config := &GenerateContentConfig{AutomaticFunctionCalling: &AutomaticFunctionCallingConfig{MaxIterations: 3}}
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		response, err := m.GenerateContentWithTools(ctx, "gemini-2.0-flash", Text("Weather in Paris?"), tools, config)
+		if err != nil {
+			t.Fatalf("GenerateContentWithTools() error = %v", err)
+		}
+		if got := len(response.FunctionCalls()); got != 2 {
+			t.Errorf("last response has %d function calls, want 2", got)
+		}
+		if got := len(gotContents()); got != 3 {
+			t.Errorf("got %d requests, want 3", got)
+		}
+	})
+
+	t.Run("max parallel calls", func(t *testing.T) {
+		ts, _ := newRecordingTestServer(t, replyInOrder([]string{functionCallsResponse, `{"candidates":[{"content":{"role":"model","parts":[{"text":"done"}]}}]}`}))
+		defer ts.Close()
+
+		var running, maxRunning atomic.Int32
+		slow := func(ctx context.Context, args map[string]any) (map[string]any, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				old := maxRunning.Load()
+				if n <= old || maxRunning.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil, nil
+		}
+		tools := map[string]ToolFunc{"getWeather": slow, "getTime": slow}
+		config := &GenerateContentConfig{AutomaticFunctionCalling: &AutomaticFunctionCallingConfig{MaxParallelCalls: 1}}
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		if _, err := m.GenerateContentWithTools(ctx, "gemini-2.0-flash", Text("Weather in Paris?"), tools, config); err != nil {
+			t.Fatalf("GenerateContentWithTools() error = %v", err)
+		}
+		if got := maxRunning.Load(); got != 1 {
+			t.Errorf("got %d concurrent calls, want 1", got)
+		}
+	})
+
+	t.Run("unknown function", func(t *testing.T) {
+		ts, _ := newRecordingTestServer(t, replyInOrder([]string{functionCallsResponse}))
+		defer ts.Close()
+
+		tools := map[string]ToolFunc{
+			"getWeather": func(ctx context.Context, args map[string]any) (map[string]any, error) { return nil, nil },
+		}
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		if _, err := m.GenerateContentWithTools(ctx, "gemini-2.0-flash", Text("Weather in Paris?"), tools, nil); err == nil {
+			t.Error("GenerateContentWithTools() succeeded, want error")
+		}
+	})
+}
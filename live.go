@@ -17,12 +17,19 @@
 package genai
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 
 	"github.com/gorilla/websocket"
+	"golang.org/x/oauth2"
 )
 
 // Live can be used to create a realtime connection to the API.
@@ -30,25 +37,93 @@ import (
 // The live module is experimental.
 //
 //	client, _ := genai.NewClient(ctx, &genai.ClientConfig{})
-//	session, _ := client.Live.Connect(model, &genai.LiveConnectConfig{}).
+//	session, _ := client.Live.Connect(ctx, model, &genai.LiveConnectConfig{}).
 type Live struct {
 	apiClient *apiClient
+
+	mu    sync.Mutex
+	token *oauth2.Token // Last token used to connect to Vertex AI.
 }
 
 // Session is a realtime connection to the API.
 // The live module is experimental.
 type Session struct {
-	conn      *websocket.Conn
+	live      *Live
+	model     string
 	apiClient *apiClient
+
+	mu               sync.Mutex
+	config           *LiveConnectConfig // Config reused by Reconnect.
+	conn             *websocket.Conn    // Current connection, replaced by Reconnect.
+	done             chan struct{}      // Closed when conn is terminated.
+	stopCloseConn    func() bool        // Stops closing conn when the client is closed.
+	connected        bool
+	resumptionHandle string // Latest handle sent by the server for resuming the session.
 }
 
 // Connect establishes a realtime connection to the specified model with given configuration.
 // It returns a Session object representing the connection or an error if the connection fails.
+// The context bounds the WebSocket handshake and the wait for the setup to complete;
+// if it is done first, Connect returns ctx.Err(). It has no effect on the returned session.
 // The live module is experimental.
-func (r *Live) Connect(model string, config *LiveConnectConfig) (*Session, error) {
-	baseURL, err := url.Parse(r.apiClient.clientConfig.HTTPOptions.BaseURL)
+func (r *Live) Connect(ctx context.Context, model string, config *LiveConnectConfig) (*Session, error) {
+	config, err := cloneLiveConnectConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse base URL: %w", err)
+		return nil, err
+	}
+	s := &Session{
+		live:      r,
+		model:     model,
+		apiClient: r.apiClient,
+		config:    config,
+	}
+	if config != nil && config.SessionResumption != nil {
+		s.resumptionHandle = config.SessionResumption.Handle
+	}
+	if err := s.connect(ctx, config); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// cloneLiveConnectConfig returns a copy of config whose tools and system instruction
+// are deep copies, so that the session is not affected if the caller modifies them
+// after Connect.
+func cloneLiveConnectConfig(config *LiveConnectConfig) (*LiveConnectConfig, error) {
+	if config == nil {
+		return nil, nil
+	}
+	c := *config
+	c.SystemInstruction = config.SystemInstruction.DeepCopy()
+	if config.Tools != nil {
+		// Tools hold only API data, so a JSON round trip copies them exactly.
+		data, err := json.Marshal(config.Tools)
+		if err != nil {
+			return nil, fmt.Errorf("copy config.Tools: %w", err)
+		}
+		c.Tools = nil
+		if err := json.Unmarshal(data, &c.Tools); err != nil {
+			return nil, fmt.Errorf("copy config.Tools: %w", err)
+		}
+	}
+	return &c, nil
+}
+
+// connect dials a new connection, sends the setup message and waits for the setup
+// to complete. On success, the connection replaces the current one of the session.
+func (s *Session) connect(ctx context.Context, config *LiveConnectConfig) error {
+	if s.apiClient.closed() {
+		return ErrClientClosed
+	}
+	r := s.live
+	var requestHTTPOptions *HTTPOptions
+	if config != nil {
+		requestHTTPOptions = config.HTTPOptions
+	}
+	httpOptions := mergeHTTPOptions(r.apiClient.clientConfig, requestHTTPOptions)
+	baseURL, err := url.Parse(httpOptions.BaseURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse base URL: %w", err)
 	}
 	scheme := baseURL.Scheme
 	// Avoid overwrite schema if websocket scheme is already specified.
@@ -57,16 +132,7 @@ func (r *Live) Connect(model string, config *LiveConnectConfig) (*Session, error
 	}
 
 	var u url.URL
-	var header http.Header
 	if r.apiClient.clientConfig.Backend == BackendVertexAI {
-		token, err := r.apiClient.clientConfig.Credentials.TokenSource.Token()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get token: %w", err)
-		}
-		header = http.Header{
-			"Content-Type":  []string{"application/json"},
-			"Authorization": []string{fmt.Sprintf("Bearer %s", token.AccessToken)},
-		}
 		u = url.URL{
 			Scheme: scheme,
 			Host:   baseURL.Host,
@@ -81,21 +147,23 @@ func (r *Live) Connect(model string, config *LiveConnectConfig) (*Session, error
 			Path:     "/ws/google.ai.generativelanguage.v1alpha.GenerativeService.BidiGenerateContent",
 			RawQuery: fmt.Sprintf("key=%s", r.apiClient.clientConfig.APIKey),
 		}
-		// TODO(b/372730941): support custom header
-		header = http.Header{}
 	}
 
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), header)
-	if err != nil {
-		return nil, fmt.Errorf("Connect to %s failed: %w", u.String(), err)
+	var subprotocols []string
+	if config != nil {
+		subprotocols = config.Subprotocols
 	}
-	s := &Session{
-		conn:      conn,
-		apiClient: r.apiClient,
+	conn, err := r.dial(ctx, u.String(), httpOptions.ExtraHeaders, subprotocols)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("Connect to %s failed: %w", u.String(), err)
 	}
-	modelFullName, err := tModelFullName(r.apiClient, model)
+	modelFullName, err := tModelFullName(r.apiClient, s.model)
 	if err != nil {
-		return nil, err
+		conn.Close()
+		return err
 	}
 	kwargs := map[string]any{"model": modelFullName, "config": config}
 	parameterMap := make(map[string]any)
@@ -109,20 +177,219 @@ func (r *Live) Connect(model string, config *LiveConnectConfig) (*Session, error
 	}
 	body, err := toConverter(r.apiClient, parameterMap, nil)
 	if err != nil {
-		return nil, err
+		conn.Close()
+		return err
 	}
 	delete(body, "config")
 
 	clientBytes, err := json.Marshal(body)
 	if err != nil {
-		return nil, fmt.Errorf("marshal LiveClientSetup failed: %w", err)
+		conn.Close()
+		return fmt.Errorf("marshal LiveClientSetup failed: %w", err)
+	}
+	if err := conn.WriteMessage(websocket.TextMessage, clientBytes); err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to send setup message: %w", err)
+	}
+	if err := s.awaitSetupComplete(ctx, conn); err != nil {
+		conn.Close()
+		return err
 	}
-	s.conn.WriteMessage(websocket.TextMessage, clientBytes)
-	_, err = s.Receive()
+
+	done := make(chan struct{})
+	s.mu.Lock()
+	s.conn = conn
+	s.done = done
+	s.connected = true
+	s.stopCloseConn = func() bool { return false }
+	if closeCtx := s.apiClient.closeCtx; closeCtx != nil {
+		s.stopCloseConn = context.AfterFunc(closeCtx, func() { s.closeConn(conn) })
+	}
+	s.mu.Unlock()
+	if config != nil && config.KeepAliveInterval > 0 {
+		timeout := config.KeepAliveTimeout
+		if timeout <= 0 {
+			timeout = config.KeepAliveInterval
+		}
+		s.startKeepAlive(conn, done, config.KeepAliveInterval, timeout)
+	}
+	return nil
+}
+
+// Reconnect closes the current connection of the session, if still open, and
+// replaces it with a new one set up with config, or with the config of the previous
+// connection if config is nil. If session resumption is enabled in the config and
+// the server sent a resumption handle, the new connection resumes the session.
+// Send and Receive calls in flight on the previous connection fail; later calls use
+// the new connection. See Connect for the meaning of ctx.
+// The live module is experimental.
+func (s *Session) Reconnect(ctx context.Context, config *LiveConnectConfig) error {
+	config, err := cloneLiveConnectConfig(config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to the server: %w", err)
+		return err
+	}
+	s.mu.Lock()
+	if config == nil {
+		config = s.config
+	} else {
+		s.config = config
+	}
+	handle := s.resumptionHandle
+	s.mu.Unlock()
+	if config != nil && config.SessionResumption != nil && handle != "" {
+		resumed := *config
+		resumed.SessionResumption = &SessionResumptionConfig{Handle: handle}
+		config = &resumed
+	}
+	s.Close()
+	return s.connect(ctx, config)
+}
+
+// IsConnected reports whether the current connection of the session is open. It
+// is false after Close, a failed read or a keepalive timeout, until Reconnect
+// succeeds.
+// The live module is experimental.
+func (s *Session) IsConnected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connected
+}
+
+// connection returns the current connection of the session.
+func (s *Session) connection() *websocket.Conn {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn
+}
+
+// Reconnect resumes a previous session identified by handle, typically the value
+// returned by Session.ResumptionHandle before the connection dropped. The other
+// settings are taken from config, which should match the one of the original
+// session. See Connect for the meaning of ctx.
+// The live module is experimental.
+func (r *Live) Reconnect(ctx context.Context, model string, handle string, config *LiveConnectConfig) (*Session, error) {
+	if handle == "" {
+		return nil, fmt.Errorf("Reconnect: handle must not be empty")
+	}
+	var resumed LiveConnectConfig
+	if config != nil {
+		resumed = *config
+	}
+	resumed.SessionResumption = &SessionResumptionConfig{Handle: handle}
+	return r.Connect(ctx, model, &resumed)
+}
+
+// startKeepAlive starts sending a ping on conn every interval and closes conn if
+// the pong does not arrive within timeout. It must be called before conn is read
+// from. done is closed when conn is terminated.
+func (s *Session) startKeepAlive(conn *websocket.Conn, done <-chan struct{}, interval, timeout time.Duration) {
+	pong := make(chan struct{}, 1)
+	conn.SetPongHandler(func(string) error {
+		select {
+		case pong <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	// After Client.Close, the connection is closed and needs no keepalive.
+	s.apiClient.goBackground(func() { s.keepAlive(conn, done, interval, timeout, pong) })
+}
+
+// keepAlive is the ping loop of startKeepAlive. It returns when conn is terminated.
+func (s *Session) keepAlive(conn *websocket.Conn, done <-chan struct{}, interval, timeout time.Duration, pong <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		// Drop a pong left over from a previous ping.
+		select {
+		case <-pong:
+		default:
+		}
+		if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout)); err != nil {
+			s.closeConn(conn)
+			return
+		}
+		select {
+		case <-done:
+			return
+		case <-pong:
+		case <-time.After(timeout):
+			s.closeConn(conn)
+			return
+		}
+	}
+}
+
+// dial opens the WebSocket connection with extraHeaders added to the handshake and
+// the given subprotocols offered to the server. For Vertex AI, the handshake is
+// authenticated with the cached token; if the server rejects it, a new token is
+// fetched and the handshake is retried once.
+func (r *Live) dial(ctx context.Context, u string, extraHeaders map[string]string, subprotocols []string) (*websocket.Conn, error) {
+	dialer := *websocket.DefaultDialer
+	dialer.Subprotocols = subprotocols
+	if r.apiClient.clientConfig.Backend != BackendVertexAI {
+		header := http.Header{}
+		setExtraHeaders(header, extraHeaders)
+		conn, _, err := dialer.DialContext(ctx, u, header)
+		return conn, err
+	}
+	for refresh := false; ; refresh = true {
+		token, err := r.accessToken(refresh)
+		if err != nil {
+			return nil, err
+		}
+		header := http.Header{
+			"Content-Type":  []string{"application/json"},
+			"Authorization": []string{fmt.Sprintf("Bearer %s", token.AccessToken)},
+		}
+		setExtraHeaders(header, extraHeaders)
+		conn, resp, err := dialer.DialContext(ctx, u, header)
+		if err != nil && !refresh && resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			continue
+		}
+		return conn, err
+	}
+}
+
+// accessToken returns the token used to authenticate with Vertex AI. The last
+// token is reused while it is valid, unless refresh is set.
+func (r *Live) accessToken(refresh bool) (*oauth2.Token, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !refresh && r.token.Valid() {
+		return r.token, nil
+	}
+	token, err := r.apiClient.clientConfig.Credentials.TokenSource.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	r.token = token
+	return token, nil
+}
+
+// awaitSetupComplete waits for the server to acknowledge the setup message sent on
+// conn. If ctx is done first, the pending read is abandoned and ctx.Err() is
+// returned; the caller closes the connection, which ends the read.
+func (s *Session) awaitSetupComplete(ctx context.Context, conn *websocket.Conn) error {
+	errc := make(chan error, 1)
+	go func() {
+		_, err := s.receive(conn)
+		errc <- err
+	}()
+	select {
+	case err := <-errc:
+		if err != nil {
+			return fmt.Errorf("failed to connect to the server: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return s, nil
 }
 
 // Send transmits a LiveClientMessage over the established connection.
@@ -153,15 +420,130 @@ func (s *Session) Send(input *LiveClientMessage) error {
 	if err != nil {
 		return fmt.Errorf("marshal client message error: %w", err)
 	}
-	return s.conn.WriteMessage(websocket.TextMessage, []byte(data))
+	return s.connection().WriteMessage(websocket.TextMessage, []byte(data))
+}
+
+// SetContextWindow injects contents into the session as if they had been said in the
+// conversation, without terminating the session. Each content is sent as a separate
+// client content message; all but the last leave the turn open, and the last one
+// completes the turn so the model responds. Roles must alternate between user and
+// model. An empty role is treated as user.
+// The live module is experimental.
+func (s *Session) SetContextWindow(ctx context.Context, contents []*Content) error {
+	if len(contents) == 0 {
+		return fmt.Errorf("SetContextWindow: contents must not be empty")
+	}
+	prevRole := ""
+	for i, c := range contents {
+		if c == nil {
+			return fmt.Errorf("SetContextWindow: contents[%d] is nil", i)
+		}
+		role := c.Role
+		if role == "" {
+			role = RoleUser
+		}
+		if role != RoleUser && role != RoleModel {
+			return fmt.Errorf("SetContextWindow: contents[%d] has invalid role %q, want %q or %q", i, c.Role, RoleUser, RoleModel)
+		}
+		if role == prevRole {
+			return fmt.Errorf("SetContextWindow: contents[%d] has role %q, roles must alternate between %q and %q", i, role, RoleUser, RoleModel)
+		}
+		prevRole = role
+	}
+
+	for i, c := range contents {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		msg := &LiveClientMessage{ClientContent: &LiveClientContent{
+			Turns:        []*Content{c},
+			TurnComplete: i == len(contents)-1,
+		}}
+		if err := s.Send(msg); err != nil {
+			return fmt.Errorf("SetContextWindow: send contents[%d] failed: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// InjectSystemInstruction adds text to the session context without asking the model
+// to respond. The Live API does not allow changing the system instruction after
+// setup, so the text is sent as an incomplete user turn.
+// The live module is experimental.
+func (s *Session) InjectSystemInstruction(ctx context.Context, text string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Send(&LiveClientMessage{ClientContent: &LiveClientContent{
+		Turns: []*Content{{Role: RoleUser, Parts: []*Part{{Text: text}}}},
+	}})
+}
+
+// SendText sends text as a complete user turn, so the model responds to it.
+// The live module is experimental.
+func (s *Session) SendText(ctx context.Context, text string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Send(&LiveClientMessage{ClientContent: &LiveClientContent{
+		Turns:        []*Content{{Role: RoleUser, Parts: []*Part{{Text: text}}}},
+		TurnComplete: true,
+	}})
+}
+
+// SendAudio sends a chunk of audio with the given MIME type as realtime input,
+// for example "audio/pcm;rate=16000".
+// The live module is experimental.
+func (s *Session) SendAudio(data []byte, mimeType string) error {
+	return s.Send(&LiveClientMessage{RealtimeInput: &LiveClientRealtimeInput{
+		MediaChunks: []*Blob{{Data: data, MIMEType: mimeType}},
+	}})
+}
+
+// SendFunctionResponses runs handler for each of the function calls of a tool call
+// message, concurrently, and sends all the function responses in a single tool
+// response message. An error returned by handler is reported to the model under
+// the "error" key of the function response, so that the other responses are still
+// sent. It returns ctx.Err() without sending anything if ctx is done once the
+// handlers return.
+// The live module is experimental.
+func (s *Session) SendFunctionResponses(ctx context.Context, calls []*FunctionCall, handler func(context.Context, *FunctionCall) (map[string]any, error)) error {
+	if len(calls) == 0 {
+		return fmt.Errorf("SendFunctionResponses: calls must not be empty")
+	}
+	responses := make([]*FunctionResponse, len(calls))
+	var wg sync.WaitGroup
+	for i, fc := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := handler(ctx, fc)
+			if err != nil {
+				response = map[string]any{"error": err.Error()}
+			}
+			responses[i] = &FunctionResponse{ID: fc.ID, Name: fc.Name, Response: response}
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.Send(&LiveClientMessage{ToolResponse: &LiveClientToolResponse{FunctionResponses: responses}})
 }
 
 // Receive reads a LiveServerMessage from the connection.
 // It returns the received message or an error if reading or unmarshalling fails.
 // The live module is experimental.
 func (s *Session) Receive() (*LiveServerMessage, error) {
-	messageType, msgBytes, err := s.conn.ReadMessage()
+	return s.receive(s.connection())
+}
+
+// receive reads a LiveServerMessage from conn.
+func (s *Session) receive(conn *websocket.Conn) (*LiveServerMessage, error) {
+	messageType, msgBytes, err := conn.ReadMessage()
 	if err != nil {
+		// Read errors are permanent, the connection can no longer be used.
+		s.closeConn(conn)
 		return nil, err
 	}
 	responseMap := make(map[string]any)
@@ -189,13 +571,96 @@ func (s *Session) Receive() (*LiveServerMessage, error) {
 	if err != nil {
 		return nil, err
 	}
+	if u := message.SessionResumptionUpdate; u != nil && u.Resumable && u.NewHandle != "" {
+		s.mu.Lock()
+		s.resumptionHandle = u.NewHandle
+		s.mu.Unlock()
+	}
 	return message, err
 }
 
-// Close terminates the connection.
+// ResumptionHandle returns the latest handle that can be passed to Live.Reconnect
+// to resume the session. It is empty unless session resumption was enabled in the
+// LiveConnectConfig and the session is resumable.
+// The live module is experimental.
+func (s *Session) ResumptionHandle() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.resumptionHandle
+}
+
+// ReceiveStream returns an iterator over the messages sent by the server. The
+// iteration stops after a message that completes the model's turn, or after
+// yielding an error. If the server closes the connection, the error is io.EOF.
+// If ctx is done while waiting for a message, the pending read is interrupted,
+// ctx.Err() is yielded and the session is closed.
+// The live module is experimental.
+func (s *Session) ReceiveStream(ctx context.Context) iter.Seq2[*LiveServerMessage, error] {
+	return func(yield func(*LiveServerMessage, error) bool) {
+		stop := context.AfterFunc(ctx, func() {
+			s.connection().SetReadDeadline(time.Now())
+		})
+		defer stop()
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			message, err := s.Receive()
+			if err != nil {
+				var closeErr *websocket.CloseError
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					err = ctxErr
+				} else if errors.As(err, &closeErr) {
+					err = io.EOF
+				}
+				yield(nil, err)
+				return
+			}
+			if !yield(message, nil) {
+				return
+			}
+			if message.ServerContent != nil && message.ServerContent.TurnComplete {
+				return
+			}
+		}
+	}
+}
+
+// Close terminates the connection. It is safe to call Close more than once.
 // The live module is experimental.
 func (s *Session) Close() {
-	s.conn.Close()
+	s.closeConn(s.connection())
+}
+
+// closeConn closes conn and, if it is the current connection of the session, marks
+// the session as disconnected.
+func (s *Session) closeConn(conn *websocket.Conn) {
+	s.mu.Lock()
+	if conn == s.conn && s.connected {
+		s.connected = false
+		close(s.done)
+		s.stopCloseConn()
+	}
+	s.mu.Unlock()
+	conn.Close()
+}
+
+// Subprotocol returns the WebSocket subprotocol negotiated with the server, or an
+// empty string if none was. See LiveConnectConfig.Subprotocols.
+// The live module is experimental.
+func (s *Session) Subprotocol() string {
+	return s.connection().Subprotocol()
+}
+
+// Done returns a channel that is closed when the current connection is terminated,
+// whether by Close, by a failed read or by a keepalive timeout. Reconnect replaces
+// the channel, so it must be called again after a reconnection.
+// The live module is experimental.
+func (s *Session) Done() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done
 }
 
 // BEGIN: Converter functions
@@ -237,6 +702,11 @@ func liveConnectConfigToMldev(ac *apiClient, fromObject map[string]any, parentOb
 		setValueByPath(parentObject, []string{"setup", "tools"}, fromTools)
 	}
 
+	fromSessionResumption := getValueByPath(fromObject, []string{"sessionResumption"})
+	if fromSessionResumption != nil {
+		setValueByPath(parentObject, []string{"setup", "sessionResumption"}, fromSessionResumption)
+	}
+
 	return toObject, nil
 }
 
@@ -278,6 +748,11 @@ func liveConnectConfigToVertex(ac *apiClient, fromObject map[string]any, parentO
 		setValueByPath(parentObject, []string{"setup", "tools"}, fromTools)
 	}
 
+	fromSessionResumption := getValueByPath(fromObject, []string{"sessionResumption"})
+	if fromSessionResumption != nil {
+		setValueByPath(parentObject, []string{"setup", "sessionResumption"}, fromSessionResumption)
+	}
+
 	return toObject, nil
 }
 
@@ -356,6 +831,11 @@ func liveClientSetupToMldev(ac *apiClient, fromObject map[string]any, parentObje
 		setValueByPath(toObject, []string{"tools"}, fromTools)
 	}
 
+	fromSessionResumption := getValueByPath(fromObject, []string{"sessionResumption"})
+	if fromSessionResumption != nil {
+		setValueByPath(toObject, []string{"sessionResumption"}, fromSessionResumption)
+	}
+
 	return toObject, nil
 }
 
@@ -392,6 +872,11 @@ func liveClientSetupToVertex(ac *apiClient, fromObject map[string]any, parentObj
 		setValueByPath(toObject, []string{"tools"}, fromTools)
 	}
 
+	fromSessionResumption := getValueByPath(fromObject, []string{"sessionResumption"})
+	if fromSessionResumption != nil {
+		setValueByPath(toObject, []string{"sessionResumption"}, fromSessionResumption)
+	}
+
 	return toObject, nil
 }
 
@@ -520,7 +1005,7 @@ func liveClientToolResponseToVertex(ac *apiClient, fromObject map[string]any, pa
 
 	fromFunctionResponses := getValueByPath(fromObject, []string{"functionResponses"})
 	if fromFunctionResponses != nil {
-		fromFunctionResponses, err = applyConverterToSlice(ac, fromFunctionResponses.([]any), functionResponseToVertex)
+		fromFunctionResponses, err = applyConverterToSlice(ac, withoutFunctionResponseIDs(fromFunctionResponses.([]any)), functionResponseToVertex)
 		if err != nil {
 			return nil, err
 		}
@@ -531,6 +1016,28 @@ func liveClientToolResponseToVertex(ac *apiClient, fromObject map[string]any, pa
 	return toObject, nil
 }
 
+// withoutFunctionResponseIDs returns the function responses without their id, which
+// Vertex AI does not support. This lets a response built from a function call
+// received from the Gemini API, which sets the id, be sent to Vertex AI.
+func withoutFunctionResponseIDs(responses []any) []any {
+	stripped := make([]any, len(responses))
+	for i, r := range responses {
+		m, ok := r.(map[string]any)
+		if !ok || m["id"] == nil {
+			stripped[i] = r
+			continue
+		}
+		c := make(map[string]any, len(m))
+		for k, v := range m {
+			if k != "id" {
+				c[k] = v
+			}
+		}
+		stripped[i] = c
+	}
+	return stripped
+}
+
 func liveClientMessageToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
@@ -850,6 +1357,11 @@ func liveServerMessageFromMldev(ac *apiClient, fromObject map[string]any, parent
 		setValueByPath(toObject, []string{"toolCallCancellation"}, fromToolCallCancellation)
 	}
 
+	fromSessionResumptionUpdate := getValueByPath(fromObject, []string{"sessionResumptionUpdate"})
+	if fromSessionResumptionUpdate != nil {
+		setValueByPath(toObject, []string{"sessionResumptionUpdate"}, fromSessionResumptionUpdate)
+	}
+
 	return toObject, nil
 }
 
@@ -896,6 +1408,11 @@ func liveServerMessageFromVertex(ac *apiClient, fromObject map[string]any, paren
 		setValueByPath(toObject, []string{"toolCallCancellation"}, fromToolCallCancellation)
 	}
 
+	fromSessionResumptionUpdate := getValueByPath(fromObject, []string{"sessionResumptionUpdate"})
+	if fromSessionResumptionUpdate != nil {
+		setValueByPath(toObject, []string{"sessionResumptionUpdate"}, fromSessionResumptionUpdate)
+	}
+
 	return toObject, nil
 }
 
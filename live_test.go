@@ -17,14 +17,19 @@ package genai
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/gorilla/websocket"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
 )
 
 type mockTokenSource struct {
@@ -137,7 +142,7 @@ func TestLiveConnect(t *testing.T) {
 			if err != nil {
 				t.Fatalf("NewClient failed: %v", err)
 			}
-			session, err := tt.client.Live.Connect(model, tt.config)
+			session, err := tt.client.Live.Connect(ctx, model, tt.config)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Connect() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -186,7 +191,7 @@ func TestLiveConnect(t *testing.T) {
 					tt.client.Live.apiClient.clientConfig.Credentials.TokenSource = mts
 				}
 
-				session, err := tt.client.Live.Connect("test-model", &LiveConnectConfig{})
+				session, err := tt.client.Live.Connect(ctx, "test-model", &LiveConnectConfig{})
 				if err != nil {
 					t.Fatalf("Connect failed: %v", err)
 				}
@@ -222,6 +227,159 @@ func TestLiveConnect(t *testing.T) {
 }
 
 // Helper function to set up a test websocket server.
+// sequenceTokenSource returns the given tokens in order, repeating the last one.
+type sequenceTokenSource struct {
+	mu     sync.Mutex
+	tokens []*oauth2.Token
+	calls  int
+}
+
+func (s *sequenceTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token := s.tokens[min(s.calls, len(s.tokens)-1)]
+	s.calls++
+	return token, nil
+}
+
+func TestLiveConnectTokenRefresh(t *testing.T) {
+	ctx := context.Background()
+	expired := &oauth2.Token{AccessToken: "expired-token", Expiry: time.Now().Add(-time.Hour)}
+	valid := &oauth2.Token{AccessToken: "valid-token", Expiry: time.Now().Add(time.Hour)}
+
+	var upgrader = websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer valid-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, _ := upgrader.Upgrade(w, r, nil)
+		defer conn.Close()
+		mt, _, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.WriteMessage(mt, []byte(`{"setupComplete":{}}`))
+	}))
+	defer ts.Close()
+
+	newClient := func(t *testing.T, tokenSource oauth2.TokenSource) *Client {
+		t.Helper()
+		client, err := NewClient(ctx, &ClientConfig{
+			Backend:     BackendVertexAI,
+			Project:     "test-project",
+			Location:    "test-location",
+			Credentials: &google.Credentials{TokenSource: tokenSource},
+			HTTPOptions: HTTPOptions{
+				BaseURL: strings.Replace(ts.URL, "http", "ws", 1),
+			},
+		})
+		if err != nil {
+			t.Fatalf("NewClient failed: %v", err)
+		}
+		return client
+	}
+
+	t.Run("refreshes once on 401", func(t *testing.T) {
+		tokenSource := &sequenceTokenSource{tokens: []*oauth2.Token{expired, valid}}
+		client := newClient(t, tokenSource)
+
+		session, err := client.Live.Connect(ctx, "test-model", &LiveConnectConfig{})
+		if err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		session.Close()
+		if tokenSource.calls != 2 {
+			t.Errorf("Token() called %d times, want 2", tokenSource.calls)
+		}
+
+		// The refreshed token is reused by later connections.
+		session, err = client.Live.Connect(ctx, "test-model", &LiveConnectConfig{})
+		if err != nil {
+			t.Fatalf("Connect failed: %v", err)
+		}
+		session.Close()
+		if tokenSource.calls != 2 {
+			t.Errorf("Token() called %d times, want 2", tokenSource.calls)
+		}
+	})
+
+	t.Run("gives up after one refresh", func(t *testing.T) {
+		tokenSource := &sequenceTokenSource{tokens: []*oauth2.Token{expired}}
+		client := newClient(t, tokenSource)
+
+		if _, err := client.Live.Connect(ctx, "test-model", &LiveConnectConfig{}); err == nil {
+			t.Fatalf("Connect() succeeded, want error")
+		}
+		if tokenSource.calls != 2 {
+			t.Errorf("Token() called %d times, want 2", tokenSource.calls)
+		}
+	})
+}
+
+func TestLiveConnectContextDeadline(t *testing.T) {
+	var upgrader = websocket.Upgrader{}
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _ := upgrader.Upgrade(w, r, nil)
+		defer conn.Close()
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		// Hold back the setup response until the test is over.
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	client, err := NewClient(context.Background(), &ClientConfig{
+		Backend: BackendGeminiAPI,
+		APIKey:  "test-api-key",
+		HTTPOptions: HTTPOptions{
+			BaseURL: strings.Replace(ts.URL, "http", "ws", 1),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	session, err := client.Live.Connect(ctx, "test-model", &LiveConnectConfig{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Connect() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if session != nil {
+		t.Errorf("Connect() session = %v, want nil", session)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Connect() returned after %v, want it to return at the deadline", elapsed)
+	}
+}
+
+func TestLiveConnectContextCanceled(t *testing.T) {
+	ts := setupTestWebsocketServer(t, nil, nil)
+	defer ts.Close()
+
+	client, err := NewClient(context.Background(), &ClientConfig{
+		Backend: BackendGeminiAPI,
+		APIKey:  "test-api-key",
+		HTTPOptions: HTTPOptions{
+			BaseURL: strings.Replace(ts.URL, "http", "ws", 1),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.Live.Connect(ctx, "test-model", &LiveConnectConfig{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Connect() error = %v, want %v", err, context.Canceled)
+	}
+}
+
 func setupTestWebsocketServer(t *testing.T, wantRequestBodySlice []string, fakeResponseBodySlice []string) *httptest.Server {
 	t.Helper()
 
@@ -253,3 +411,757 @@ func setupTestWebsocketServer(t *testing.T, wantRequestBodySlice []string, fakeR
 
 	return ts
 }
+
+// setupRecordingWebsocketServer starts a websocket server that completes the setup
+// handshake and then records every following client message without replying.
+func setupRecordingWebsocketServer(t *testing.T) (*httptest.Server, <-chan string) {
+	t.Helper()
+
+	var upgrader = websocket.Upgrader{}
+	messages := make(chan string, 16)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _ := upgrader.Upgrade(w, r, nil)
+		defer conn.Close()
+
+		mt, _, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(mt, []byte(`{"setupComplete":{}}`)); err != nil {
+			return
+		}
+		for {
+			_, message, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			messages <- string(message)
+		}
+	}))
+
+	return ts, messages
+}
+
+// newTestLiveSession connects a Gemini API session to the given test server.
+func newTestLiveSession(t *testing.T, ts *httptest.Server) *Session {
+	t.Helper()
+	return newTestLiveSessionWithConfig(t, ts, &LiveConnectConfig{})
+}
+
+func newTestLiveSessionWithConfig(t *testing.T, ts *httptest.Server, config *LiveConnectConfig) *Session {
+	t.Helper()
+	client, err := NewClient(context.Background(), &ClientConfig{
+		Backend: BackendGeminiAPI,
+		APIKey:  "test-api-key",
+		HTTPOptions: HTTPOptions{
+			BaseURL: strings.Replace(ts.URL, "http", "ws", 1),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	session, err := client.Live.Connect(context.Background(), "test-model", config)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	t.Cleanup(session.Close)
+	return session
+}
+
+func TestLiveConnectExtraHeaders(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header.Clone()
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		mt, _, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.WriteMessage(mt, []byte(`{"setupComplete":{}}`))
+		conn.ReadMessage()
+	}))
+	defer ts.Close()
+
+	newTestLiveSessionWithConfig(t, ts, &LiveConnectConfig{
+		HTTPOptions: &HTTPOptions{ExtraHeaders: map[string]string{
+			"X-Request-ID": "req-1",
+			"Content-Type": "text/plain",
+		}},
+	})
+
+	got := <-headers
+	if v := got.Get("X-Request-ID"); v != "req-1" {
+		t.Errorf("X-Request-ID = %q, want %q", v, "req-1")
+	}
+	if v := got.Get("Content-Type"); v != "" {
+		t.Errorf("Content-Type = %q, want it not to be set", v)
+	}
+}
+
+func TestLiveSessionResumption(t *testing.T) {
+	setups := make(chan string, 1)
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		mt, setup, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		setups <- string(setup)
+		conn.WriteMessage(mt, []byte(`{"setupComplete":{}}`))
+		conn.WriteMessage(mt, []byte(`{"sessionResumptionUpdate":{"newHandle":"handle-2","resumable":true}}`))
+		conn.ReadMessage()
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(context.Background(), &ClientConfig{
+		Backend:     BackendGeminiAPI,
+		APIKey:      "test-api-key",
+		HTTPOptions: HTTPOptions{BaseURL: strings.Replace(ts.URL, "http", "ws", 1)},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	session, err := client.Live.Reconnect(context.Background(), "test-model", "handle-1", nil)
+	if err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+	defer session.Close()
+
+	wantSetup := `{"setup":{"model":"models/test-model","sessionResumption":{"handle":"handle-1"}}}`
+	if diff := cmp.Diff(wantSetup, <-setups); diff != "" {
+		t.Errorf("setup message mismatch (-want +got):\n%s", diff)
+	}
+	if got := session.ResumptionHandle(); got != "handle-1" {
+		t.Errorf("ResumptionHandle() = %q, want %q", got, "handle-1")
+	}
+
+	message, err := session.Receive()
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	want := &LiveServerMessage{SessionResumptionUpdate: &LiveServerSessionResumptionUpdate{NewHandle: "handle-2", Resumable: true}}
+	if diff := cmp.Diff(want, message); diff != "" {
+		t.Errorf("Receive() mismatch (-want +got):\n%s", diff)
+	}
+	if got := session.ResumptionHandle(); got != "handle-2" {
+		t.Errorf("ResumptionHandle() = %q, want %q", got, "handle-2")
+	}
+
+	if _, err := client.Live.Reconnect(context.Background(), "test-model", "", nil); err == nil {
+		t.Errorf("Reconnect() with empty handle succeeded, want error")
+	}
+}
+
+func TestSessionReconnect(t *testing.T) {
+	setups := make(chan string, 2)
+	upgrader := websocket.Upgrader{}
+	connections := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		connections++
+		mt, setup, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		setups <- string(setup)
+		conn.WriteMessage(mt, []byte(`{"setupComplete":{}}`))
+		if connections == 1 {
+			// Drop the first connection after the first message, like a server-side timeout.
+			conn.WriteMessage(mt, []byte(`{"sessionResumptionUpdate":{"newHandle":"handle-1","resumable":true}}`))
+			conn.ReadMessage()
+			return
+		}
+		conn.WriteMessage(mt, []byte(`{"serverContent":{"turnComplete":true}}`))
+		conn.ReadMessage()
+	}))
+	defer ts.Close()
+
+	session := newTestLiveSessionWithConfig(t, ts, &LiveConnectConfig{SessionResumption: &SessionResumptionConfig{}})
+	<-setups
+	if !session.IsConnected() {
+		t.Fatalf("IsConnected() = false after Connect, want true")
+	}
+	if _, err := session.Receive(); err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if err := session.SendText(context.Background(), "hello"); err != nil {
+		t.Fatalf("SendText failed: %v", err)
+	}
+	if _, err := session.Receive(); err == nil {
+		t.Fatalf("Receive() after the connection dropped succeeded, want error")
+	}
+	if session.IsConnected() {
+		t.Errorf("IsConnected() = true after the connection dropped, want false")
+	}
+	done := session.Done()
+
+	if err := session.Reconnect(context.Background(), nil); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+	wantSetup := `{"setup":{"model":"models/test-model","sessionResumption":{"handle":"handle-1"}}}`
+	if diff := cmp.Diff(wantSetup, <-setups); diff != "" {
+		t.Errorf("setup message mismatch (-want +got):\n%s", diff)
+	}
+	if !session.IsConnected() {
+		t.Errorf("IsConnected() = false after Reconnect, want true")
+	}
+	select {
+	case <-done:
+	default:
+		t.Errorf("Done() of the dropped connection not closed")
+	}
+	select {
+	case <-session.Done():
+		t.Errorf("Done() closed after Reconnect, want open")
+	default:
+	}
+	message, err := session.Receive()
+	if err != nil {
+		t.Fatalf("Receive after Reconnect failed: %v", err)
+	}
+	if message.ServerContent == nil || !message.ServerContent.TurnComplete {
+		t.Errorf("Receive() = %+v, want a complete turn", message)
+	}
+
+	session.Close()
+	if session.IsConnected() {
+		t.Errorf("IsConnected() = true after Close, want false")
+	}
+}
+
+func TestLiveConnectCopiesConfig(t *testing.T) {
+	setups := make(chan string, 2)
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		mt, setup, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		setups <- string(setup)
+		conn.WriteMessage(mt, []byte(`{"setupComplete":{}}`))
+		conn.ReadMessage()
+	}))
+	defer ts.Close()
+
+	config := &LiveConnectConfig{
+		SystemInstruction: &Content{Parts: []*Part{{Text: "Be brief."}}},
+		Tools: []*Tool{{FunctionDeclarations: []*FunctionDeclaration{{
+			Name:       "get_weather",
+			Parameters: &Schema{Type: TypeObject, Properties: map[string]*Schema{"city": {Type: TypeString}}},
+		}}}},
+	}
+	session := newTestLiveSessionWithConfig(t, ts, config)
+	want := <-setups
+
+	config.SystemInstruction.Parts[0].Text = "Be verbose."
+	config.Tools[0].FunctionDeclarations[0].Name = "get_time"
+	config.Tools[0].FunctionDeclarations[0].Parameters.Properties["city"].Type = TypeInteger
+	config.Tools = append(config.Tools, &Tool{CodeExecution: &ToolCodeExecution{}})
+
+	if err := session.Reconnect(context.Background(), nil); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+	if diff := cmp.Diff(want, <-setups); diff != "" {
+		t.Errorf("setup message after modifying the config mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestClientCloseLiveSessions(t *testing.T) {
+	ts, _ := setupRecordingWebsocketServer(t)
+	defer ts.Close()
+	client, err := NewClient(context.Background(), &ClientConfig{
+		Backend:     BackendGeminiAPI,
+		APIKey:      "test-api-key",
+		HTTPOptions: HTTPOptions{BaseURL: strings.Replace(ts.URL, "http", "ws", 1)},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	withKeepAlive, err := client.Live.Connect(context.Background(), "test-model", &LiveConnectConfig{KeepAliveInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	withoutKeepAlive, err := client.Live.Connect(context.Background(), "test-model", nil)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	// Sessions closed before the client are skipped by Close.
+	closed, err := client.Live.Connect(context.Background(), "test-model", nil)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	closed.Close()
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close() failed: %v", err)
+	}
+	for _, s := range []*Session{withKeepAlive, withoutKeepAlive} {
+		select {
+		case <-s.Done():
+		case <-time.After(time.Second):
+			t.Errorf("session still connected after Client.Close")
+		}
+	}
+	if _, err := client.Live.Connect(context.Background(), "test-model", nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Connect() after Close error = %v, want %v", err, ErrClientClosed)
+	}
+	if err := withoutKeepAlive.Reconnect(context.Background(), nil); !errors.Is(err, ErrClientClosed) {
+		t.Errorf("Reconnect() after Close error = %v, want %v", err, ErrClientClosed)
+	}
+}
+
+func TestLiveToolResponseRoundTrip(t *testing.T) {
+	type converter = func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	tests := []struct {
+		desc        string
+		receivedID  bool
+		receiveWith converter
+		sendBackend Backend
+		sendWith    converter
+		want        []map[string]any
+	}{
+		{
+			desc:        "Gemini API",
+			receivedID:  true,
+			receiveWith: liveServerMessageFromMldev,
+			sendBackend: BackendGeminiAPI,
+			sendWith:    liveSendParametersToMldev,
+			want:        []map[string]any{{"id": "call-1", "name": "get_weather", "response": map[string]any{"temperature": 21.5}}},
+		},
+		{
+			desc:        "Vertex AI",
+			receiveWith: liveServerMessageFromVertex,
+			sendBackend: BackendVertexAI,
+			sendWith:    liveSendParametersToVertex,
+			want:        []map[string]any{{"name": "get_weather", "response": map[string]any{"temperature": 21.5}}},
+		},
+		{
+			desc:        "Gemini API call answered on Vertex AI",
+			receivedID:  true,
+			receiveWith: liveServerMessageFromMldev,
+			sendBackend: BackendVertexAI,
+			sendWith:    liveSendParametersToVertex,
+			want:        []map[string]any{{"name": "get_weather", "response": map[string]any{"temperature": 21.5}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			call := map[string]any{"name": "get_weather", "args": map[string]any{"city": "Paris"}}
+			if tt.receivedID {
+				call["id"] = "call-1"
+			}
+			serverMessage := map[string]any{"toolCall": map[string]any{"functionCalls": []any{call}}}
+			responseMap, err := tt.receiveWith(nil, serverMessage, nil)
+			if err != nil {
+				t.Fatalf("receive converter failed: %v", err)
+			}
+			received := new(LiveServerMessage)
+			if err := mapToStruct(responseMap, received); err != nil {
+				t.Fatalf("mapToStruct failed: %v", err)
+			}
+
+			var responses []*FunctionResponse
+			for _, fc := range received.ToolCall.FunctionCalls {
+				responses = append(responses, &FunctionResponse{ID: fc.ID, Name: fc.Name, Response: map[string]any{"temperature": 21.5}})
+			}
+			parameterMap := make(map[string]any)
+			deepMarshal(map[string]any{"input": &LiveClientMessage{ToolResponse: &LiveClientToolResponse{FunctionResponses: responses}}}, &parameterMap)
+			ac := &apiClient{clientConfig: &ClientConfig{Backend: tt.sendBackend}}
+			body, err := tt.sendWith(ac, parameterMap, nil)
+			if err != nil {
+				t.Fatalf("send converter failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, getValueByPath(body, []string{"toolResponse", "functionResponses"})); diff != "" {
+				t.Errorf("function responses mismatch (-want +got):\n%s", diff)
+			}
+			if tt.receivedID && responses[0].ID != "call-1" {
+				t.Errorf("FunctionResponse.ID = %q, want it left unchanged", responses[0].ID)
+			}
+		})
+	}
+}
+
+func TestLiveConnectSubprotocols(t *testing.T) {
+	offered := make(chan []string, 1)
+	upgrader := websocket.Upgrader{Subprotocols: []string{"genai.v2", "genai.v1"}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offered <- websocket.Subprotocols(r)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		mt, _, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.WriteMessage(mt, []byte(`{"setupComplete":{}}`))
+		conn.ReadMessage()
+	}))
+	defer ts.Close()
+
+	t.Run("negotiated", func(t *testing.T) {
+		session := newTestLiveSessionWithConfig(t, ts, &LiveConnectConfig{Subprotocols: []string{"genai.v1", "genai.v0"}})
+		if diff := cmp.Diff([]string{"genai.v1", "genai.v0"}, <-offered); diff != "" {
+			t.Errorf("offered subprotocols mismatch (-want +got):\n%s", diff)
+		}
+		if got := session.Subprotocol(); got != "genai.v1" {
+			t.Errorf("Subprotocol() = %q, want %q", got, "genai.v1")
+		}
+	})
+
+	t.Run("none offered", func(t *testing.T) {
+		session := newTestLiveSession(t, ts)
+		if got := <-offered; len(got) != 0 {
+			t.Errorf("offered subprotocols = %v, want none", got)
+		}
+		if got := session.Subprotocol(); got != "" {
+			t.Errorf("Subprotocol() = %q, want empty", got)
+		}
+	})
+}
+
+func TestSessionSetContextWindow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sends turns in order", func(t *testing.T) {
+		ts, messages := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		contents := []*Content{
+			{Role: RoleUser, Parts: []*Part{{Text: "hello"}}},
+			{Role: RoleModel, Parts: []*Part{{Text: "hi there"}}},
+			{Parts: []*Part{{Text: "how are you?"}}},
+		}
+		if err := session.SetContextWindow(ctx, contents); err != nil {
+			t.Fatalf("SetContextWindow failed: %v", err)
+		}
+
+		want := []string{
+			`{"clientContent":{"turns":[{"parts":[{"text":"hello"}],"role":"user"}]}}`,
+			`{"clientContent":{"turns":[{"parts":[{"text":"hi there"}],"role":"model"}]}}`,
+			`{"clientContent":{"turnComplete":true,"turns":[{"parts":[{"text":"how are you?"}]}]}}`,
+		}
+		for i, w := range want {
+			if diff := cmp.Diff(w, <-messages); diff != "" {
+				t.Errorf("message %d mismatch (-want +got):\n%s", i, diff)
+			}
+		}
+	})
+
+	t.Run("invalid contents", func(t *testing.T) {
+		ts, _ := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		tests := []struct {
+			desc     string
+			contents []*Content
+		}{
+			{desc: "empty", contents: nil},
+			{desc: "nil content", contents: []*Content{nil}},
+			{desc: "unknown role", contents: []*Content{{Role: "system", Parts: []*Part{{Text: "a"}}}}},
+			{desc: "roles not alternating", contents: []*Content{
+				{Role: RoleUser, Parts: []*Part{{Text: "a"}}},
+				{Role: RoleUser, Parts: []*Part{{Text: "b"}}},
+			}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				if err := session.SetContextWindow(ctx, tt.contents); err == nil {
+					t.Errorf("SetContextWindow() succeeded, want error")
+				}
+			})
+		}
+	})
+
+	t.Run("InjectSystemInstruction", func(t *testing.T) {
+		ts, messages := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		if err := session.InjectSystemInstruction(ctx, "answer briefly"); err != nil {
+			t.Fatalf("InjectSystemInstruction failed: %v", err)
+		}
+		want := `{"clientContent":{"turns":[{"parts":[{"text":"answer briefly"}],"role":"user"}]}}`
+		if diff := cmp.Diff(want, <-messages); diff != "" {
+			t.Errorf("message mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestSessionSendHelpers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SendText", func(t *testing.T) {
+		ts, messages := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		if err := session.SendText(ctx, "hello"); err != nil {
+			t.Fatalf("SendText failed: %v", err)
+		}
+		want := `{"clientContent":{"turnComplete":true,"turns":[{"parts":[{"text":"hello"}],"role":"user"}]}}`
+		if diff := cmp.Diff(want, <-messages); diff != "" {
+			t.Errorf("message mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("SendText canceled context", func(t *testing.T) {
+		ts, _ := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		if err := session.SendText(ctx, "hello"); !errors.Is(err, context.Canceled) {
+			t.Errorf("SendText() error = %v, want %v", err, context.Canceled)
+		}
+	})
+
+	t.Run("SendAudio", func(t *testing.T) {
+		ts, messages := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		if err := session.SendAudio([]byte{0x00, 0x01, 0xfe, 0xff}, "audio/pcm;rate=16000"); err != nil {
+			t.Fatalf("SendAudio failed: %v", err)
+		}
+		want := `{"realtimeInput":{"mediaChunks":[{"data":"AAH+/w==","mimeType":"audio/pcm;rate=16000"}]}}`
+		if diff := cmp.Diff(want, <-messages); diff != "" {
+			t.Errorf("message mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("SendFunctionResponses", func(t *testing.T) {
+		ts, messages := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		calls := []*FunctionCall{
+			{ID: "call-1", Name: "get_weather", Args: map[string]any{"city": "Paris"}},
+			{ID: "call-2", Name: "get_time"},
+		}
+		// Each handler waits for the other one, so that the test only passes if they
+		// run concurrently.
+		var started sync.WaitGroup
+		started.Add(len(calls))
+		handler := func(ctx context.Context, fc *FunctionCall) (map[string]any, error) {
+			started.Done()
+			started.Wait()
+			if fc.Name == "get_time" {
+				return nil, errors.New("clock unavailable")
+			}
+			return map[string]any{"temperature": 21.5, "city": fc.Args["city"]}, nil
+		}
+		if err := session.SendFunctionResponses(ctx, calls, handler); err != nil {
+			t.Fatalf("SendFunctionResponses failed: %v", err)
+		}
+		want := `{"toolResponse":{"functionResponses":[` +
+			`{"id":"call-1","name":"get_weather","response":{"city":"Paris","temperature":21.5}},` +
+			`{"id":"call-2","name":"get_time","response":{"error":"clock unavailable"}}]}}`
+		if diff := cmp.Diff(want, <-messages); diff != "" {
+			t.Errorf("message mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("SendFunctionResponses canceled context", func(t *testing.T) {
+		ts, messages := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		ctx, cancel := context.WithCancel(ctx)
+		handler := func(ctx context.Context, fc *FunctionCall) (map[string]any, error) {
+			cancel()
+			return nil, ctx.Err()
+		}
+		err := session.SendFunctionResponses(ctx, []*FunctionCall{{ID: "call-1", Name: "get_weather"}}, handler)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("SendFunctionResponses() error = %v, want %v", err, context.Canceled)
+		}
+		if err := session.SendFunctionResponses(context.Background(), nil, handler); err == nil {
+			t.Error("SendFunctionResponses() with no calls succeeded, want error")
+		}
+		select {
+		case m := <-messages:
+			t.Errorf("got message %s, want none", m)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+func TestSessionReceiveStream(t *testing.T) {
+	ctx := context.Background()
+	setupRequest := `{"setup":{"model":"models/test-model"}}`
+	setupResponse := `{"setupComplete":{}}`
+
+	t.Run("stops at turn complete", func(t *testing.T) {
+		ts := setupTestWebsocketServer(t,
+			[]string{
+				setupRequest,
+				`{"clientContent":{"turnComplete":true,"turns":[{"parts":[{"text":"hello"}],"role":"user"}]}}`,
+				`{"clientContent":{"turns":[{"parts":[{"text":"more"}],"role":"user"}]}}`,
+			},
+			[]string{
+				setupResponse,
+				`{"serverContent":{"modelTurn":{"parts":[{"text":"hi"}],"role":"model"}}}`,
+				`{"serverContent":{"modelTurn":{"parts":[{"text":" there"}],"role":"model"},"turnComplete":true}}`,
+			})
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		if err := session.SendText(ctx, "hello"); err != nil {
+			t.Fatalf("SendText failed: %v", err)
+		}
+		var got []string
+		for message, err := range session.ReceiveStream(ctx) {
+			if err != nil {
+				t.Fatalf("ReceiveStream failed: %v", err)
+			}
+			got = append(got, message.ServerContent.ModelTurn.Parts[0].Text)
+			if len(got) == 1 {
+				// The test server answers one message per request.
+				if err := session.Send(&LiveClientMessage{ClientContent: &LiveClientContent{
+					Turns: []*Content{{Role: RoleUser, Parts: []*Part{{Text: "more"}}}},
+				}}); err != nil {
+					t.Fatalf("Send failed: %v", err)
+				}
+			}
+		}
+		if diff := cmp.Diff([]string{"hi", " there"}, got); diff != "" {
+			t.Errorf("ReceiveStream() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("connection closed", func(t *testing.T) {
+		var upgrader = websocket.Upgrader{}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, _ := upgrader.Upgrade(w, r, nil)
+			defer conn.Close()
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(setupResponse))
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		}))
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		var gotErr error
+		for _, err := range session.ReceiveStream(ctx) {
+			gotErr = err
+		}
+		if !errors.Is(gotErr, io.EOF) {
+			t.Errorf("ReceiveStream() error = %v, want %v", gotErr, io.EOF)
+		}
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		ts := setupTestWebsocketServer(t, []string{setupRequest}, []string{setupResponse})
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		var gotErr error
+		for _, err := range session.ReceiveStream(ctx) {
+			gotErr = err
+		}
+		if !errors.Is(gotErr, context.DeadlineExceeded) {
+			t.Errorf("ReceiveStream() error = %v, want %v", gotErr, context.DeadlineExceeded)
+		}
+	})
+}
+
+func TestSessionKeepAlive(t *testing.T) {
+	config := &LiveConnectConfig{
+		KeepAliveInterval: 10 * time.Millisecond,
+		KeepAliveTimeout:  20 * time.Millisecond,
+	}
+	// receiveAll reads from the session until it fails, so that pongs are processed.
+	receiveAll := func(session *Session) {
+		go func() {
+			for {
+				if _, err := session.Receive(); err != nil {
+					return
+				}
+			}
+		}()
+	}
+
+	t.Run("pong received", func(t *testing.T) {
+		ts, _ := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSessionWithConfig(t, ts, config)
+		receiveAll(session)
+
+		select {
+		case <-session.Done():
+			t.Fatalf("session closed, want it to stay open while pongs are received")
+		case <-time.After(200 * time.Millisecond):
+		}
+	})
+
+	t.Run("pong dropped", func(t *testing.T) {
+		var upgrader = websocket.Upgrader{}
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, _ := upgrader.Upgrade(w, r, nil)
+			defer conn.Close()
+			// Swallow pings instead of answering them with pongs.
+			conn.SetPingHandler(func(string) error { return nil })
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+			conn.WriteMessage(websocket.TextMessage, []byte(`{"setupComplete":{}}`))
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}))
+		defer ts.Close()
+		session := newTestLiveSessionWithConfig(t, ts, config)
+		receiveAll(session)
+
+		select {
+		case <-session.Done():
+		case <-time.After(5 * time.Second):
+			t.Fatalf("session still open, want it closed after the keepalive timeout")
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		ts, _ := setupRecordingWebsocketServer(t)
+		defer ts.Close()
+		session := newTestLiveSession(t, ts)
+
+		session.Close()
+		session.Close()
+		select {
+		case <-session.Done():
+		default:
+			t.Errorf("Done() not closed after Close()")
+		}
+	})
+}
@@ -12,6 +12,9 @@ const (
 	apiMode     = "api"
 	replayMode  = "replay"
 	requestMode = "request"
+	// recordMode runs the test table against the real API and rewrites the replay
+	// files with the interactions.
+	recordMode = "record"
 )
 
 // TODO(b/382773687): Enable the TestModelsGenerateContentStream tests.
@@ -61,6 +64,7 @@ var (
 			"TestModelsGenerateContentAudio/",
 		},
 		replayMode: []string{},
+		recordMode: []string{},
 		requestMode: []string{
 			"TestTable/",
 			"TestModelsGenerateContentStream/",
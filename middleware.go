@@ -0,0 +1,113 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Middleware observes or modifies the HTTP requests sent to the API and the
+// responses received. Middlewares are set with [ClientConfig.Middlewares] and run
+// in order for every attempt of a request.
+type Middleware interface {
+	// ProcessRequest is called before the request is sent. Returning an error aborts
+	// the request.
+	ProcessRequest(req *http.Request) error
+	// ProcessResponse is called when the response headers are received. The body must
+	// not be consumed. Returning an error aborts the request.
+	ProcessResponse(resp *http.Response) error
+}
+
+func processRequest(middlewares []Middleware, req *http.Request) error {
+	for _, m := range middlewares {
+		if err := m.ProcessRequest(req); err != nil {
+			return fmt.Errorf("processRequest: middleware rejected the request: %w", err)
+		}
+	}
+	return nil
+}
+
+func processResponse(middlewares []Middleware, resp *http.Response) error {
+	for _, m := range middlewares {
+		if err := m.ProcessResponse(resp); err != nil {
+			return fmt.Errorf("processResponse: middleware rejected the response: %w", err)
+		}
+	}
+	return nil
+}
+
+type loggingMiddleware struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// LoggingMiddleware returns a middleware that writes the method and URL of every
+// request, and the status of every response, to w.
+func LoggingMiddleware(w io.Writer) Middleware {
+	return &loggingMiddleware{w: w}
+}
+
+func (m *loggingMiddleware) ProcessRequest(req *http.Request) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := fmt.Fprintf(m.w, "genai: request %s %s\n", req.Method, req.URL)
+	return err
+}
+
+func (m *loggingMiddleware) ProcessResponse(resp *http.Response) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, err := fmt.Fprintf(m.w, "genai: response %s %s: %s\n", resp.Request.Method, resp.Request.URL, resp.Status)
+	return err
+}
+
+// MetricsRecorder records the latency of the requests sent to the API, for example
+// into a histogram of the application's metrics system.
+type MetricsRecorder interface {
+	// RecordLatency records the time between sending the request and receiving the
+	// response headers.
+	RecordLatency(method, path string, statusCode int, latency time.Duration)
+}
+
+type requestStartKey struct{}
+
+type metricsMiddleware struct {
+	recorder MetricsRecorder
+}
+
+// MetricsMiddleware returns a middleware that records the latency of every request
+// with recorder.
+func MetricsMiddleware(recorder MetricsRecorder) Middleware {
+	return &metricsMiddleware{recorder: recorder}
+}
+
+func (m *metricsMiddleware) ProcessRequest(req *http.Request) error {
+	*req = *req.WithContext(context.WithValue(req.Context(), requestStartKey{}, time.Now()))
+	return nil
+}
+
+func (m *metricsMiddleware) ProcessResponse(resp *http.Response) error {
+	start, ok := resp.Request.Context().Value(requestStartKey{}).(time.Time)
+	if !ok {
+		return nil
+	}
+	m.recorder.RecordLatency(resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, time.Since(start))
+	return nil
+}
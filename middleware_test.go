@@ -0,0 +1,171 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type recordingMiddleware struct {
+	name        string
+	calls       *[]string
+	requestErr  error
+	responseErr error
+}
+
+func (m recordingMiddleware) ProcessRequest(req *http.Request) error {
+	*m.calls = append(*m.calls, m.name+" request")
+	req.Header.Set("X-Middleware", m.name)
+	return m.requestErr
+}
+
+func (m recordingMiddleware) ProcessResponse(resp *http.Response) error {
+	*m.calls = append(*m.calls, m.name+" response")
+	return m.responseErr
+}
+
+type latencyRecord struct {
+	method     string
+	path       string
+	statusCode int
+}
+
+type fakeMetricsRecorder struct {
+	records []latencyRecord
+}
+
+func (r *fakeMetricsRecorder) RecordLatency(method, path string, statusCode int, latency time.Duration) {
+	if latency <= 0 {
+		panic(fmt.Sprintf("latency = %v, want > 0", latency))
+	}
+	r.records = append(r.records, latencyRecord{method: method, path: path, statusCode: statusCode})
+}
+
+func newMiddlewareTestClient(ts *httptest.Server, middlewares ...Middleware) *apiClient {
+	return &apiClient{
+		clientConfig: &ClientConfig{
+			HTTPOptions: HTTPOptions{BaseURL: ts.URL, APIVersion: "v1"},
+			HTTPClient:  ts.Client(),
+			Middlewares: middlewares,
+		},
+	}
+}
+
+func TestMiddlewares(t *testing.T) {
+	ctx := context.Background()
+	var serverCalls int
+	var gotHeader string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serverCalls++
+		gotHeader = r.Header.Get("X-Middleware")
+		fmt.Fprint(w, `{"response": "ok"}`)
+	}))
+	defer ts.Close()
+
+	t.Run("run in order", func(t *testing.T) {
+		var calls []string
+		ac := newMiddlewareTestClient(ts, recordingMiddleware{name: "first", calls: &calls}, recordingMiddleware{name: "second", calls: &calls})
+		if _, err := sendRequest(ctx, ac, "foo", http.MethodPost, map[string]any{}, nil); err != nil {
+			t.Fatalf("sendRequest() error = %v", err)
+		}
+		want := []string{"first request", "second request", "first response", "second response"}
+		if diff := cmp.Diff(want, calls); diff != "" {
+			t.Errorf("middleware calls mismatch (-want +got):\n%s", diff)
+		}
+		if gotHeader != "second" {
+			t.Errorf("request header = %q, want %q", gotHeader, "second")
+		}
+	})
+
+	t.Run("request error aborts the request", func(t *testing.T) {
+		serverCalls = 0
+		var calls []string
+		wantErr := errors.New("request rejected")
+		ac := newMiddlewareTestClient(ts, recordingMiddleware{name: "first", calls: &calls, requestErr: wantErr}, recordingMiddleware{name: "second", calls: &calls})
+		_, err := sendRequest(ctx, ac, "foo", http.MethodPost, map[string]any{}, nil)
+		if !errors.Is(err, wantErr) {
+			t.Errorf("sendRequest() error = %v, want %v", err, wantErr)
+		}
+		if serverCalls != 0 {
+			t.Errorf("got %d server calls, want 0", serverCalls)
+		}
+		if diff := cmp.Diff([]string{"first request"}, calls); diff != "" {
+			t.Errorf("middleware calls mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("response error aborts the request", func(t *testing.T) {
+		var calls []string
+		wantErr := errors.New("response rejected")
+		ac := newMiddlewareTestClient(ts, recordingMiddleware{name: "first", calls: &calls, responseErr: wantErr})
+		if _, err := sendRequest(ctx, ac, "foo", http.MethodPost, map[string]any{}, nil); !errors.Is(err, wantErr) {
+			t.Errorf("sendRequest() error = %v, want %v", err, wantErr)
+		}
+	})
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"response": "ok"}`)
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	ac := newMiddlewareTestClient(ts, LoggingMiddleware(&buf))
+	if _, err := sendRequest(context.Background(), ac, "foo", http.MethodPost, map[string]any{}, nil); err != nil {
+		t.Fatalf("sendRequest() error = %v", err)
+	}
+	url := ts.URL + "/v1/foo"
+	want := fmt.Sprintf("genai: request POST %s\ngenai: response POST %s: 200 OK\n", url, url)
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Errorf("log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestMetricsMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		if strings.HasSuffix(r.URL.Path, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"code": 404}}`)
+			return
+		}
+		fmt.Fprint(w, `{"response": "ok"}`)
+	}))
+	defer ts.Close()
+
+	recorder := &fakeMetricsRecorder{}
+	ac := newMiddlewareTestClient(ts, MetricsMiddleware(recorder))
+	sendRequest(context.Background(), ac, "foo", http.MethodPost, map[string]any{}, nil)
+	sendRequest(context.Background(), ac, "missing", http.MethodGet, map[string]any{}, nil)
+
+	want := []latencyRecord{
+		{method: http.MethodPost, path: "/v1/foo", statusCode: http.StatusOK},
+		{method: http.MethodGet, path: "/v1/missing", statusCode: http.StatusNotFound},
+	}
+	if diff := cmp.Diff(want, recorder.records, cmp.AllowUnexported(latencyRecord{})); diff != "" {
+		t.Errorf("recorded latencies mismatch (-want +got):\n%s", diff)
+	}
+}
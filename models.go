@@ -29,6 +29,10 @@ func partToMldev(ac *apiClient, fromObject map[string]any, parentObject map[stri
 		return nil, fmt.Errorf("video_metadata parameter is not supported in Gemini API")
 	}
 
+	if getValueByPath(fromObject, []string{"mediaResolution"}) != nil {
+		return nil, fmt.Errorf("media_resolution parameter is not supported in Gemini API")
+	}
+
 	fromThought := getValueByPath(fromObject, []string{"thought"})
 	if fromThought != nil {
 		setValueByPath(toObject, []string{"thought"}, fromThought)
@@ -120,6 +124,11 @@ func partToVertex(ac *apiClient, fromObject map[string]any, parentObject map[str
 		setValueByPath(toObject, []string{"text"}, fromText)
 	}
 
+	fromMediaResolution := getValueByPath(fromObject, []string{"mediaResolution"})
+	if fromMediaResolution != nil && (fromFileData != nil || fromInlineData != nil) {
+		setValueByPath(toObject, []string{"mediaResolution"}, fromMediaResolution)
+	}
+
 	return toObject, nil
 }
 
@@ -491,6 +500,10 @@ func googleSearchToVertex(ac *apiClient, fromObject map[string]any, parentObject
 func dynamicRetrievalConfigToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
+	if _, err := tDynamicRetrievalConfig(ac, fromObject); err != nil {
+		return nil, err
+	}
+
 	fromMode := getValueByPath(fromObject, []string{"mode"})
 	if fromMode != nil {
 		setValueByPath(toObject, []string{"mode"}, fromMode)
@@ -507,6 +520,10 @@ func dynamicRetrievalConfigToMldev(ac *apiClient, fromObject map[string]any, par
 func dynamicRetrievalConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
+	if _, err := tDynamicRetrievalConfig(ac, fromObject); err != nil {
+		return nil, err
+	}
+
 	fromMode := getValueByPath(fromObject, []string{"mode"})
 	if fromMode != nil {
 		setValueByPath(toObject, []string{"mode"}, fromMode)
@@ -530,7 +547,10 @@ func googleSearchRetrievalToMldev(ac *apiClient, fromObject map[string]any, pare
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"dynamicRetrievalConfig"}, fromDynamicRetrievalConfig)
+		// An empty config is not sent, since the API may reject an empty object.
+		if len(fromDynamicRetrievalConfig.(map[string]any)) > 0 {
+			setValueByPath(toObject, []string{"dynamicRetrievalConfig"}, fromDynamicRetrievalConfig)
+		}
 	}
 
 	return toObject, nil
@@ -546,7 +566,10 @@ func googleSearchRetrievalToVertex(ac *apiClient, fromObject map[string]any, par
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"dynamicRetrievalConfig"}, fromDynamicRetrievalConfig)
+		// An empty config is not sent, since the API may reject an empty object.
+		if len(fromDynamicRetrievalConfig.(map[string]any)) > 0 {
+			setValueByPath(toObject, []string{"dynamicRetrievalConfig"}, fromDynamicRetrievalConfig)
+		}
 	}
 
 	return toObject, nil
@@ -774,6 +797,47 @@ func speechConfigToMldev(ac *apiClient, fromObject map[string]any, parentObject
 		setValueByPath(toObject, []string{"voiceConfig"}, fromVoiceConfig)
 	}
 
+	if getValueByPath(fromObject, []string{"multiSpeakerVoiceConfig"}) != nil {
+		return nil, fmt.Errorf("multiSpeakerVoiceConfig parameter is not supported in Gemini API")
+	}
+
+	return toObject, nil
+}
+
+func speakerVoiceConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromSpeaker := getValueByPath(fromObject, []string{"speaker"})
+	if fromSpeaker != nil {
+		setValueByPath(toObject, []string{"speaker"}, fromSpeaker)
+	}
+
+	fromVoiceConfig := getValueByPath(fromObject, []string{"voiceConfig"})
+	if fromVoiceConfig != nil {
+		fromVoiceConfig, err = voiceConfigToVertex(ac, fromVoiceConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"voiceConfig"}, fromVoiceConfig)
+	}
+
+	return toObject, nil
+}
+
+func multiSpeakerVoiceConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromSpeakerVoiceConfigs := getValueByPath(fromObject, []string{"speakerVoiceConfigs"})
+	if fromSpeakerVoiceConfigs != nil {
+		fromSpeakerVoiceConfigs, err = applyConverterToSlice(ac, fromSpeakerVoiceConfigs.([]any), speakerVoiceConfigToVertex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"speakerVoiceConfigs"}, fromSpeakerVoiceConfigs)
+	}
+
 	return toObject, nil
 }
 
@@ -790,6 +854,16 @@ func speechConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject
 		setValueByPath(toObject, []string{"voiceConfig"}, fromVoiceConfig)
 	}
 
+	fromMultiSpeakerVoiceConfig := getValueByPath(fromObject, []string{"multiSpeakerVoiceConfig"})
+	if fromMultiSpeakerVoiceConfig != nil {
+		fromMultiSpeakerVoiceConfig, err = multiSpeakerVoiceConfigToVertex(ac, fromMultiSpeakerVoiceConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"multiSpeakerVoiceConfig"}, fromMultiSpeakerVoiceConfig)
+	}
+
 	return toObject, nil
 }
 
@@ -801,6 +875,11 @@ func thinkingConfigToMldev(ac *apiClient, fromObject map[string]any, parentObjec
 		setValueByPath(toObject, []string{"includeThoughts"}, fromIncludeThoughts)
 	}
 
+	fromThinkingBudget := getValueByPath(fromObject, []string{"thinkingBudget"})
+	if fromThinkingBudget != nil {
+		setValueByPath(toObject, []string{"thinkingBudget"}, fromThinkingBudget)
+	}
+
 	return toObject, nil
 }
 
@@ -812,6 +891,11 @@ func thinkingConfigToVertex(ac *apiClient, fromObject map[string]any, parentObje
 		setValueByPath(toObject, []string{"includeThoughts"}, fromIncludeThoughts)
 	}
 
+	fromThinkingBudget := getValueByPath(fromObject, []string{"thinkingBudget"})
+	if fromThinkingBudget != nil {
+		setValueByPath(toObject, []string{"thinkingBudget"}, fromThinkingBudget)
+	}
+
 	return toObject, nil
 }
 
@@ -819,6 +903,16 @@ func generateContentConfigToMldev(ac *apiClient, fromObject map[string]any, pare
 	toObject = make(map[string]any)
 
 	fromSystemInstruction := getValueByPath(fromObject, []string{"systemInstruction"})
+	fromSystemInstructionText := getValueByPath(fromObject, []string{"systemInstructionText"})
+	if fromSystemInstructionText != nil {
+		if fromSystemInstruction != nil {
+			return nil, fmt.Errorf("system_instruction and system_instruction_text parameters cannot both be set")
+		}
+		fromSystemInstruction, err = tSystemInstructionText(ac, fromSystemInstructionText)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if fromSystemInstruction != nil {
 		fromSystemInstruction, err = tContent(ac, fromSystemInstruction)
 		if err != nil {
@@ -850,6 +944,11 @@ func generateContentConfigToMldev(ac *apiClient, fromObject map[string]any, pare
 
 	fromCandidateCount := getValueByPath(fromObject, []string{"candidateCount"})
 	if fromCandidateCount != nil {
+		fromCandidateCount, err = tCandidateCount(ac, fromCandidateCount)
+		if err != nil {
+			return nil, err
+		}
+
 		setValueByPath(toObject, []string{"candidateCount"}, fromCandidateCount)
 	}
 
@@ -860,6 +959,11 @@ func generateContentConfigToMldev(ac *apiClient, fromObject map[string]any, pare
 
 	fromStopSequences := getValueByPath(fromObject, []string{"stopSequences"})
 	if fromStopSequences != nil {
+		fromStopSequences, err = tStopSequences(ac, fromStopSequences)
+		if err != nil {
+			return nil, err
+		}
+
 		setValueByPath(toObject, []string{"stopSequences"}, fromStopSequences)
 	}
 
@@ -890,6 +994,13 @@ func generateContentConfigToMldev(ac *apiClient, fromObject map[string]any, pare
 
 	fromResponseMimeType := getValueByPath(fromObject, []string{"responseMimeType"})
 	if fromResponseMimeType != nil {
+		if getValueByPath(fromObject, []string{"allowCustomMimeType"}) == nil {
+			fromResponseMimeType, err = tResponseMIMEType(ac, fromResponseMimeType)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		setValueByPath(toObject, []string{"responseMimeType"}, fromResponseMimeType)
 	}
 
@@ -909,7 +1020,9 @@ func generateContentConfigToMldev(ac *apiClient, fromObject map[string]any, pare
 	}
 
 	if getValueByPath(fromObject, []string{"routingConfig"}) != nil {
-		return nil, fmt.Errorf("routing_config parameter is not supported in Gemini API")
+		// The Gemini API has no model router: the field is dropped rather than failing
+		// requests that are also sent to Vertex AI.
+		ac.logger().Warn("genai: routing_config parameter is not supported in Gemini API and is ignored")
 	}
 
 	fromSafetySettings := getValueByPath(fromObject, []string{"safetySettings"})
@@ -940,6 +1053,12 @@ func generateContentConfigToMldev(ac *apiClient, fromObject map[string]any, pare
 	}
 
 	fromToolConfig := getValueByPath(fromObject, []string{"toolConfig"})
+	if fromToolConfig == nil && getValueByPath(fromObject, []string{"disableAutoToolConfig"}) == nil {
+		fromToolConfig, err = tAutoToolConfig(ac, getValueByPath(fromObject, []string{"tools"}))
+		if err != nil {
+			return nil, err
+		}
+	}
 	if fromToolConfig != nil {
 		fromToolConfig, err = toolConfigToMldev(ac, fromToolConfig.(map[string]any), toObject)
 		if err != nil {
@@ -987,8 +1106,9 @@ func generateContentConfigToMldev(ac *apiClient, fromObject map[string]any, pare
 		setValueByPath(toObject, []string{"speechConfig"}, fromSpeechConfig)
 	}
 
-	if getValueByPath(fromObject, []string{"audioTimestamp"}) != nil {
-		return nil, fmt.Errorf("audio_timestamp parameter is not supported in Gemini API")
+	fromAudioTimestamp := getValueByPath(fromObject, []string{"audioTimestamp"})
+	if fromAudioTimestamp != nil {
+		setValueByPath(toObject, []string{"audioTimestamp"}, fromAudioTimestamp)
 	}
 
 	fromThinkingConfig := getValueByPath(fromObject, []string{"thinkingConfig"})
@@ -1008,6 +1128,16 @@ func generateContentConfigToVertex(ac *apiClient, fromObject map[string]any, par
 	toObject = make(map[string]any)
 
 	fromSystemInstruction := getValueByPath(fromObject, []string{"systemInstruction"})
+	fromSystemInstructionText := getValueByPath(fromObject, []string{"systemInstructionText"})
+	if fromSystemInstructionText != nil {
+		if fromSystemInstruction != nil {
+			return nil, fmt.Errorf("system_instruction and system_instruction_text parameters cannot both be set")
+		}
+		fromSystemInstruction, err = tSystemInstructionText(ac, fromSystemInstructionText)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if fromSystemInstruction != nil {
 		fromSystemInstruction, err = tContent(ac, fromSystemInstruction)
 		if err != nil {
@@ -1039,6 +1169,11 @@ func generateContentConfigToVertex(ac *apiClient, fromObject map[string]any, par
 
 	fromCandidateCount := getValueByPath(fromObject, []string{"candidateCount"})
 	if fromCandidateCount != nil {
+		fromCandidateCount, err = tCandidateCount(ac, fromCandidateCount)
+		if err != nil {
+			return nil, err
+		}
+
 		setValueByPath(toObject, []string{"candidateCount"}, fromCandidateCount)
 	}
 
@@ -1049,6 +1184,11 @@ func generateContentConfigToVertex(ac *apiClient, fromObject map[string]any, par
 
 	fromStopSequences := getValueByPath(fromObject, []string{"stopSequences"})
 	if fromStopSequences != nil {
+		fromStopSequences, err = tStopSequences(ac, fromStopSequences)
+		if err != nil {
+			return nil, err
+		}
+
 		setValueByPath(toObject, []string{"stopSequences"}, fromStopSequences)
 	}
 
@@ -1079,6 +1219,13 @@ func generateContentConfigToVertex(ac *apiClient, fromObject map[string]any, par
 
 	fromResponseMimeType := getValueByPath(fromObject, []string{"responseMimeType"})
 	if fromResponseMimeType != nil {
+		if getValueByPath(fromObject, []string{"allowCustomMimeType"}) == nil {
+			fromResponseMimeType, err = tResponseMIMEType(ac, fromResponseMimeType)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		setValueByPath(toObject, []string{"responseMimeType"}, fromResponseMimeType)
 	}
 
@@ -1130,6 +1277,12 @@ func generateContentConfigToVertex(ac *apiClient, fromObject map[string]any, par
 	}
 
 	fromToolConfig := getValueByPath(fromObject, []string{"toolConfig"})
+	if fromToolConfig == nil && getValueByPath(fromObject, []string{"disableAutoToolConfig"}) == nil {
+		fromToolConfig, err = tAutoToolConfig(ac, getValueByPath(fromObject, []string{"tools"}))
+		if err != nil {
+			return nil, err
+		}
+	}
 	if fromToolConfig != nil {
 		fromToolConfig, err = toolConfigToVertex(ac, fromToolConfig.(map[string]any), toObject)
 		if err != nil {
@@ -1714,173 +1867,124 @@ func computeTokensParametersToVertex(ac *apiClient, fromObject map[string]any, p
 	return toObject, nil
 }
 
-func partFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func embedContentConfigToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromThought := getValueByPath(fromObject, []string{"thought"})
-	if fromThought != nil {
-		setValueByPath(toObject, []string{"thought"}, fromThought)
-	}
-
-	fromCodeExecutionResult := getValueByPath(fromObject, []string{"codeExecutionResult"})
-	if fromCodeExecutionResult != nil {
-		setValueByPath(toObject, []string{"codeExecutionResult"}, fromCodeExecutionResult)
-	}
-
-	fromExecutableCode := getValueByPath(fromObject, []string{"executableCode"})
-	if fromExecutableCode != nil {
-		setValueByPath(toObject, []string{"executableCode"}, fromExecutableCode)
-	}
-
-	fromFileData := getValueByPath(fromObject, []string{"fileData"})
-	if fromFileData != nil {
-		setValueByPath(toObject, []string{"fileData"}, fromFileData)
-	}
-
-	fromFunctionCall := getValueByPath(fromObject, []string{"functionCall"})
-	if fromFunctionCall != nil {
-		setValueByPath(toObject, []string{"functionCall"}, fromFunctionCall)
-	}
-
-	fromFunctionResponse := getValueByPath(fromObject, []string{"functionResponse"})
-	if fromFunctionResponse != nil {
-		setValueByPath(toObject, []string{"functionResponse"}, fromFunctionResponse)
+	fromTaskType := getValueByPath(fromObject, []string{"taskType"})
+	if fromTaskType != nil {
+		setValueByPath(parentObject, []string{"taskType"}, fromTaskType)
 	}
 
-	fromInlineData := getValueByPath(fromObject, []string{"inlineData"})
-	if fromInlineData != nil {
-		setValueByPath(toObject, []string{"inlineData"}, fromInlineData)
+	fromTitle := getValueByPath(fromObject, []string{"title"})
+	if fromTitle != nil {
+		setValueByPath(parentObject, []string{"title"}, fromTitle)
 	}
 
-	fromText := getValueByPath(fromObject, []string{"text"})
-	if fromText != nil {
-		setValueByPath(toObject, []string{"text"}, fromText)
+	fromOutputDimensionality := getValueByPath(fromObject, []string{"outputDimensionality"})
+	if fromOutputDimensionality != nil {
+		setValueByPath(parentObject, []string{"outputDimensionality"}, fromOutputDimensionality)
 	}
 
 	return toObject, nil
 }
 
-func partFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func embedContentConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromVideoMetadata := getValueByPath(fromObject, []string{"videoMetadata"})
-	if fromVideoMetadata != nil {
-		setValueByPath(toObject, []string{"videoMetadata"}, fromVideoMetadata)
-	}
-
-	fromThought := getValueByPath(fromObject, []string{"thought"})
-	if fromThought != nil {
-		setValueByPath(toObject, []string{"thought"}, fromThought)
-	}
-
-	fromCodeExecutionResult := getValueByPath(fromObject, []string{"codeExecutionResult"})
-	if fromCodeExecutionResult != nil {
-		setValueByPath(toObject, []string{"codeExecutionResult"}, fromCodeExecutionResult)
-	}
-
-	fromExecutableCode := getValueByPath(fromObject, []string{"executableCode"})
-	if fromExecutableCode != nil {
-		setValueByPath(toObject, []string{"executableCode"}, fromExecutableCode)
-	}
-
-	fromFileData := getValueByPath(fromObject, []string{"fileData"})
-	if fromFileData != nil {
-		setValueByPath(toObject, []string{"fileData"}, fromFileData)
-	}
-
-	fromFunctionCall := getValueByPath(fromObject, []string{"functionCall"})
-	if fromFunctionCall != nil {
-		setValueByPath(toObject, []string{"functionCall"}, fromFunctionCall)
-	}
-
-	fromFunctionResponse := getValueByPath(fromObject, []string{"functionResponse"})
-	if fromFunctionResponse != nil {
-		setValueByPath(toObject, []string{"functionResponse"}, fromFunctionResponse)
+	fromTaskType := getValueByPath(fromObject, []string{"taskType"})
+	if fromTaskType != nil {
+		setValueByPath(parentObject, []string{"task_type"}, fromTaskType)
 	}
 
-	fromInlineData := getValueByPath(fromObject, []string{"inlineData"})
-	if fromInlineData != nil {
-		setValueByPath(toObject, []string{"inlineData"}, fromInlineData)
+	fromTitle := getValueByPath(fromObject, []string{"title"})
+	if fromTitle != nil {
+		setValueByPath(parentObject, []string{"title"}, fromTitle)
 	}
 
-	fromText := getValueByPath(fromObject, []string{"text"})
-	if fromText != nil {
-		setValueByPath(toObject, []string{"text"}, fromText)
+	fromOutputDimensionality := getValueByPath(fromObject, []string{"outputDimensionality"})
+	if fromOutputDimensionality != nil {
+		setValueByPath(toObject, []string{"outputDimensionality"}, fromOutputDimensionality)
 	}
 
 	return toObject, nil
 }
 
-func contentFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func embedContentRequestToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromParts := getValueByPath(fromObject, []string{"parts"})
-	if fromParts != nil {
-		fromParts, err = applyConverterToSlice(ac, fromParts.([]any), partFromMldev)
+	fromContent := getValueByPath(fromObject, []string{"content"})
+	if fromContent != nil {
+		fromContent, err = tContent(ac, fromContent)
 		if err != nil {
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"parts"}, fromParts)
+		fromContent, err = contentToMldev(ac, fromContent.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"content"}, fromContent)
 	}
 
-	fromRole := getValueByPath(fromObject, []string{"role"})
-	if fromRole != nil {
-		setValueByPath(toObject, []string{"role"}, fromRole)
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		_, err = embedContentConfigToMldev(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return toObject, nil
 }
 
-func contentFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func embedContentRequestToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromParts := getValueByPath(fromObject, []string{"parts"})
-	if fromParts != nil {
-		fromParts, err = applyConverterToSlice(ac, fromParts.([]any), partFromVertex)
+	fromContent := getValueByPath(fromObject, []string{"content"})
+	if fromContent != nil {
+		fromContent, err = tContentForEmbed(ac, fromContent)
 		if err != nil {
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"parts"}, fromParts)
-	}
-
-	fromRole := getValueByPath(fromObject, []string{"role"})
-	if fromRole != nil {
-		setValueByPath(toObject, []string{"role"}, fromRole)
+		setValueByPath(toObject, []string{"content"}, fromContent)
 	}
 
-	return toObject, nil
-}
-
-func citationMetadataFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
-	toObject = make(map[string]any)
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = embedContentConfigToVertex(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
 
-	fromCitations := getValueByPath(fromObject, []string{"citationSources"})
-	if fromCitations != nil {
-		setValueByPath(toObject, []string{"citations"}, fromCitations)
+		setValueByPath(toObject, []string{"_parameters"}, fromConfig)
 	}
 
 	return toObject, nil
 }
 
-func citationMetadataFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func embedContentParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromCitations := getValueByPath(fromObject, []string{"citations"})
-	if fromCitations != nil {
-		setValueByPath(toObject, []string{"citations"}, fromCitations)
-	}
-
-	return toObject, nil
-}
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
+		if err != nil {
+			return nil, err
+		}
 
-func candidateFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
-	toObject = make(map[string]any)
+		setValueByPath(toObject, []string{"_url", "model"}, fromModel)
+	}
 
 	fromContent := getValueByPath(fromObject, []string{"content"})
 	if fromContent != nil {
-		fromContent, err = contentFromMldev(ac, fromContent.(map[string]any), toObject)
+		fromContent, err = tContent(ac, fromContent)
+		if err != nil {
+			return nil, err
+		}
+
+		fromContent, err = contentToMldev(ac, fromContent.(map[string]any), toObject)
 		if err != nil {
 			return nil, err
 		}
@@ -1888,377 +1992,1566 @@ func candidateFromMldev(ac *apiClient, fromObject map[string]any, parentObject m
 		setValueByPath(toObject, []string{"content"}, fromContent)
 	}
 
-	fromCitationMetadata := getValueByPath(fromObject, []string{"citationMetadata"})
-	if fromCitationMetadata != nil {
-		fromCitationMetadata, err = citationMetadataFromMldev(ac, fromCitationMetadata.(map[string]any), toObject)
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = embedContentConfigToMldev(ac, fromConfig.(map[string]any), toObject)
 		if err != nil {
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"citationMetadata"}, fromCitationMetadata)
+		setValueByPath(toObject, []string{"config"}, fromConfig)
 	}
 
-	fromTokenCount := getValueByPath(fromObject, []string{"tokenCount"})
-	if fromTokenCount != nil {
-		setValueByPath(toObject, []string{"tokenCount"}, fromTokenCount)
-	}
-
-	fromAvgLogprobs := getValueByPath(fromObject, []string{"avgLogprobs"})
-	if fromAvgLogprobs != nil {
-		setValueByPath(toObject, []string{"avgLogprobs"}, fromAvgLogprobs)
-	}
+	return toObject, nil
+}
 
-	fromFinishReason := getValueByPath(fromObject, []string{"finishReason"})
-	if fromFinishReason != nil {
-		setValueByPath(toObject, []string{"finishReason"}, fromFinishReason)
-	}
+func embedContentParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
 
-	fromGroundingMetadata := getValueByPath(fromObject, []string{"groundingMetadata"})
-	if fromGroundingMetadata != nil {
-		setValueByPath(toObject, []string{"groundingMetadata"}, fromGroundingMetadata)
-	}
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
+		if err != nil {
+			return nil, err
+		}
 
-	fromIndex := getValueByPath(fromObject, []string{"index"})
-	if fromIndex != nil {
-		setValueByPath(toObject, []string{"index"}, fromIndex)
+		setValueByPath(toObject, []string{"_url", "model"}, fromModel)
 	}
 
-	fromLogprobsResult := getValueByPath(fromObject, []string{"logprobsResult"})
-	if fromLogprobsResult != nil {
-		setValueByPath(toObject, []string{"logprobsResult"}, fromLogprobsResult)
+	instance, err := embedContentRequestToVertex(ac, fromObject, toObject)
+	if err != nil {
+		return nil, err
 	}
-
-	fromSafetyRatings := getValueByPath(fromObject, []string{"safetyRatings"})
-	if fromSafetyRatings != nil {
-		setValueByPath(toObject, []string{"safetyRatings"}, fromSafetyRatings)
+	instances := []map[string]any{instance}
+	parameters, err := tEmbedParameters(ac, instances)
+	if err != nil {
+		return nil, err
 	}
+	setValueByPath(toObject, []string{"instances"}, instances)
+	setValueByPath(toObject, []string{"parameters"}, parameters)
 
 	return toObject, nil
 }
 
-func candidateFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func batchEmbedContentsParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromContent := getValueByPath(fromObject, []string{"content"})
-	if fromContent != nil {
-		fromContent, err = contentFromVertex(ac, fromContent.(map[string]any), toObject)
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
 		if err != nil {
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"content"}, fromContent)
+		setValueByPath(toObject, []string{"_url", "model"}, fromModel)
 	}
 
-	fromCitationMetadata := getValueByPath(fromObject, []string{"citationMetadata"})
-	if fromCitationMetadata != nil {
-		fromCitationMetadata, err = citationMetadataFromVertex(ac, fromCitationMetadata.(map[string]any), toObject)
+	fromRequests := getValueByPath(fromObject, []string{"requests"})
+	if fromRequests != nil {
+		requests, err := applyConverterToSlice(ac, fromRequests.([]any), embedContentRequestToMldev)
 		if err != nil {
 			return nil, err
 		}
+		// Each request of a batch must name the model it is sent to.
+		for _, request := range requests {
+			setValueByPath(request, []string{"model"}, fromModel)
+		}
 
-		setValueByPath(toObject, []string{"citationMetadata"}, fromCitationMetadata)
+		setValueByPath(toObject, []string{"requests"}, requests)
 	}
 
-	fromFinishMessage := getValueByPath(fromObject, []string{"finishMessage"})
-	if fromFinishMessage != nil {
-		setValueByPath(toObject, []string{"finishMessage"}, fromFinishMessage)
-	}
+	return toObject, nil
+}
 
-	fromAvgLogprobs := getValueByPath(fromObject, []string{"avgLogprobs"})
-	if fromAvgLogprobs != nil {
-		setValueByPath(toObject, []string{"avgLogprobs"}, fromAvgLogprobs)
-	}
+func batchEmbedContentsParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
 
-	fromFinishReason := getValueByPath(fromObject, []string{"finishReason"})
-	if fromFinishReason != nil {
-		setValueByPath(toObject, []string{"finishReason"}, fromFinishReason)
-	}
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
+		if err != nil {
+			return nil, err
+		}
 
-	fromGroundingMetadata := getValueByPath(fromObject, []string{"groundingMetadata"})
-	if fromGroundingMetadata != nil {
-		setValueByPath(toObject, []string{"groundingMetadata"}, fromGroundingMetadata)
+		setValueByPath(toObject, []string{"_url", "model"}, fromModel)
 	}
 
-	fromIndex := getValueByPath(fromObject, []string{"index"})
-	if fromIndex != nil {
-		setValueByPath(toObject, []string{"index"}, fromIndex)
-	}
+	fromRequests := getValueByPath(fromObject, []string{"requests"})
+	if fromRequests != nil {
+		instances, err := applyConverterToSlice(ac, fromRequests.([]any), embedContentRequestToVertex)
+		if err != nil {
+			return nil, err
+		}
 
-	fromLogprobsResult := getValueByPath(fromObject, []string{"logprobsResult"})
-	if fromLogprobsResult != nil {
-		setValueByPath(toObject, []string{"logprobsResult"}, fromLogprobsResult)
-	}
+		parameters, err := tEmbedParameters(ac, instances)
+		if err != nil {
+			return nil, err
+		}
 
-	fromSafetyRatings := getValueByPath(fromObject, []string{"safetyRatings"})
-	if fromSafetyRatings != nil {
-		setValueByPath(toObject, []string{"safetyRatings"}, fromSafetyRatings)
+		setValueByPath(toObject, []string{"instances"}, instances)
+		setValueByPath(toObject, []string{"parameters"}, parameters)
 	}
 
 	return toObject, nil
 }
 
-func generateContentResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func getModelParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromCandidates := getValueByPath(fromObject, []string{"candidates"})
-	if fromCandidates != nil {
-		fromCandidates, err = applyConverterToSlice(ac, fromCandidates.([]any), candidateFromMldev)
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
 		if err != nil {
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"candidates"}, fromCandidates)
+		setValueByPath(toObject, []string{"_url", "name"}, fromModel)
 	}
 
-	fromModelVersion := getValueByPath(fromObject, []string{"modelVersion"})
-	if fromModelVersion != nil {
-		setValueByPath(toObject, []string{"modelVersion"}, fromModelVersion)
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		setValueByPath(toObject, []string{"config"}, fromConfig)
 	}
 
-	fromPromptFeedback := getValueByPath(fromObject, []string{"promptFeedback"})
-	if fromPromptFeedback != nil {
-		setValueByPath(toObject, []string{"promptFeedback"}, fromPromptFeedback)
+	return toObject, nil
+}
+
+func getModelParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"_url", "name"}, fromModel)
 	}
 
-	fromUsageMetadata := getValueByPath(fromObject, []string{"usageMetadata"})
-	if fromUsageMetadata != nil {
-		setValueByPath(toObject, []string{"usageMetadata"}, fromUsageMetadata)
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		setValueByPath(toObject, []string{"config"}, fromConfig)
 	}
 
 	return toObject, nil
 }
 
-func generateContentResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func updateModelConfigToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromCandidates := getValueByPath(fromObject, []string{"candidates"})
-	if fromCandidates != nil {
-		fromCandidates, err = applyConverterToSlice(ac, fromCandidates.([]any), candidateFromVertex)
-		if err != nil {
-			return nil, err
-		}
+	fromDisplayName := getValueByPath(fromObject, []string{"displayName"})
+	if fromDisplayName != nil {
+		setValueByPath(parentObject, []string{"displayName"}, fromDisplayName)
+	}
 
-		setValueByPath(toObject, []string{"candidates"}, fromCandidates)
+	fromDescription := getValueByPath(fromObject, []string{"description"})
+	if fromDescription != nil {
+		setValueByPath(parentObject, []string{"description"}, fromDescription)
 	}
 
-	fromModelVersion := getValueByPath(fromObject, []string{"modelVersion"})
-	if fromModelVersion != nil {
-		setValueByPath(toObject, []string{"modelVersion"}, fromModelVersion)
+	fromUpdateMask := getValueByPath(fromObject, []string{"updateMask"})
+	if fromUpdateMask != nil {
+		setValueByPath(parentObject, []string{"_query", "updateMask"}, fromUpdateMask)
 	}
 
-	fromPromptFeedback := getValueByPath(fromObject, []string{"promptFeedback"})
-	if fromPromptFeedback != nil {
-		setValueByPath(toObject, []string{"promptFeedback"}, fromPromptFeedback)
+	return toObject, nil
+}
+
+func updateModelConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromDisplayName := getValueByPath(fromObject, []string{"displayName"})
+	if fromDisplayName != nil {
+		setValueByPath(parentObject, []string{"displayName"}, fromDisplayName)
 	}
 
-	fromUsageMetadata := getValueByPath(fromObject, []string{"usageMetadata"})
-	if fromUsageMetadata != nil {
-		setValueByPath(toObject, []string{"usageMetadata"}, fromUsageMetadata)
+	fromDescription := getValueByPath(fromObject, []string{"description"})
+	if fromDescription != nil {
+		setValueByPath(parentObject, []string{"description"}, fromDescription)
+	}
+
+	fromUpdateMask := getValueByPath(fromObject, []string{"updateMask"})
+	if fromUpdateMask != nil {
+		setValueByPath(parentObject, []string{"_query", "updateMask"}, fromUpdateMask)
 	}
 
 	return toObject, nil
 }
 
-func imageFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func updateModelParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromImageBytes := getValueByPath(fromObject, []string{"bytesBase64Encoded"})
-	if fromImageBytes != nil {
-		fromImageBytes, err = tBytes(ac, fromImageBytes)
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
 		if err != nil {
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"imageBytes"}, fromImageBytes)
+		setValueByPath(toObject, []string{"_url", "name"}, fromModel)
 	}
 
-	fromMimeType := getValueByPath(fromObject, []string{"mimeType"})
-	if fromMimeType != nil {
-		setValueByPath(toObject, []string{"mimeType"}, fromMimeType)
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = updateModelConfigToMldev(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
 	}
 
 	return toObject, nil
 }
 
-func imageFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func updateModelParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromGcsUri := getValueByPath(fromObject, []string{"gcsUri"})
-	if fromGcsUri != nil {
-		setValueByPath(toObject, []string{"gcsUri"}, fromGcsUri)
-	}
-
-	fromImageBytes := getValueByPath(fromObject, []string{"bytesBase64Encoded"})
-	if fromImageBytes != nil {
-		fromImageBytes, err = tBytes(ac, fromImageBytes)
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
 		if err != nil {
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"imageBytes"}, fromImageBytes)
+		setValueByPath(toObject, []string{"_url", "name"}, fromModel)
 	}
 
-	fromMimeType := getValueByPath(fromObject, []string{"mimeType"})
-	if fromMimeType != nil {
-		setValueByPath(toObject, []string{"mimeType"}, fromMimeType)
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = updateModelConfigToVertex(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
 	}
 
 	return toObject, nil
 }
 
-func generatedImageFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func deleteModelParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromImage := getValueByPath(fromObject, []string{"_self"})
-	if fromImage != nil {
-		fromImage, err = imageFromMldev(ac, fromImage.(map[string]any), toObject)
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
 		if err != nil {
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"image"}, fromImage)
-	}
-
-	fromRaiFilteredReason := getValueByPath(fromObject, []string{"raiFilteredReason"})
-	if fromRaiFilteredReason != nil {
-		setValueByPath(toObject, []string{"raiFilteredReason"}, fromRaiFilteredReason)
+		setValueByPath(toObject, []string{"_url", "name"}, fromModel)
 	}
 
-	fromEnhancedPrompt := getValueByPath(fromObject, []string{"prompt"})
-	if fromEnhancedPrompt != nil {
-		setValueByPath(toObject, []string{"enhancedPrompt"}, fromEnhancedPrompt)
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		setValueByPath(toObject, []string{"config"}, fromConfig)
 	}
 
 	return toObject, nil
 }
 
-func generatedImageFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func deleteModelParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromImage := getValueByPath(fromObject, []string{"_self"})
-	if fromImage != nil {
-		fromImage, err = imageFromVertex(ac, fromImage.(map[string]any), toObject)
+	fromModel := getValueByPath(fromObject, []string{"model"})
+	if fromModel != nil {
+		fromModel, err = tModel(ac, fromModel)
 		if err != nil {
 			return nil, err
 		}
 
-		setValueByPath(toObject, []string{"image"}, fromImage)
+		setValueByPath(toObject, []string{"_url", "name"}, fromModel)
 	}
 
-	fromRaiFilteredReason := getValueByPath(fromObject, []string{"raiFilteredReason"})
-	if fromRaiFilteredReason != nil {
-		setValueByPath(toObject, []string{"raiFilteredReason"}, fromRaiFilteredReason)
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		setValueByPath(toObject, []string{"config"}, fromConfig)
 	}
 
-	fromEnhancedPrompt := getValueByPath(fromObject, []string{"prompt"})
-	if fromEnhancedPrompt != nil {
-		setValueByPath(toObject, []string{"enhancedPrompt"}, fromEnhancedPrompt)
+	return toObject, nil
+}
+
+func listModelsConfigToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromPageSize := getValueByPath(fromObject, []string{"pageSize"})
+	if fromPageSize != nil {
+		setValueByPath(parentObject, []string{"_query", "pageSize"}, fromPageSize)
+	}
+
+	fromPageToken := getValueByPath(fromObject, []string{"pageToken"})
+	if fromPageToken != nil {
+		setValueByPath(parentObject, []string{"_query", "pageToken"}, fromPageToken)
+	}
+
+	if getValueByPath(fromObject, []string{"filter"}) != nil {
+		return nil, fmt.Errorf("filter parameter is not supported in Gemini API")
 	}
 
 	return toObject, nil
 }
 
-func generateImagesResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+func listModelsConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
 	toObject = make(map[string]any)
 
-	fromGeneratedImages := getValueByPath(fromObject, []string{"predictions"})
-	if fromGeneratedImages != nil {
-		fromGeneratedImages, err = applyConverterToSlice(ac, fromGeneratedImages.([]any), generatedImageFromMldev)
-		if err != nil {
-			return nil, err
-		}
+	fromPageSize := getValueByPath(fromObject, []string{"pageSize"})
+	if fromPageSize != nil {
+		setValueByPath(parentObject, []string{"_query", "pageSize"}, fromPageSize)
+	}
 
-		setValueByPath(toObject, []string{"generatedImages"}, fromGeneratedImages)
+	fromPageToken := getValueByPath(fromObject, []string{"pageToken"})
+	if fromPageToken != nil {
+		setValueByPath(parentObject, []string{"_query", "pageToken"}, fromPageToken)
+	}
+
+	fromFilter := getValueByPath(fromObject, []string{"filter"})
+	if fromFilter != nil {
+		setValueByPath(parentObject, []string{"_query", "filter"}, fromFilter)
+	}
+
+	return toObject, nil
+}
+
+func listModelsParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = listModelsConfigToMldev(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func listModelsParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = listModelsConfigToVertex(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func partFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromThought := getValueByPath(fromObject, []string{"thought"})
+	if fromThought != nil {
+		setValueByPath(toObject, []string{"thought"}, fromThought)
+	}
+
+	fromCodeExecutionResult := getValueByPath(fromObject, []string{"codeExecutionResult"})
+	if fromCodeExecutionResult != nil {
+		setValueByPath(toObject, []string{"codeExecutionResult"}, fromCodeExecutionResult)
+	}
+
+	fromExecutableCode := getValueByPath(fromObject, []string{"executableCode"})
+	if fromExecutableCode != nil {
+		setValueByPath(toObject, []string{"executableCode"}, fromExecutableCode)
+	}
+
+	fromFileData := getValueByPath(fromObject, []string{"fileData"})
+	if fromFileData != nil {
+		setValueByPath(toObject, []string{"fileData"}, fromFileData)
+	}
+
+	fromFunctionCall := getValueByPath(fromObject, []string{"functionCall"})
+	if fromFunctionCall != nil {
+		setValueByPath(toObject, []string{"functionCall"}, fromFunctionCall)
+	}
+
+	fromFunctionResponse := getValueByPath(fromObject, []string{"functionResponse"})
+	if fromFunctionResponse != nil {
+		setValueByPath(toObject, []string{"functionResponse"}, fromFunctionResponse)
+	}
+
+	fromInlineData := getValueByPath(fromObject, []string{"inlineData"})
+	if fromInlineData != nil {
+		setValueByPath(toObject, []string{"inlineData"}, fromInlineData)
+	}
+
+	fromText := getValueByPath(fromObject, []string{"text"})
+	if fromText != nil {
+		setValueByPath(toObject, []string{"text"}, fromText)
+	}
+
+	return toObject, nil
+}
+
+func partFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromVideoMetadata := getValueByPath(fromObject, []string{"videoMetadata"})
+	if fromVideoMetadata != nil {
+		setValueByPath(toObject, []string{"videoMetadata"}, fromVideoMetadata)
+	}
+
+	fromThought := getValueByPath(fromObject, []string{"thought"})
+	if fromThought != nil {
+		setValueByPath(toObject, []string{"thought"}, fromThought)
+	}
+
+	fromCodeExecutionResult := getValueByPath(fromObject, []string{"codeExecutionResult"})
+	if fromCodeExecutionResult != nil {
+		setValueByPath(toObject, []string{"codeExecutionResult"}, fromCodeExecutionResult)
+	}
+
+	fromExecutableCode := getValueByPath(fromObject, []string{"executableCode"})
+	if fromExecutableCode != nil {
+		setValueByPath(toObject, []string{"executableCode"}, fromExecutableCode)
+	}
+
+	fromFileData := getValueByPath(fromObject, []string{"fileData"})
+	if fromFileData != nil {
+		setValueByPath(toObject, []string{"fileData"}, fromFileData)
+	}
+
+	fromFunctionCall := getValueByPath(fromObject, []string{"functionCall"})
+	if fromFunctionCall != nil {
+		setValueByPath(toObject, []string{"functionCall"}, fromFunctionCall)
+	}
+
+	fromFunctionResponse := getValueByPath(fromObject, []string{"functionResponse"})
+	if fromFunctionResponse != nil {
+		setValueByPath(toObject, []string{"functionResponse"}, fromFunctionResponse)
+	}
+
+	fromInlineData := getValueByPath(fromObject, []string{"inlineData"})
+	if fromInlineData != nil {
+		setValueByPath(toObject, []string{"inlineData"}, fromInlineData)
+	}
+
+	fromText := getValueByPath(fromObject, []string{"text"})
+	if fromText != nil {
+		setValueByPath(toObject, []string{"text"}, fromText)
+	}
+
+	return toObject, nil
+}
+
+func contentFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromParts := getValueByPath(fromObject, []string{"parts"})
+	if fromParts != nil {
+		fromParts, err = applyConverterToSlice(ac, fromParts.([]any), partFromMldev)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"parts"}, fromParts)
+	}
+
+	fromRole := getValueByPath(fromObject, []string{"role"})
+	if fromRole != nil {
+		setValueByPath(toObject, []string{"role"}, fromRole)
+	}
+
+	return toObject, nil
+}
+
+func contentFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromParts := getValueByPath(fromObject, []string{"parts"})
+	if fromParts != nil {
+		fromParts, err = applyConverterToSlice(ac, fromParts.([]any), partFromVertex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"parts"}, fromParts)
+	}
+
+	fromRole := getValueByPath(fromObject, []string{"role"})
+	if fromRole != nil {
+		setValueByPath(toObject, []string{"role"}, fromRole)
+	}
+
+	return toObject, nil
+}
+
+func citationMetadataFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromCitations := getValueByPath(fromObject, []string{"citationSources"})
+	if fromCitations != nil {
+		setValueByPath(toObject, []string{"citations"}, fromCitations)
+	}
+
+	return toObject, nil
+}
+
+func citationMetadataFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromCitations := getValueByPath(fromObject, []string{"citations"})
+	if fromCitations != nil {
+		setValueByPath(toObject, []string{"citations"}, fromCitations)
+	}
+
+	return toObject, nil
+}
+
+func candidateFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromContent := getValueByPath(fromObject, []string{"content"})
+	if fromContent != nil {
+		fromContent, err = contentFromMldev(ac, fromContent.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"content"}, fromContent)
+	}
+
+	fromCitationMetadata := getValueByPath(fromObject, []string{"citationMetadata"})
+	if fromCitationMetadata != nil {
+		fromCitationMetadata, err = citationMetadataFromMldev(ac, fromCitationMetadata.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"citationMetadata"}, fromCitationMetadata)
+	}
+
+	fromTokenCount := getValueByPath(fromObject, []string{"tokenCount"})
+	if fromTokenCount != nil {
+		setValueByPath(toObject, []string{"tokenCount"}, fromTokenCount)
+	}
+
+	fromAvgLogprobs := getValueByPath(fromObject, []string{"avgLogprobs"})
+	if fromAvgLogprobs != nil {
+		setValueByPath(toObject, []string{"avgLogprobs"}, fromAvgLogprobs)
+	}
+
+	fromFinishReason := getValueByPath(fromObject, []string{"finishReason"})
+	if fromFinishReason != nil {
+		setValueByPath(toObject, []string{"finishReason"}, fromFinishReason)
+	}
+
+	fromGroundingMetadata := getValueByPath(fromObject, []string{"groundingMetadata"})
+	if fromGroundingMetadata != nil {
+		setValueByPath(toObject, []string{"groundingMetadata"}, fromGroundingMetadata)
+	}
+
+	fromIndex := getValueByPath(fromObject, []string{"index"})
+	if fromIndex != nil {
+		fromIndex, err = tCandidateIndex(ac, fromIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"index"}, fromIndex)
+	}
+
+	fromLogprobsResult := getValueByPath(fromObject, []string{"logprobsResult"})
+	if fromLogprobsResult != nil {
+		setValueByPath(toObject, []string{"logprobsResult"}, fromLogprobsResult)
+	}
+
+	fromSafetyRatings := getValueByPath(fromObject, []string{"safetyRatings"})
+	if fromSafetyRatings != nil {
+		setValueByPath(toObject, []string{"safetyRatings"}, fromSafetyRatings)
+	}
+
+	return toObject, nil
+}
+
+func candidateFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromContent := getValueByPath(fromObject, []string{"content"})
+	if fromContent != nil {
+		fromContent, err = contentFromVertex(ac, fromContent.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"content"}, fromContent)
+	}
+
+	fromCitationMetadata := getValueByPath(fromObject, []string{"citationMetadata"})
+	if fromCitationMetadata != nil {
+		fromCitationMetadata, err = citationMetadataFromVertex(ac, fromCitationMetadata.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"citationMetadata"}, fromCitationMetadata)
+	}
+
+	fromFinishMessage := getValueByPath(fromObject, []string{"finishMessage"})
+	if fromFinishMessage != nil {
+		setValueByPath(toObject, []string{"finishMessage"}, fromFinishMessage)
+	}
+
+	fromAvgLogprobs := getValueByPath(fromObject, []string{"avgLogprobs"})
+	if fromAvgLogprobs != nil {
+		setValueByPath(toObject, []string{"avgLogprobs"}, fromAvgLogprobs)
+	}
+
+	fromFinishReason := getValueByPath(fromObject, []string{"finishReason"})
+	if fromFinishReason != nil {
+		setValueByPath(toObject, []string{"finishReason"}, fromFinishReason)
+	}
+
+	fromGroundingMetadata := getValueByPath(fromObject, []string{"groundingMetadata"})
+	if fromGroundingMetadata != nil {
+		setValueByPath(toObject, []string{"groundingMetadata"}, fromGroundingMetadata)
+	}
+
+	fromIndex := getValueByPath(fromObject, []string{"index"})
+	if fromIndex != nil {
+		fromIndex, err = tCandidateIndex(ac, fromIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"index"}, fromIndex)
+	}
+
+	fromLogprobsResult := getValueByPath(fromObject, []string{"logprobsResult"})
+	if fromLogprobsResult != nil {
+		setValueByPath(toObject, []string{"logprobsResult"}, fromLogprobsResult)
+	}
+
+	fromSafetyRatings := getValueByPath(fromObject, []string{"safetyRatings"})
+	if fromSafetyRatings != nil {
+		setValueByPath(toObject, []string{"safetyRatings"}, fromSafetyRatings)
+	}
+
+	return toObject, nil
+}
+
+func generateContentResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromCandidates := getValueByPath(fromObject, []string{"candidates"})
+	if fromCandidates != nil {
+		fromCandidates, err = applyConverterToSlice(ac, fromCandidates.([]any), candidateFromMldev)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"candidates"}, fromCandidates)
+	}
+
+	fromModelVersion := getValueByPath(fromObject, []string{"modelVersion"})
+	if fromModelVersion != nil {
+		setValueByPath(toObject, []string{"modelVersion"}, fromModelVersion)
+	}
+
+	fromPromptFeedback := getValueByPath(fromObject, []string{"promptFeedback"})
+	if fromPromptFeedback != nil {
+		setValueByPath(toObject, []string{"promptFeedback"}, fromPromptFeedback)
+	}
+
+	fromUsageMetadata := getValueByPath(fromObject, []string{"usageMetadata"})
+	if fromUsageMetadata != nil {
+		setValueByPath(toObject, []string{"usageMetadata"}, fromUsageMetadata)
+	}
+
+	return toObject, nil
+}
+
+func generateContentResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromCandidates := getValueByPath(fromObject, []string{"candidates"})
+	if fromCandidates != nil {
+		fromCandidates, err = applyConverterToSlice(ac, fromCandidates.([]any), candidateFromVertex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"candidates"}, fromCandidates)
+	}
+
+	fromModelVersion := getValueByPath(fromObject, []string{"modelVersion"})
+	if fromModelVersion != nil {
+		setValueByPath(toObject, []string{"modelVersion"}, fromModelVersion)
+	}
+
+	fromPromptFeedback := getValueByPath(fromObject, []string{"promptFeedback"})
+	if fromPromptFeedback != nil {
+		setValueByPath(toObject, []string{"promptFeedback"}, fromPromptFeedback)
+	}
+
+	fromUsageMetadata := getValueByPath(fromObject, []string{"usageMetadata"})
+	if fromUsageMetadata != nil {
+		setValueByPath(toObject, []string{"usageMetadata"}, fromUsageMetadata)
+	}
+
+	return toObject, nil
+}
+
+func imageFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromImageBytes := getValueByPath(fromObject, []string{"bytesBase64Encoded"})
+	if fromImageBytes != nil {
+		fromImageBytes, err = tBytes(ac, fromImageBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"imageBytes"}, fromImageBytes)
+	}
+
+	fromMimeType := getValueByPath(fromObject, []string{"mimeType"})
+	if fromMimeType != nil {
+		setValueByPath(toObject, []string{"mimeType"}, fromMimeType)
+	}
+
+	return toObject, nil
+}
+
+func imageFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromGcsUri := getValueByPath(fromObject, []string{"gcsUri"})
+	if fromGcsUri != nil {
+		setValueByPath(toObject, []string{"gcsUri"}, fromGcsUri)
+	}
+
+	fromImageBytes := getValueByPath(fromObject, []string{"bytesBase64Encoded"})
+	if fromImageBytes != nil {
+		fromImageBytes, err = tBytes(ac, fromImageBytes)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"imageBytes"}, fromImageBytes)
+	}
+
+	fromMimeType := getValueByPath(fromObject, []string{"mimeType"})
+	if fromMimeType != nil {
+		setValueByPath(toObject, []string{"mimeType"}, fromMimeType)
+	}
+
+	return toObject, nil
+}
+
+func generatedImageFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromImage := getValueByPath(fromObject, []string{"_self"})
+	if fromImage != nil {
+		fromImage, err = imageFromMldev(ac, fromImage.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"image"}, fromImage)
+	}
+
+	fromRaiFilteredReason := getValueByPath(fromObject, []string{"raiFilteredReason"})
+	if fromRaiFilteredReason != nil {
+		setValueByPath(toObject, []string{"raiFilteredReason"}, fromRaiFilteredReason)
+	}
+
+	fromEnhancedPrompt := getValueByPath(fromObject, []string{"prompt"})
+	if fromEnhancedPrompt != nil {
+		setValueByPath(toObject, []string{"enhancedPrompt"}, fromEnhancedPrompt)
+	}
+
+	return toObject, nil
+}
+
+func generatedImageFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromImage := getValueByPath(fromObject, []string{"_self"})
+	if fromImage != nil {
+		fromImage, err = imageFromVertex(ac, fromImage.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"image"}, fromImage)
+	}
+
+	fromRaiFilteredReason := getValueByPath(fromObject, []string{"raiFilteredReason"})
+	if fromRaiFilteredReason != nil {
+		setValueByPath(toObject, []string{"raiFilteredReason"}, fromRaiFilteredReason)
+	}
+
+	fromEnhancedPrompt := getValueByPath(fromObject, []string{"prompt"})
+	if fromEnhancedPrompt != nil {
+		setValueByPath(toObject, []string{"enhancedPrompt"}, fromEnhancedPrompt)
+	}
+
+	return toObject, nil
+}
+
+func generateImagesResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromGeneratedImages := getValueByPath(fromObject, []string{"predictions"})
+	if fromGeneratedImages != nil {
+		fromGeneratedImages, err = applyConverterToSlice(ac, fromGeneratedImages.([]any), generatedImageFromMldev)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"generatedImages"}, fromGeneratedImages)
+	}
+
+	return toObject, nil
+}
+
+func generateImagesResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromGeneratedImages := getValueByPath(fromObject, []string{"predictions"})
+	if fromGeneratedImages != nil {
+		fromGeneratedImages, err = applyConverterToSlice(ac, fromGeneratedImages.([]any), generatedImageFromVertex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"generatedImages"}, fromGeneratedImages)
+	}
+
+	return toObject, nil
+}
+
+func countTokensResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromTotalTokens := getValueByPath(fromObject, []string{"totalTokens"})
+	if fromTotalTokens != nil {
+		setValueByPath(toObject, []string{"totalTokens"}, fromTotalTokens)
+	}
+
+	fromCachedContentTokenCount := getValueByPath(fromObject, []string{"cachedContentTokenCount"})
+	if fromCachedContentTokenCount != nil {
+		setValueByPath(toObject, []string{"cachedContentTokenCount"}, fromCachedContentTokenCount)
+	}
+
+	return toObject, nil
+}
+
+func countTokensResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromTotalTokens := getValueByPath(fromObject, []string{"totalTokens"})
+	if fromTotalTokens != nil {
+		setValueByPath(toObject, []string{"totalTokens"}, fromTotalTokens)
+	}
+
+	return toObject, nil
+}
+
+func computeTokensResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromTokensInfo := getValueByPath(fromObject, []string{"tokensInfo"})
+	if fromTokensInfo != nil {
+		setValueByPath(toObject, []string{"tokensInfo"}, fromTokensInfo)
+	}
+
+	return toObject, nil
+}
+
+func computeTokensResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromTokensInfo := getValueByPath(fromObject, []string{"tokensInfo"})
+	if fromTokensInfo != nil {
+		setValueByPath(toObject, []string{"tokensInfo"}, fromTokensInfo)
+	}
+
+	return toObject, nil
+}
+
+func contentEmbeddingStatisticsFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromTruncated := getValueByPath(fromObject, []string{"truncated"})
+	if fromTruncated != nil {
+		setValueByPath(toObject, []string{"truncated"}, fromTruncated)
+	}
+
+	fromTokenCount := getValueByPath(fromObject, []string{"token_count"})
+	if fromTokenCount != nil {
+		setValueByPath(toObject, []string{"tokenCount"}, fromTokenCount)
+	}
+
+	return toObject, nil
+}
+
+func contentEmbeddingFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromValues := getValueByPath(fromObject, []string{"values"})
+	if fromValues != nil {
+		setValueByPath(toObject, []string{"values"}, fromValues)
+	}
+
+	return toObject, nil
+}
+
+func contentEmbeddingFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromValues := getValueByPath(fromObject, []string{"values"})
+	if fromValues != nil {
+		setValueByPath(toObject, []string{"values"}, fromValues)
+	}
+
+	fromStatistics := getValueByPath(fromObject, []string{"statistics"})
+	if fromStatistics != nil {
+		fromStatistics, err = contentEmbeddingStatisticsFromVertex(ac, fromStatistics.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"statistics"}, fromStatistics)
+	}
+
+	return toObject, nil
+}
+
+// embeddingPredictionFromVertex unwraps the embedding of a single Vertex AI
+// prediction.
+func embeddingPredictionFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromEmbeddings := getValueByPath(fromObject, []string{"embeddings"})
+	if fromEmbeddings != nil {
+		return contentEmbeddingFromVertex(ac, fromEmbeddings.(map[string]any), toObject)
+	}
+
+	return toObject, nil
+}
+
+func embedContentResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromEmbedding := getValueByPath(fromObject, []string{"embedding"})
+	if fromEmbedding != nil {
+		fromEmbedding, err = contentEmbeddingFromMldev(ac, fromEmbedding.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"embedding"}, fromEmbedding)
+	}
+
+	return toObject, nil
+}
+
+func embedContentResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromPredictions := getValueByPath(fromObject, []string{"predictions"})
+	if fromPredictions != nil {
+		embeddings, err := applyConverterToSlice(ac, fromPredictions.([]any), embeddingPredictionFromVertex)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(embeddings) > 0 {
+			setValueByPath(toObject, []string{"embedding"}, embeddings[0])
+		}
+	}
+
+	return toObject, nil
+}
+
+func batchEmbedContentsResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromEmbeddings := getValueByPath(fromObject, []string{"embeddings"})
+	if fromEmbeddings != nil {
+		fromEmbeddings, err = applyConverterToSlice(ac, fromEmbeddings.([]any), contentEmbeddingFromMldev)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"embeddings"}, fromEmbeddings)
+	}
+
+	return toObject, nil
+}
+
+func batchEmbedContentsResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromPredictions := getValueByPath(fromObject, []string{"predictions"})
+	if fromPredictions != nil {
+		fromPredictions, err = applyConverterToSlice(ac, fromPredictions.([]any), embeddingPredictionFromVertex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"embeddings"}, fromPredictions)
+	}
+
+	return toObject, nil
+}
+
+func modelInfoFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		setValueByPath(toObject, []string{"name"}, fromName)
+	}
+
+	fromDisplayName := getValueByPath(fromObject, []string{"displayName"})
+	if fromDisplayName != nil {
+		setValueByPath(toObject, []string{"displayName"}, fromDisplayName)
+	}
+
+	fromDescription := getValueByPath(fromObject, []string{"description"})
+	if fromDescription != nil {
+		setValueByPath(toObject, []string{"description"}, fromDescription)
+	}
+
+	fromVersion := getValueByPath(fromObject, []string{"version"})
+	if fromVersion != nil {
+		setValueByPath(toObject, []string{"version"}, fromVersion)
+	}
+
+	fromSupportedGenerationMethods := getValueByPath(fromObject, []string{"supportedGenerationMethods"})
+	if fromSupportedGenerationMethods != nil {
+		setValueByPath(toObject, []string{"supportedGenerationMethods"}, fromSupportedGenerationMethods)
+	}
+
+	fromInputTokenLimit := getValueByPath(fromObject, []string{"inputTokenLimit"})
+	if fromInputTokenLimit != nil {
+		setValueByPath(toObject, []string{"inputTokenLimit"}, fromInputTokenLimit)
+	}
+
+	fromOutputTokenLimit := getValueByPath(fromObject, []string{"outputTokenLimit"})
+	if fromOutputTokenLimit != nil {
+		setValueByPath(toObject, []string{"outputTokenLimit"}, fromOutputTokenLimit)
+	}
+
+	fromMaxTemperature := getValueByPath(fromObject, []string{"maxTemperature"})
+	if fromMaxTemperature != nil {
+		setValueByPath(toObject, []string{"maxTemperature"}, fromMaxTemperature)
+	}
+
+	return toObject, nil
+}
+
+func modelInfoFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		setValueByPath(toObject, []string{"name"}, fromName)
+	}
+
+	fromDisplayName := getValueByPath(fromObject, []string{"displayName"})
+	if fromDisplayName != nil {
+		setValueByPath(toObject, []string{"displayName"}, fromDisplayName)
+	}
+
+	fromDescription := getValueByPath(fromObject, []string{"description"})
+	if fromDescription != nil {
+		setValueByPath(toObject, []string{"description"}, fromDescription)
+	}
+
+	fromVersion := getValueByPath(fromObject, []string{"versionId"})
+	if fromVersion != nil {
+		setValueByPath(toObject, []string{"version"}, fromVersion)
+	}
+
+	return toObject, nil
+}
+
+func listModelsResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromNextPageToken := getValueByPath(fromObject, []string{"nextPageToken"})
+	if fromNextPageToken != nil {
+		setValueByPath(toObject, []string{"nextPageToken"}, fromNextPageToken)
+	}
+
+	fromModels := getValueByPath(fromObject, []string{"models"})
+	if fromModels != nil {
+		fromModels, err = applyConverterToSlice(ac, fromModels.([]any), modelInfoFromMldev)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"models"}, fromModels)
+	}
+
+	return toObject, nil
+}
+
+func listModelsResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromNextPageToken := getValueByPath(fromObject, []string{"nextPageToken"})
+	if fromNextPageToken != nil {
+		setValueByPath(toObject, []string{"nextPageToken"}, fromNextPageToken)
+	}
+
+	fromModels := getValueByPath(fromObject, []string{"publisherModels"})
+	if fromModels != nil {
+		fromModels, err = applyConverterToSlice(ac, fromModels.([]any), modelInfoFromVertex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"models"}, fromModels)
+	}
+
+	return toObject, nil
+}
+
+func deleteModelResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	return toObject, nil
+}
+
+func deleteModelResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	return toObject, nil
+}
+
+type Models struct {
+	apiClient *apiClient
+}
+
+func (m Models) generateContent(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) (*GenerateContentResponse, error) {
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"model": model, "contents": contents, "config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(GenerateContentResponse)
+	var responseMap map[string]any
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = generateContentParametersToVertex
+		fromConverter = generateContentResponseFromVertex
+	} else {
+		toConverter = generateContentParametersToMldev
+		fromConverter = generateContentResponseFromMldev
+	}
+
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var path string
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		path, err = formatMap("{model}:generateContent", urlParams)
+	} else {
+		path, err = formatMap("{model}:generateContent", urlParams)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// generateContentStream ...
+func (m Models) generateContentStream(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) iter.Seq2[*GenerateContentResponse, error] {
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"model": model, "contents": contents, "config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var rs responseStream[GenerateContentResponse]
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	yieldErrorAndEndIterator := func(err error) iter.Seq2[*GenerateContentResponse, error] {
+		return func(yield func(*GenerateContentResponse, error) bool) {
+			if !yield(nil, err) {
+				return
+			}
+		}
+	}
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = generateContentParametersToVertex
+		fromConverter = generateContentResponseFromVertex
+	} else {
+		toConverter = generateContentParametersToMldev
+		fromConverter = generateContentResponseFromMldev
+	}
+
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return yieldErrorAndEndIterator(err)
+	}
+	var path string
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		path, err = formatMap("{model}:streamGenerateContent", urlParams)
+	} else {
+		path, err = formatMap("{model}:streamGenerateContent", urlParams)
+	}
+	if err != nil {
+		return yieldErrorAndEndIterator(fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err))
+	}
+	switch httpOptions.StreamFormat {
+	case "", StreamFormatSSE:
+		path += "?alt=sse"
+	case StreamFormatNDJSON:
+	default:
+		return yieldErrorAndEndIterator(fmt.Errorf("unsupported stream format %q", httpOptions.StreamFormat))
+	}
+	delete(body, "_url")
+	delete(body, "config")
+	err = sendStreamRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions, &rs)
+	if err != nil {
+		return yieldErrorAndEndIterator(err)
+	}
+	return iterateResponseStream(&rs, func(responseMap map[string]any) (*GenerateContentResponse, error) {
+		responseMap, err := fromConverter(m.apiClient, responseMap, nil)
+		if err != nil {
+			return nil, err
+		}
+		var response = new(GenerateContentResponse)
+		err = mapToStruct(responseMap, response)
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	})
+}
+
+func (m Models) GenerateImages(ctx context.Context, model string, prompt string, config *GenerateImagesConfig) (*GenerateImagesResponse, error) {
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"model": model, "prompt": prompt, "config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(GenerateImagesResponse)
+	var responseMap map[string]any
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = generateImagesParametersToVertex
+		fromConverter = generateImagesResponseFromVertex
+	} else {
+		toConverter = generateImagesParametersToMldev
+		fromConverter = generateImagesResponseFromMldev
+	}
+
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var path string
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		path, err = formatMap("{model}:predict", urlParams)
+	} else {
+		path, err = formatMap("{model}:predict", urlParams)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (m Models) CountTokens(ctx context.Context, model string, contents []*Content, config *CountTokensConfig) (*CountTokensResponse, error) {
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"model": model, "contents": contents, "config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(CountTokensResponse)
+	var responseMap map[string]any
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = countTokensParametersToVertex
+		fromConverter = countTokensResponseFromVertex
+	} else {
+		toConverter = countTokensParametersToMldev
+		fromConverter = countTokensResponseFromMldev
+	}
+
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var path string
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		path, err = formatMap("{model}:countTokens", urlParams)
+	} else {
+		path, err = formatMap("{model}:countTokens", urlParams)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (m Models) ComputeTokens(ctx context.Context, model string, contents []*Content, config *ComputeTokensConfig) (*ComputeTokensResponse, error) {
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"model": model, "contents": contents, "config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	if m.apiClient.clientConfig.Backend == BackendGeminiAPI {
+		return nil, newInvalidArgumentError("method ComputeTokens is only supported in Vertex AI backend.")
+	}
+
+	var response = new(ComputeTokensResponse)
+	var responseMap map[string]any
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = computeTokensParametersToVertex
+		fromConverter = computeTokensResponseFromVertex
+	} else {
+		toConverter = computeTokensParametersToMldev
+		fromConverter = computeTokensResponseFromMldev
+	}
+
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var path string
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		path, err = formatMap("{model}:computeTokens", urlParams)
+	} else {
+		path, err = formatMap("None", urlParams)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
 	}
-
-	return toObject, nil
+	return response, nil
 }
 
-func generateImagesResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
-	toObject = make(map[string]any)
+func (m Models) embedContent(ctx context.Context, model string, content *Content, config *EmbedContentConfig) (*EmbedContentResponse, error) {
+	parameterMap := make(map[string]any)
 
-	fromGeneratedImages := getValueByPath(fromObject, []string{"predictions"})
-	if fromGeneratedImages != nil {
-		fromGeneratedImages, err = applyConverterToSlice(ac, fromGeneratedImages.([]any), generatedImageFromVertex)
-		if err != nil {
-			return nil, err
-		}
+	kwargs := map[string]any{"model": model, "content": content, "config": config}
+	deepMarshal(kwargs, &parameterMap)
 
-		setValueByPath(toObject, []string{"generatedImages"}, fromGeneratedImages)
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
 	}
 
-	return toObject, nil
-}
-
-func countTokensResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
-	toObject = make(map[string]any)
-
-	fromTotalTokens := getValueByPath(fromObject, []string{"totalTokens"})
-	if fromTotalTokens != nil {
-		setValueByPath(toObject, []string{"totalTokens"}, fromTotalTokens)
+	var response = new(EmbedContentResponse)
+	var responseMap map[string]any
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = embedContentParametersToVertex
+		fromConverter = embedContentResponseFromVertex
+	} else {
+		toConverter = embedContentParametersToMldev
+		fromConverter = embedContentResponseFromMldev
 	}
 
-	fromCachedContentTokenCount := getValueByPath(fromObject, []string{"cachedContentTokenCount"})
-	if fromCachedContentTokenCount != nil {
-		setValueByPath(toObject, []string{"cachedContentTokenCount"}, fromCachedContentTokenCount)
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	return toObject, nil
-}
-
-func countTokensResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
-	toObject = make(map[string]any)
-
-	fromTotalTokens := getValueByPath(fromObject, []string{"totalTokens"})
-	if fromTotalTokens != nil {
-		setValueByPath(toObject, []string{"totalTokens"}, fromTotalTokens)
+	var path string
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
 	}
-
-	return toObject, nil
-}
-
-func computeTokensResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
-	toObject = make(map[string]any)
-
-	fromTokensInfo := getValueByPath(fromObject, []string{"tokensInfo"})
-	if fromTokensInfo != nil {
-		setValueByPath(toObject, []string{"tokensInfo"}, fromTokensInfo)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		path, err = formatMap("{model}:predict", urlParams)
+	} else {
+		path, err = formatMap("{model}:embedContent", urlParams)
 	}
-
-	return toObject, nil
-}
-
-func computeTokensResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
-	toObject = make(map[string]any)
-
-	fromTokensInfo := getValueByPath(fromObject, []string{"tokensInfo"})
-	if fromTokensInfo != nil {
-		setValueByPath(toObject, []string{"tokensInfo"}, fromTokensInfo)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
 	}
 
-	return toObject, nil
-}
-
-type Models struct {
-	apiClient *apiClient
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
 }
 
-func (m Models) generateContent(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) (*GenerateContentResponse, error) {
+func (m Models) batchEmbedContents(ctx context.Context, model string, requests []*EmbedContentRequest, config *BatchEmbedContentsConfig) (*BatchEmbedContentsResponse, error) {
 	parameterMap := make(map[string]any)
 
-	kwargs := map[string]any{"model": model, "contents": contents, "config": config}
+	kwargs := map[string]any{"model": model, "requests": requests, "config": config}
 	deepMarshal(kwargs, &parameterMap)
 
-	var response = new(GenerateContentResponse)
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(BatchEmbedContentsResponse)
 	var responseMap map[string]any
 	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		toConverter = generateContentParametersToVertex
-		fromConverter = generateContentResponseFromVertex
+		toConverter = batchEmbedContentsParametersToVertex
+		fromConverter = batchEmbedContentsResponseFromVertex
 	} else {
-		toConverter = generateContentParametersToMldev
-		fromConverter = generateContentResponseFromMldev
+		toConverter = batchEmbedContentsParametersToMldev
+		fromConverter = batchEmbedContentsResponseFromMldev
 	}
 
 	body, err := toConverter(m.apiClient, parameterMap, nil)
@@ -2272,9 +3565,9 @@ func (m Models) generateContent(ctx context.Context, model string, contents []*C
 		delete(body, "_url")
 	}
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		path, err = formatMap("{model}:generateContent", urlParams)
+		path, err = formatMap("{model}:predict", urlParams)
 	} else {
-		path, err = formatMap("{model}:generateContent", urlParams)
+		path, err = formatMap("{model}:batchEmbedContents", urlParams)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
@@ -2283,7 +3576,7 @@ func (m Models) generateContent(ctx context.Context, model string, contents []*C
 	if _, ok := body["config"]; ok {
 		delete(body, "config")
 	}
-	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body)
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -2298,34 +3591,34 @@ func (m Models) generateContent(ctx context.Context, model string, contents []*C
 	return response, nil
 }
 
-// generateContentStream ...
-func (m Models) generateContentStream(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) iter.Seq2[*GenerateContentResponse, error] {
+func (m Models) Get(ctx context.Context, model string, config *GetModelConfig) (*ModelInfo, error) {
 	parameterMap := make(map[string]any)
 
-	kwargs := map[string]any{"model": model, "contents": contents, "config": config}
+	kwargs := map[string]any{"model": model, "config": config}
 	deepMarshal(kwargs, &parameterMap)
 
-	var rs responseStream[GenerateContentResponse]
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(ModelInfo)
+	var responseMap map[string]any
 	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
-	yieldErrorAndEndIterator := func(err error) iter.Seq2[*GenerateContentResponse, error] {
-		return func(yield func(*GenerateContentResponse, error) bool) {
-			if !yield(nil, err) {
-				return
-			}
-		}
-	}
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		toConverter = generateContentParametersToVertex
-		fromConverter = generateContentResponseFromVertex
+		toConverter = getModelParametersToVertex
+		fromConverter = modelInfoFromVertex
 	} else {
-		toConverter = generateContentParametersToMldev
-		fromConverter = generateContentResponseFromMldev
+		toConverter = getModelParametersToMldev
+		fromConverter = modelInfoFromMldev
 	}
 
 	body, err := toConverter(m.apiClient, parameterMap, nil)
 	if err != nil {
-		return yieldErrorAndEndIterator(err)
+		return nil, err
 	}
 	var path string
 	var urlParams map[string]any
@@ -2334,49 +3627,59 @@ func (m Models) generateContentStream(ctx context.Context, model string, content
 		delete(body, "_url")
 	}
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		path, err = formatMap("{model}:streamGenerateContent?alt=sse", urlParams)
+		path, err = formatMap("{name}", urlParams)
 	} else {
-		path, err = formatMap("{model}:streamGenerateContent?alt=sse", urlParams)
+		path, err = formatMap("{name}", urlParams)
 	}
 	if err != nil {
-		return yieldErrorAndEndIterator(fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err))
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
 	}
-	delete(body, "_url")
-	delete(body, "config")
-	err = sendStreamRequest(ctx, m.apiClient, path, http.MethodPost, &body, &rs)
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodGet, &body, httpOptions)
 	if err != nil {
-		return yieldErrorAndEndIterator(err)
+		return nil, err
 	}
-	return iterateResponseStream(&rs, func(responseMap map[string]any) (*GenerateContentResponse, error) {
-		responseMap, err := fromConverter(m.apiClient, responseMap, nil)
-		if err != nil {
-			return nil, err
-		}
-		var response = new(GenerateContentResponse)
-		err = mapToStruct(responseMap, response)
-		if err != nil {
-			return nil, err
-		}
-		return response, nil
-	})
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	// Remember the model limits to validate later requests locally.
+	if name, err := tModel(m.apiClient, model); err == nil {
+		m.apiClient.modelInfo.Store(name, response)
+	}
+	return response, nil
 }
 
-func (m Models) GenerateImages(ctx context.Context, model string, prompt string, config *GenerateImagesConfig) (*GenerateImagesResponse, error) {
+func (m Models) list(ctx context.Context, config *ListModelsConfig) (*ListModelsResponse, error) {
 	parameterMap := make(map[string]any)
 
-	kwargs := map[string]any{"model": model, "prompt": prompt, "config": config}
+	kwargs := map[string]any{"config": config}
 	deepMarshal(kwargs, &parameterMap)
 
-	var response = new(GenerateImagesResponse)
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(ListModelsResponse)
 	var responseMap map[string]any
 	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		toConverter = generateImagesParametersToVertex
-		fromConverter = generateImagesResponseFromVertex
+		toConverter = listModelsParametersToVertex
+		fromConverter = listModelsResponseFromVertex
 	} else {
-		toConverter = generateImagesParametersToMldev
-		fromConverter = generateImagesResponseFromMldev
+		toConverter = listModelsParametersToMldev
+		fromConverter = listModelsResponseFromMldev
 	}
 
 	body, err := toConverter(m.apiClient, parameterMap, nil)
@@ -2390,18 +3693,22 @@ func (m Models) GenerateImages(ctx context.Context, model string, prompt string,
 		delete(body, "_url")
 	}
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		path, err = formatMap("{model}:predict", urlParams)
+		path, err = formatMap("publishers/google/models", urlParams)
 	} else {
-		path, err = formatMap("{model}:predict", urlParams)
+		path, err = formatMap("models", urlParams)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
 	}
+	if _, ok := body["_query"]; ok {
+		path += "?" + createURLQuery(body["_query"].(map[string]any))
+		delete(body, "_query")
+	}
 
 	if _, ok := body["config"]; ok {
 		delete(body, "config")
 	}
-	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body)
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodGet, &body, httpOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -2416,22 +3723,32 @@ func (m Models) GenerateImages(ctx context.Context, model string, prompt string,
 	return response, nil
 }
 
-func (m Models) CountTokens(ctx context.Context, model string, contents []*Content, config *CountTokensConfig) (*CountTokensResponse, error) {
+// Update updates the display name and description of a tuned model, and returns the
+// updated model. On the Gemini API, model is the name of a tuned model, such as
+// "tunedModels/my-model".
+func (m Models) Update(ctx context.Context, model string, config *UpdateModelConfig) (*ModelInfo, error) {
 	parameterMap := make(map[string]any)
 
-	kwargs := map[string]any{"model": model, "contents": contents, "config": config}
+	kwargs := map[string]any{"model": model, "config": config}
 	deepMarshal(kwargs, &parameterMap)
 
-	var response = new(CountTokensResponse)
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(ModelInfo)
 	var responseMap map[string]any
 	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		toConverter = countTokensParametersToVertex
-		fromConverter = countTokensResponseFromVertex
+		toConverter = updateModelParametersToVertex
+		fromConverter = modelInfoFromVertex
 	} else {
-		toConverter = countTokensParametersToMldev
-		fromConverter = countTokensResponseFromMldev
+		toConverter = updateModelParametersToMldev
+		fromConverter = modelInfoFromMldev
 	}
 
 	body, err := toConverter(m.apiClient, parameterMap, nil)
@@ -2445,18 +3762,22 @@ func (m Models) CountTokens(ctx context.Context, model string, contents []*Conte
 		delete(body, "_url")
 	}
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		path, err = formatMap("{model}:countTokens", urlParams)
+		path, err = formatMap("{name}", urlParams)
 	} else {
-		path, err = formatMap("{model}:countTokens", urlParams)
+		path, err = formatMap("{name}", urlParams)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
 	}
+	if _, ok := body["_query"]; ok {
+		path += "?" + createURLQuery(body["_query"].(map[string]any))
+		delete(body, "_query")
+	}
 
 	if _, ok := body["config"]; ok {
 		delete(body, "config")
 	}
-	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body)
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPatch, &body, httpOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -2471,26 +3792,30 @@ func (m Models) CountTokens(ctx context.Context, model string, contents []*Conte
 	return response, nil
 }
 
-func (m Models) ComputeTokens(ctx context.Context, model string, contents []*Content, config *ComputeTokensConfig) (*ComputeTokensResponse, error) {
+// Delete deletes a tuned model. Base models cannot be deleted.
+func (m Models) Delete(ctx context.Context, model string, config *DeleteModelConfig) (*DeleteModelResponse, error) {
 	parameterMap := make(map[string]any)
 
-	kwargs := map[string]any{"model": model, "contents": contents, "config": config}
+	kwargs := map[string]any{"model": model, "config": config}
 	deepMarshal(kwargs, &parameterMap)
 
-	if m.apiClient.clientConfig.Backend == BackendGeminiAPI {
-		return nil, fmt.Errorf("method ComputeTokens is only supported in Vertex AI backend.")
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
 	}
 
-	var response = new(ComputeTokensResponse)
+	var response = new(DeleteModelResponse)
 	var responseMap map[string]any
 	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		toConverter = computeTokensParametersToVertex
-		fromConverter = computeTokensResponseFromVertex
+		toConverter = deleteModelParametersToVertex
+		fromConverter = deleteModelResponseFromVertex
 	} else {
-		toConverter = computeTokensParametersToMldev
-		fromConverter = computeTokensResponseFromMldev
+		toConverter = deleteModelParametersToMldev
+		fromConverter = deleteModelResponseFromMldev
 	}
 
 	body, err := toConverter(m.apiClient, parameterMap, nil)
@@ -2504,9 +3829,9 @@ func (m Models) ComputeTokens(ctx context.Context, model string, contents []*Con
 		delete(body, "_url")
 	}
 	if m.apiClient.clientConfig.Backend == BackendVertexAI {
-		path, err = formatMap("{model}:computeTokens", urlParams)
+		path, err = formatMap("{name}", urlParams)
 	} else {
-		path, err = formatMap("None", urlParams)
+		path, err = formatMap("{name}", urlParams)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
@@ -2515,7 +3840,7 @@ func (m Models) ComputeTokens(ctx context.Context, model string, contents []*Con
 	if _, ok := body["config"]; ok {
 		delete(body, "config")
 	}
-	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body)
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodDelete, &body, httpOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -2527,6 +3852,9 @@ func (m Models) ComputeTokens(ctx context.Context, model string, contents []*Con
 	if err != nil {
 		return nil, err
 	}
+	if name, err := tModel(m.apiClient, model); err == nil {
+		m.apiClient.modelInfo.Delete(name)
+	}
 	return response, nil
 }
 
@@ -2538,7 +3866,20 @@ func (m Models) GenerateContent(ctx context.Context, model string, contents []*C
 	for _, c := range contents {
 		c.setDefaults()
 	}
-	return m.generateContent(ctx, model, contents, config)
+	m.loadModelConstraints(ctx, model, config)
+	if err := m.validateGenerateContent(model, contents, config); err != nil {
+		return nil, err
+	}
+	resp, err := m.generateContent(ctx, model, contents, config)
+	if err != nil {
+		return nil, err
+	}
+	if config != nil && config.ValidateResponseSchema && config.ResponseSchema != nil {
+		if err := validateJSONResponse(resp, config.ResponseSchema); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
 }
 
 // GenerateContentStream calls the GenerateContentStream method on the model.
@@ -2549,5 +3890,153 @@ func (m Models) GenerateContentStream(ctx context.Context, model string, content
 	for _, c := range contents {
 		c.setDefaults()
 	}
+	m.loadModelConstraints(ctx, model, config)
+	if err := m.validateGenerateContent(model, contents, config); err != nil {
+		return func(yield func(*GenerateContentResponse, error) bool) {
+			yield(nil, err)
+		}
+	}
 	return m.generateContentStream(ctx, model, contents, config)
 }
+
+// GenerateContentStreamChan is like GenerateContentStream, but sends the responses
+// on a channel, so that the stream can be received in a select statement. The
+// responses channel is unbuffered and closed at the end of the stream. Then the
+// error channel receives the error that ended the stream, or nil, and is closed.
+// If ctx is done, the stream is stopped and the error is ctx.Err(), even if the
+// caller is no longer receiving responses.
+func (m Models) GenerateContentStreamChan(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) (<-chan *GenerateContentResponse, <-chan error) {
+	return generateContentStreamChan(ctx, m, model, contents, config)
+}
+
+// generateContentStreamChan implements GenerateContentStreamChan with
+// m.GenerateContentStream, for Models and MockModels.
+func generateContentStreamChan(ctx context.Context, m ModelsInterface, model string, contents []*Content, config *GenerateContentConfig) (<-chan *GenerateContentResponse, <-chan error) {
+	responses := make(chan *GenerateContentResponse)
+	errc := make(chan error, 1)
+	go func() {
+		var err error
+		defer func() {
+			close(responses)
+			errc <- err
+			close(errc)
+		}()
+		for resp, respErr := range m.GenerateContentStream(ctx, model, contents, config) {
+			if respErr != nil {
+				err = respErr
+				return
+			}
+			select {
+			case responses <- resp:
+			case <-ctx.Done():
+				err = ctx.Err()
+				return
+			}
+		}
+	}()
+	return responses, errc
+}
+
+// EmbedContent generates an embedding for the given content. If
+// ClientConfig.EmbeddingsCache is set, an embedding found in the cache is returned
+// without calling the API; it has no Statistics.
+func (m Models) EmbedContent(ctx context.Context, model string, content *Content, config *EmbedContentConfig) (*EmbedContentResponse, error) {
+	cache := m.apiClient.clientConfig.EmbeddingsCache
+	if cache == nil {
+		return m.embedContent(ctx, model, content, config)
+	}
+	key, err := m.embeddingCacheKey(model, content, config)
+	if err != nil {
+		return nil, err
+	}
+	if values, ok := cache.Get(key); ok {
+		return &EmbedContentResponse{Embedding: &ContentEmbedding{Values: values}}, nil
+	}
+	resp, err := m.embedContent(ctx, model, content, config)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Embedding != nil {
+		cache.Set(key, resp.Embedding.Values)
+	}
+	return resp, nil
+}
+
+// BatchEmbedContents generates embeddings for multiple contents in a single request.
+// The embeddings are returned in the same order as the requests. If
+// ClientConfig.EmbeddingsCache is set, only the contents not found in the cache are
+// sent to the API, each of them once; the embeddings found in the cache have no
+// Statistics.
+func (m Models) BatchEmbedContents(ctx context.Context, model string, requests []*EmbedContentRequest, config *BatchEmbedContentsConfig) (*BatchEmbedContentsResponse, error) {
+	cache := m.apiClient.clientConfig.EmbeddingsCache
+	if cache == nil {
+		return m.batchEmbedContents(ctx, model, requests, config)
+	}
+
+	embeddings := make([]*ContentEmbedding, len(requests))
+	keys := make([]string, len(requests))
+	var misses []*EmbedContentRequest
+	var missKeys []string
+	missIndex := make(map[string]int) // Index in misses of the request with a key.
+	for i, r := range requests {
+		if r == nil {
+			return nil, newInvalidArgumentError("requests[%d] is nil", i)
+		}
+		key, err := m.embeddingCacheKey(model, r.Content, r.Config)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = key
+		if values, ok := cache.Get(key); ok {
+			embeddings[i] = &ContentEmbedding{Values: values}
+		} else if _, ok := missIndex[key]; !ok {
+			missIndex[key] = len(misses)
+			misses = append(misses, r)
+			missKeys = append(missKeys, key)
+		}
+	}
+	if len(misses) == 0 {
+		return &BatchEmbedContentsResponse{Embeddings: embeddings}, nil
+	}
+
+	resp, err := m.batchEmbedContents(ctx, model, misses, config)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Embeddings) != len(misses) {
+		return nil, fmt.Errorf("BatchEmbedContents: got %d embeddings for %d requests", len(resp.Embeddings), len(misses))
+	}
+	for i, e := range resp.Embeddings {
+		if e != nil {
+			cache.Set(missKeys[i], e.Values)
+		}
+	}
+	for i := range embeddings {
+		if embeddings[i] == nil {
+			embeddings[i] = resp.Embeddings[missIndex[keys[i]]]
+		}
+	}
+	return &BatchEmbedContentsResponse{Embeddings: embeddings}, nil
+}
+
+// List returns an iterator over the models available to the client. Pages are
+// fetched lazily as the iteration advances. config.PageSize controls the page size
+// and config.PageToken the first page to fetch.
+func (m Models) List(ctx context.Context, config *ListModelsConfig) iter.Seq2[*ModelInfo, error] {
+	var pageToken string
+	if config != nil {
+		pageToken = config.PageToken
+	}
+	return allPages(ctx, pageToken, func(ctx context.Context, pageToken string) ([]*ModelInfo, string, error) {
+		pageConfig := &ListModelsConfig{}
+		if config != nil {
+			*pageConfig = *config
+		}
+		pageConfig.PageToken = pageToken
+		response, err := m.list(ctx, pageConfig)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Models, response.NextPageToken, nil
+	})
+}
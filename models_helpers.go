@@ -14,18 +14,355 @@
 
 package genai
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Roles of the author of a Content.
+const (
+	RoleUser  = "user"
+	RoleModel = "model"
+)
+
+// MaxCandidateCount is the maximum value of GenerateContentConfig.CandidateCount
+// accepted by the API.
+const MaxCandidateCount = 8
+
+// MaxStopSequences is the maximum number of GenerateContentConfig.StopSequences
+// accepted by the API, and MaxStopSequenceLength the maximum length in characters of
+// each of them.
+const (
+	MaxStopSequences      = 5
+	MaxStopSequenceLength = 1000
+)
+
+// Response MIME types supported by GenerateContentConfig.ResponseMIMEType.
 const (
-	roleUser = "user"
+	MIMETypeText = "text/plain"
+	MIMETypeJSON = "application/json"
+	MIMETypeEnum = "text/x.enum"
 )
 
 // Text returns a slice of Content with a single Part with the given text.
 func Text(text string) []*Content {
 	return []*Content{{
-		Role:  roleUser,
+		Role:  RoleUser,
 		Parts: []*Part{{Text: text}},
 	}}
 }
 
+// Turn is a single text message of a conversation, sent by Role.
+type Turn struct {
+	Role string
+	Text string
+}
+
+// TurnSlice is a scripted conversation, for example to provide a few examples of
+// the expected answers. Use ToContents to pass it to GenerateContent.
+type TurnSlice []Turn
+
+// ToContents returns a Content with a single text Part for each turn. An empty role
+// is treated as user.
+func (ts TurnSlice) ToContents() []*Content {
+	contents := make([]*Content, len(ts))
+	for i, t := range ts {
+		role := t.Role
+		if role == "" {
+			role = RoleUser
+		}
+		contents[i] = &Content{Role: role, Parts: []*Part{{Text: t.Text}}}
+	}
+	return contents
+}
+
+// NewTurns returns the turns of a conversation alternating between the user and the
+// model, starting with the user. It returns an error if the number of texts is odd,
+// since each user turn must be answered by the model.
+func NewTurns(texts ...string) (TurnSlice, error) {
+	if len(texts)%2 != 0 {
+		return nil, fmt.Errorf("NewTurns: got %d texts, want an even number of alternating user and model texts", len(texts))
+	}
+	turns := make(TurnSlice, len(texts))
+	for i, text := range texts {
+		role := RoleUser
+		if i%2 == 1 {
+			role = RoleModel
+		}
+		turns[i] = Turn{Role: role, Text: text}
+	}
+	return turns, nil
+}
+
+// NewUserContent returns a Content with the user role and the given parts.
+func NewUserContent(parts ...*Part) *Content {
+	return &Content{Role: RoleUser, Parts: parts}
+}
+
+// NewModelContent returns a Content with the model role and the given parts.
+func NewModelContent(parts ...*Part) *Content {
+	return &Content{Role: RoleModel, Parts: parts}
+}
+
+// SetRole sets the role of c and returns c, for chaining with the constructors:
+//
+//	genai.NewUserContent(part).SetRole(genai.RoleModel)
+func (c *Content) SetRole(role string) *Content {
+	c.Role = role
+	return c
+}
+
+// ContentSlice is the history of a conversation. Use the Append methods to add turns
+// to it, and pass it as the contents of GenerateContent.
+type ContentSlice []*Content
+
+// AppendUserTurn appends a Content with the user role and the given parts.
+func (cs *ContentSlice) AppendUserTurn(parts ...*Part) {
+	*cs = append(*cs, NewUserContent(parts...))
+}
+
+// AppendModelTurn appends a Content with the model role and the given parts.
+func (cs *ContentSlice) AppendModelTurn(parts ...*Part) {
+	*cs = append(*cs, NewModelContent(parts...))
+}
+
+// LastRole returns the role of the last Content of cs. It returns false if cs is
+// empty or its last element is nil.
+func (cs ContentSlice) LastRole() (string, bool) {
+	if len(cs) == 0 || cs[len(cs)-1] == nil {
+		return "", false
+	}
+	return cs[len(cs)-1].Role, true
+}
+
+// Merge returns a new Content with the role of c and the parts of c followed by the
+// parts of other. Nil parts are dropped. Neither c nor other is modified; the
+// parts themselves are shared, not copied.
+func (c *Content) Merge(other *Content) *Content {
+	merged := &Content{}
+	for _, content := range []*Content{c, other} {
+		if content == nil {
+			continue
+		}
+		for _, p := range content.Parts {
+			if p != nil {
+				merged.Parts = append(merged.Parts, p)
+			}
+		}
+	}
+	if c != nil {
+		merged.Role = c.Role
+	}
+	return merged
+}
+
+// DeepCopy returns a copy of c whose parts are copied with [Part.DeepCopy]. It returns
+// nil if c is nil.
+func (c *Content) DeepCopy() *Content {
+	if c == nil {
+		return nil
+	}
+	copied := &Content{Role: c.Role}
+	if c.Parts != nil {
+		copied.Parts = make([]*Part, len(c.Parts))
+		for i, p := range c.Parts {
+			copied.Parts[i] = p.DeepCopy()
+		}
+	}
+	return copied
+}
+
+// SizeBytes returns the sum of the [Part.SizeBytes] of the parts of c. It returns 0
+// if c is nil.
+func (c *Content) SizeBytes() int64 {
+	if c == nil {
+		return 0
+	}
+	var size int64
+	for _, p := range c.Parts {
+		size += p.SizeBytes()
+	}
+	return size
+}
+
+// TotalSizeBytes returns the sum of the [Content.SizeBytes] of cs.
+func TotalSizeBytes(cs []*Content) int64 {
+	var size int64
+	for _, c := range cs {
+		size += c.SizeBytes()
+	}
+	return size
+}
+
+// EstimateTokens returns a rough estimate of the number of tokens of c, one token
+// per four characters of text, without calling the API. Function calls and
+// responses count the characters of their JSON arguments and responses. Media is
+// not counted; use Models.CountTokens for an exact count.
+func EstimateTokens(c *Content) int {
+	if c == nil {
+		return 0
+	}
+	chars := 0
+	for _, p := range c.Parts {
+		chars += partChars(p)
+	}
+	return (chars + 3) / 4
+}
+
+// partChars returns the number of characters of text of p counted by EstimateTokens.
+func partChars(p *Part) int {
+	if p == nil {
+		return 0
+	}
+	chars := utf8.RuneCountInString(p.Text)
+	if p.FunctionCall != nil {
+		b, _ := json.Marshal(p.FunctionCall.Args)
+		chars += len(p.FunctionCall.Name) + utf8.RuneCount(b)
+	}
+	if p.FunctionResponse != nil {
+		b, _ := json.Marshal(p.FunctionResponse.Response)
+		chars += len(p.FunctionResponse.Name) + utf8.RuneCount(b)
+	}
+	if p.ExecutableCode != nil {
+		chars += utf8.RuneCountInString(p.ExecutableCode.Code)
+	}
+	if p.CodeExecutionResult != nil {
+		chars += utf8.RuneCountInString(p.CodeExecutionResult.Output)
+	}
+	return chars
+}
+
+// Truncate returns a Content with the role of c and its first parts, removing whole
+// parts from the end until the [EstimateTokens] estimate is at most
+// maxTokenEstimate. The first part is always kept, even if it alone exceeds the
+// limit. c is not modified; the parts are shared, not copied. It returns nil if c
+// is nil.
+func (c *Content) Truncate(maxTokenEstimate int) *Content {
+	if c == nil {
+		return nil
+	}
+	chars := 0
+	for _, p := range c.Parts {
+		chars += partChars(p)
+	}
+	n := len(c.Parts)
+	for n > 1 && (chars+3)/4 > maxTokenEstimate {
+		n--
+		chars -= partChars(c.Parts[n])
+	}
+	return &Content{Role: c.Role, Parts: c.Parts[:n:n]}
+}
+
+// ToMap returns the JSON representation of c as a map, for example to store chat
+// history. Blob data is encoded as standard base64. Use [ContentFromMap] to convert
+// it back.
+func (c *Content) ToMap() (map[string]any, error) {
+	b, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("ToMap: error marshalling content: %w", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("ToMap: error unmarshalling content: %w", err)
+	}
+	return m, nil
+}
+
+// ContentFromMap returns the Content represented by m, as returned by
+// [Content.ToMap].
+func ContentFromMap(m map[string]any) (*Content, error) {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("ContentFromMap: error marshalling map: %w", err)
+	}
+	c := new(Content)
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("ContentFromMap: error unmarshalling content: %w", err)
+	}
+	return c, nil
+}
+
+// LoadSystemInstructionFromFile reads a system prompt from a .txt or .md file and
+// sets it as config.SystemInstruction. Markdown is sent as plain text. If the file
+// cannot be read, the returned error wraps the *os.PathError.
+func LoadSystemInstructionFromFile(path string, config *GenerateContentConfig) error {
+	if config == nil {
+		return fmt.Errorf("LoadSystemInstructionFromFile: config must not be nil")
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".txt", ".md":
+	default:
+		return fmt.Errorf("LoadSystemInstructionFromFile: unsupported file extension %q of %s, want .txt or .md", ext, path)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("LoadSystemInstructionFromFile: %w", err)
+	}
+	config.SystemInstruction = &Content{Parts: []*Part{{Text: string(b)}}}
+	return nil
+}
+
+// AddSafetySetting sets the threshold of a harm category and returns c, for chaining.
+// It replaces the setting of the category if c already has one, and appends one
+// otherwise. The SafetySettings slice is copied, so that configs sharing it, for
+// example with MergeConfigs, are not affected.
+func (c *GenerateContentConfig) AddSafetySetting(category HarmCategory, threshold HarmBlockThreshold) *GenerateContentConfig {
+	settings := slices.Clone(c.SafetySettings)
+	setting := &SafetySetting{Category: category, Threshold: threshold}
+	if i := slices.IndexFunc(settings, func(s *SafetySetting) bool { return s != nil && s.Category == category }); i >= 0 {
+		settings[i] = setting
+	} else {
+		settings = append(settings, setting)
+	}
+	c.SafetySettings = settings
+	return c
+}
+
+// WithAllSafetySettingsOff sets the threshold of the harassment, hate speech,
+// sexually explicit and dangerous content categories to BLOCK_NONE, and returns c.
+func (c *GenerateContentConfig) WithAllSafetySettingsOff() *GenerateContentConfig {
+	for _, category := range []HarmCategory{
+		HarmCategoryHarassment,
+		HarmCategoryHateSpeech,
+		HarmCategorySexuallyExplicit,
+		HarmCategoryDangerousContent,
+	} {
+		c.AddSafetySetting(category, HarmBlockThresholdBlockNone)
+	}
+	return c
+}
+
+// MergeConfigs returns a new config with the fields of base, replaced by the fields
+// of override that are set: non-nil pointers, slices and maps, non-empty strings and
+// true booleans. Slices and maps of override replace the ones of base entirely; an
+// empty non-nil slice clears the one of base. A nil base or override is treated as
+// an empty config. Neither input is modified, but the merged config shares the
+// values referenced by their pointers, slices and maps.
+func MergeConfigs(base, override *GenerateContentConfig) *GenerateContentConfig {
+	merged := &GenerateContentConfig{}
+	if base != nil {
+		*merged = *base
+	}
+	if override == nil {
+		return merged
+	}
+	dst := reflect.ValueOf(merged).Elem()
+	src := reflect.ValueOf(override).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if f := src.Field(i); !f.IsZero() {
+			dst.Field(i).Set(f)
+		}
+	}
+	return merged
+}
+
 func (c *GenerateContentConfig) setDefaults() {
 	if c == nil {
 		return
@@ -33,6 +370,7 @@ func (c *GenerateContentConfig) setDefaults() {
 	if c.SystemInstruction != nil && c.SystemInstruction.Role == "" {
 		c.SystemInstruction.setDefaults()
 	}
+	// A CandidateCount of 0 is not sent, so it defaults to 1 like an unset one.
 }
 
 func (c *Content) setDefaults() {
@@ -40,6 +378,122 @@ func (c *Content) setDefaults() {
 		return
 	}
 	if c.Role == "" {
-		c.Role = roleUser
+		c.Role = RoleUser
+	}
+}
+
+// ModelConstraints are the ranges of generation parameters supported by a model.
+type ModelConstraints struct {
+	// TemperatureRange is the inclusive range of GenerateContentConfig.Temperature,
+	// or nil if it is unknown.
+	TemperatureRange *[2]float32
+}
+
+// Constraints returns the ranges of generation parameters supported by the model.
+func (i *ModelInfo) Constraints() *ModelConstraints {
+	c := &ModelConstraints{}
+	if i != nil && i.MaxTemperature > 0 {
+		c.TemperatureRange = &[2]float32{0, float32(i.MaxTemperature)}
+	}
+	return c
+}
+
+// loadModelConstraints calls Models.Get for the model the first time it is used
+// with a Temperature, if ClientConfig.ValidateModelConstraints is set, so that
+// validateGenerateContent can check the temperature. If the model metadata is
+// unavailable, the model is remembered without constraints and is not validated.
+func (m Models) loadModelConstraints(ctx context.Context, model string, config *GenerateContentConfig) {
+	if !m.apiClient.clientConfig.ValidateModelConstraints || config == nil || config.Temperature == nil {
+		return
+	}
+	name, err := tModel(m.apiClient, model)
+	if err != nil {
+		return
+	}
+	if _, ok := m.apiClient.modelInfo.Load(name); ok {
+		return
+	}
+	if _, err := m.Get(ctx, model, nil); err != nil && ctx.Err() == nil {
+		m.apiClient.modelInfo.LoadOrStore(name, &ModelInfo{Name: name})
+	}
+}
+
+// validateGenerateContent rejects requests that the API would reject or truncate:
+// inline data larger than ClientConfig.MaxInlineDataBytes, invalid tools if
+// config.ValidateTools is set, Logprobs without ResponseLogprobs, and a
+// MaxOutputTokens above the output token limit or a Temperature outside the
+// temperature range of the model, if Models.Get was called for it.
+func (m Models) validateGenerateContent(model string, contents []*Content, config *GenerateContentConfig) error {
+	if limit := m.apiClient.clientConfig.MaxInlineDataBytes; limit > 0 {
+		for i, c := range contents {
+			if err := checkInlineDataSize(c, fmt.Sprintf("contents[%d]", i), limit); err != nil {
+				return err
+			}
+		}
+		if config != nil {
+			if err := checkInlineDataSize(config.SystemInstruction, "config.SystemInstruction", limit); err != nil {
+				return err
+			}
+		}
+	}
+
+	if config != nil && config.ValidateTools {
+		for i, tool := range config.Tools {
+			if err := tool.Validate(); err != nil {
+				return newInvalidArgumentError("config.Tools[%d]: %v", i, err)
+			}
+		}
+	}
+
+	if config != nil && config.Logprobs != nil && !config.ResponseLogprobs {
+		return newInvalidArgumentError("config.Logprobs requires config.ResponseLogprobs to be true")
+	}
+
+	if config == nil || (config.MaxOutputTokens == nil && config.Temperature == nil) {
+		return nil
+	}
+	name, err := tModel(m.apiClient, model)
+	if err != nil {
+		return nil
+	}
+	v, ok := m.apiClient.modelInfo.Load(name)
+	if !ok {
+		return nil
+	}
+	info := v.(*ModelInfo)
+	if config.MaxOutputTokens != nil {
+		limit := info.OutputTokenLimit
+		if limit > 0 && *config.MaxOutputTokens > limit {
+			return newInvalidArgumentError("config.MaxOutputTokens is %d, which exceeds the output token limit of %d of model %s", *config.MaxOutputTokens, limit, name)
+		}
+	}
+	if r := info.Constraints().TemperatureRange; config.Temperature != nil && r != nil {
+		if t := *config.Temperature; t < float64(r[0]) || t > float64(r[1]) {
+			return newInvalidArgumentError("config.Temperature is %g, which is outside the range [%g, %g] of model %s", t, r[0], r[1], name)
+		}
+	}
+	return nil
+}
+
+func checkInlineDataSize(c *Content, field string, limit int64) error {
+	if c == nil {
+		return nil
+	}
+	for i, p := range c.Parts {
+		if p == nil || p.InlineData == nil {
+			continue
+		}
+		if size := int64(len(p.InlineData.Data)); size > limit {
+			return newInvalidArgumentError("inline data of %s.Parts[%d] is %d bytes and exceeds the %s limit; use the Files API instead", field, i, size, formatByteSize(limit))
+		}
+	}
+	return nil
+}
+
+// formatByteSize formats n in MB if it is a whole number of megabytes.
+func formatByteSize(n int64) string {
+	if n%(1024*1024) == 0 {
+		return fmt.Sprintf("%d MB", n/(1024*1024))
 	}
+	return fmt.Sprintf("%d bytes", n)
 }
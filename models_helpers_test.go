@@ -15,6 +15,11 @@
 package genai
 
 import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -25,7 +30,7 @@ func TestContentHelpers(t *testing.T) {
 	t.Run("Text", func(t *testing.T) {
 		expected := []*Content{{
 			Parts: []*Part{{Text: "Hello"}},
-			Role:  roleUser,
+			Role:  RoleUser,
 		}}
 		got := Text("Hello")
 		if diff := cmp.Diff(got, expected); diff != "" {
@@ -33,8 +38,22 @@ func TestContentHelpers(t *testing.T) {
 		}
 	})
 
+	t.Run("Content_SetRole", func(t *testing.T) {
+		c := &Content{Parts: []*Part{{Text: "Hello"}}}
+		if got := c.SetRole(RoleModel); got != c {
+			t.Errorf("SetRole() = %p, want the receiver %p", got, c)
+		}
+		want := &Content{Parts: []*Part{{Text: "Hello"}}, Role: RoleModel}
+		if diff := cmp.Diff(want, c); diff != "" {
+			t.Errorf("SetRole mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(NewUserContent(), NewModelContent().SetRole(RoleUser)); diff != "" {
+			t.Errorf("chained SetRole mismatch (-want +got):\n%s", diff)
+		}
+	})
+
 	t.Run("Content_setDefaults", func(t *testing.T) {
-		expected := &Content{Parts: []*Part{{Text: "Hello"}}, Role: roleUser}
+		expected := &Content{Parts: []*Part{{Text: "Hello"}}, Role: RoleUser}
 		got := &Content{Parts: []*Part{{Text: "Hello"}}}
 		got.setDefaults()
 		if diff := cmp.Diff(got, expected); diff != "" {
@@ -43,11 +62,461 @@ func TestContentHelpers(t *testing.T) {
 	})
 
 	t.Run("GenerateContentConfig_setDefaults", func(t *testing.T) {
-		expected := &GenerateContentConfig{SystemInstruction: &Content{Parts: []*Part{{Text: "Hello"}}, Role: roleUser}}
+		expected := &GenerateContentConfig{SystemInstruction: &Content{Parts: []*Part{{Text: "Hello"}}, Role: RoleUser}}
 		got := &GenerateContentConfig{SystemInstruction: &Content{Parts: []*Part{{Text: "Hello"}}}}
 		got.setDefaults()
 		if diff := cmp.Diff(got, expected); diff != "" {
 			t.Errorf("GenerateContentConfig.setDefaults mismatch (-want +got):\n%s", diff)
 		}
 	})
+
+	t.Run("NewUserContent_NewModelContent", func(t *testing.T) {
+		if diff := cmp.Diff(&Content{Role: RoleUser, Parts: []*Part{{Text: "Hi"}}}, NewUserContent(&Part{Text: "Hi"})); diff != "" {
+			t.Errorf("NewUserContent mismatch (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(&Content{Role: RoleModel}, NewModelContent()); diff != "" {
+			t.Errorf("NewModelContent mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Content_Merge", func(t *testing.T) {
+		system := &Content{Role: RoleUser, Parts: make([]*Part, 1, 4)}
+		system.Parts[0] = &Part{Text: "Be brief."}
+		user := &Content{Role: RoleModel, Parts: []*Part{nil, {Text: "Hello"}}}
+
+		got := system.Merge(user)
+		want := &Content{Role: RoleUser, Parts: []*Part{{Text: "Be brief."}, {Text: "Hello"}}}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("Content.Merge mismatch (-want +got):\n%s", diff)
+		}
+		// The spare capacity of system.Parts must not be written to.
+		if diff := cmp.Diff([]*Part{{Text: "Be brief."}}, system.Parts); diff != "" {
+			t.Errorf("Content.Merge modified the receiver (-want +got):\n%s", diff)
+		}
+		if extra := system.Parts[:2]; extra[1] != nil {
+			t.Errorf("Content.Merge wrote %v past the parts of the receiver", extra[1])
+		}
+		if diff := cmp.Diff(&Content{Role: RoleModel, Parts: []*Part{nil, {Text: "Hello"}}}, user); diff != "" {
+			t.Errorf("Content.Merge modified the argument (-want +got):\n%s", diff)
+		}
+
+		if diff := cmp.Diff(&Content{Role: RoleUser, Parts: []*Part{{Text: "Be brief."}}}, system.Merge(nil)); diff != "" {
+			t.Errorf("Content.Merge(nil) mismatch (-want +got):\n%s", diff)
+		}
+		var empty *Content
+		if diff := cmp.Diff(&Content{Parts: []*Part{{Text: "Hello"}}}, empty.Merge(user)); diff != "" {
+			t.Errorf("nil Content.Merge mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Content_DeepCopy", func(t *testing.T) {
+		c := NewUserContent(NewPartFromText("Describe this image"), nil, NewPartFromBytes([]byte{1, 2, 3}, "image/png"))
+		got := c.DeepCopy()
+		if diff := cmp.Diff(c, got); diff != "" {
+			t.Fatalf("Content.DeepCopy mismatch (-want +got):\n%s", diff)
+		}
+		got.Role = RoleModel
+		got.Parts[0].Text = "changed"
+		got.Parts[2].InlineData.Data[0] = 9
+		want := NewUserContent(NewPartFromText("Describe this image"), nil, NewPartFromBytes([]byte{1, 2, 3}, "image/png"))
+		if diff := cmp.Diff(want, c); diff != "" {
+			t.Errorf("modifying the copy modified the original (-want +got):\n%s", diff)
+		}
+		var empty *Content
+		if empty.DeepCopy() != nil {
+			t.Errorf("nil Content.DeepCopy() = %v, want nil", empty.DeepCopy())
+		}
+	})
+
+	t.Run("ContentSlice", func(t *testing.T) {
+		var history ContentSlice
+		if role, ok := history.LastRole(); ok {
+			t.Errorf("LastRole() of empty slice = %q, true, want false", role)
+		}
+
+		history.AppendUserTurn(NewPartFromText("Hi"))
+		if role, ok := history.LastRole(); !ok || role != RoleUser {
+			t.Errorf("LastRole() = %q, %v, want %q, true", role, ok, RoleUser)
+		}
+		history.AppendModelTurn(NewPartFromText("Hello!"))
+		if role, ok := history.LastRole(); !ok || role != RoleModel {
+			t.Errorf("LastRole() = %q, %v, want %q, true", role, ok, RoleModel)
+		}
+		history.AppendUserTurn()
+
+		want := ContentSlice{
+			{Role: RoleUser, Parts: []*Part{{Text: "Hi"}}},
+			{Role: RoleModel, Parts: []*Part{{Text: "Hello!"}}},
+			{Role: RoleUser},
+		}
+		if diff := cmp.Diff(want, history); diff != "" {
+			t.Errorf("ContentSlice mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestContentMapRoundTrip(t *testing.T) {
+	tests := []struct {
+		desc    string
+		content *Content
+	}{
+		{desc: "empty", content: &Content{}},
+		{desc: "text", content: NewUserContent(&Part{Text: "Hello"}, &Part{Text: "world"})},
+		{desc: "binary inline data", content: NewUserContent(NewPartFromBytes([]byte{0x00, 0xff, 0xfe, 0x10}, "image/png"))},
+		{desc: "function call and response", content: NewModelContent(
+			NewPartFromFunctionCall("getWeather", map[string]any{"city": "Paris", "days": float64(3)}),
+			NewPartFromFunctionResponse("getWeather", map[string]any{"forecast": []any{"sun", "rain"}}),
+		)},
+		{desc: "file data and thought", content: NewModelContent(
+			NewPartFromURI("gs://bucket/file.pdf", "application/pdf"),
+			&Part{Text: "thinking", Thought: true},
+		)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			m, err := tt.content.ToMap()
+			if err != nil {
+				t.Fatalf("ToMap() failed: %v", err)
+			}
+			got, err := ContentFromMap(m)
+			if err != nil {
+				t.Fatalf("ContentFromMap() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.content, got); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("standard base64", func(t *testing.T) {
+		m, err := NewUserContent(NewPartFromBytes([]byte{0xfb, 0xff}, "image/png")).ToMap()
+		if err != nil {
+			t.Fatalf("ToMap() failed: %v", err)
+		}
+		want := map[string]any{
+			"role":  "user",
+			"parts": []any{map[string]any{"inlineData": map[string]any{"data": "+/8=", "mimeType": "image/png"}}},
+		}
+		if diff := cmp.Diff(want, m); diff != "" {
+			t.Errorf("ToMap() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("invalid map", func(t *testing.T) {
+		if _, err := ContentFromMap(map[string]any{"parts": "not a list"}); err == nil {
+			t.Errorf("ContentFromMap() succeeded, want error")
+		}
+	})
+}
+
+func TestTurns(t *testing.T) {
+	turns, err := NewTurns("What is 2+2?", "4", "And 3+3?", "6")
+	if err != nil {
+		t.Fatalf("NewTurns() failed: %v", err)
+	}
+	want := []*Content{
+		{Role: RoleUser, Parts: []*Part{{Text: "What is 2+2?"}}},
+		{Role: RoleModel, Parts: []*Part{{Text: "4"}}},
+		{Role: RoleUser, Parts: []*Part{{Text: "And 3+3?"}}},
+		{Role: RoleModel, Parts: []*Part{{Text: "6"}}},
+	}
+	if diff := cmp.Diff(want, turns.ToContents()); diff != "" {
+		t.Errorf("ToContents() mismatch (-want +got):\n%s", diff)
+	}
+
+	custom := TurnSlice{{Text: "Hi"}, {Role: RoleModel, Text: "Hello"}, {Role: RoleModel, Text: "How can I help?"}}
+	want = []*Content{
+		{Role: RoleUser, Parts: []*Part{{Text: "Hi"}}},
+		{Role: RoleModel, Parts: []*Part{{Text: "Hello"}}},
+		{Role: RoleModel, Parts: []*Part{{Text: "How can I help?"}}},
+	}
+	if diff := cmp.Diff(want, custom.ToContents()); diff != "" {
+		t.Errorf("ToContents() mismatch (-want +got):\n%s", diff)
+	}
+
+	if turns, err := NewTurns(); err != nil || len(turns) != 0 {
+		t.Errorf("NewTurns() = %v, %v, want no turns", turns, err)
+	}
+	if _, err := NewTurns("What is 2+2?", "4", "And 3+3?"); err == nil {
+		t.Errorf("NewTurns() with an odd number of texts succeeded, want error")
+	}
+}
+
+func TestLoadSystemInstructionFromFile(t *testing.T) {
+	createFile := func(t *testing.T, pattern, text string) string {
+		t.Helper()
+		f, err := os.CreateTemp(t.TempDir(), pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := f.WriteString(text); err != nil {
+			t.Fatal(err)
+		}
+		return f.Name()
+	}
+
+	for _, tt := range []struct{ pattern, text string }{
+		{pattern: "prompt-*.txt", text: "You are a helpful assistant.\n"},
+		{pattern: "prompt-*.md", text: "# Role\n\nYou are a **helpful** assistant.\n"},
+		{pattern: "prompt-*.MD", text: "Be brief."},
+	} {
+		t.Run(tt.pattern, func(t *testing.T) {
+			config := &GenerateContentConfig{Temperature: Ptr(0.5)}
+			if err := LoadSystemInstructionFromFile(createFile(t, tt.pattern, tt.text), config); err != nil {
+				t.Fatalf("LoadSystemInstructionFromFile() failed: %v", err)
+			}
+			want := &GenerateContentConfig{
+				Temperature:       Ptr(0.5),
+				SystemInstruction: &Content{Parts: []*Part{{Text: tt.text}}},
+			}
+			if diff := cmp.Diff(want, config); diff != "" {
+				t.Errorf("config mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		err := LoadSystemInstructionFromFile(filepath.Join(t.TempDir(), "missing.txt"), &GenerateContentConfig{})
+		var pathErr *os.PathError
+		if !errors.As(err, &pathErr) || !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("LoadSystemInstructionFromFile() error = %v, want a wrapped *os.PathError", err)
+		}
+	})
+
+	t.Run("unsupported extension", func(t *testing.T) {
+		config := &GenerateContentConfig{}
+		if err := LoadSystemInstructionFromFile(createFile(t, "prompt-*.json", `{}`), config); err == nil {
+			t.Error("LoadSystemInstructionFromFile() error = nil, want an error")
+		}
+		if config.SystemInstruction != nil {
+			t.Errorf("SystemInstruction = %v, want nil", config.SystemInstruction)
+		}
+	})
+
+	t.Run("nil config", func(t *testing.T) {
+		if err := LoadSystemInstructionFromFile(createFile(t, "prompt-*.txt", "Be brief."), nil); err == nil {
+			t.Error("LoadSystemInstructionFromFile() error = nil, want an error")
+		}
+	})
+}
+
+func TestEstimateTokens(t *testing.T) {
+	tests := []struct {
+		desc    string
+		content *Content
+		want    int
+	}{
+		{desc: "nil", content: nil, want: 0},
+		{desc: "no parts", content: &Content{Role: RoleUser}, want: 0},
+		{desc: "text", content: NewUserContent(NewPartFromText("12345678")), want: 2},
+		{desc: "rounds up", content: NewUserContent(NewPartFromText("123456789")), want: 3},
+		{desc: "counts characters, not bytes", content: NewUserContent(NewPartFromText("żółć")), want: 1},
+		{desc: "media is not counted", content: NewUserContent(NewPartFromBytes(make([]byte, 1024), "image/png")), want: 0},
+		{
+			desc:    "function call",
+			content: NewModelContent(NewPartFromFunctionCall("f", map[string]any{"a": 1})),
+			want:    2, // "f" and `{"a":1}`
+		},
+		{
+			desc:    "several parts",
+			content: NewUserContent(NewPartFromText("1234"), nil, NewPartFromText("5678")),
+			want:    2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := EstimateTokens(tt.content); got != tt.want {
+				t.Errorf("EstimateTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentTruncate(t *testing.T) {
+	parts := []*Part{
+		NewPartFromText(strings.Repeat("a", 40)), // 10 tokens
+		NewPartFromText(strings.Repeat("b", 40)),
+		NewPartFromText(strings.Repeat("c", 40)),
+	}
+	c := &Content{Role: RoleUser, Parts: parts}
+	tests := []struct {
+		desc      string
+		max       int
+		wantParts int
+	}{
+		{desc: "within limit", max: 30, wantParts: 3},
+		{desc: "drops the last part", max: 29, wantParts: 2},
+		{desc: "drops the last parts", max: 10, wantParts: 1},
+		{desc: "keeps the first part", max: 0, wantParts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := c.Truncate(tt.max)
+			want := &Content{Role: RoleUser, Parts: parts[:tt.wantParts]}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("Truncate(%d) mismatch (-want +got):\n%s", tt.max, diff)
+			}
+			if tt.wantParts > 1 && EstimateTokens(got) > tt.max {
+				t.Errorf("EstimateTokens(Truncate(%d)) = %d, want at most %d", tt.max, EstimateTokens(got), tt.max)
+			}
+		})
+	}
+
+	t.Run("does not modify the content", func(t *testing.T) {
+		got := c.Truncate(10)
+		got.Parts = append(got.Parts, NewPartFromText("d"))
+		if len(c.Parts) != 3 || c.Parts[1] != parts[1] {
+			t.Errorf("Truncate modified the content: %+v", c.Parts)
+		}
+	})
+
+	t.Run("nil and empty", func(t *testing.T) {
+		var nilContent *Content
+		if got := nilContent.Truncate(10); got != nil {
+			t.Errorf("Truncate() of nil = %+v, want nil", got)
+		}
+		if diff := cmp.Diff(&Content{Role: RoleModel}, (&Content{Role: RoleModel}).Truncate(10)); diff != "" {
+			t.Errorf("Truncate() of empty content mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestContentSizeBytes(t *testing.T) {
+	image := NewPartFromBytes(make([]byte, 1000), "image/png")
+	contents := []*Content{
+		NewUserContent(NewPartFromText("Describe"), nil, image),
+		nil,
+		NewModelContent(NewPartFromText("A cat.")),
+	}
+	if got, want := contents[0].SizeBytes(), int64(1008); got != want {
+		t.Errorf("SizeBytes() = %d, want %d", got, want)
+	}
+	if got := (*Content)(nil).SizeBytes(); got != 0 {
+		t.Errorf("SizeBytes() of nil = %d, want 0", got)
+	}
+	if got, want := TotalSizeBytes(contents), int64(1014); got != want {
+		t.Errorf("TotalSizeBytes() = %d, want %d", got, want)
+	}
+	if got := TotalSizeBytes(nil); got != 0 {
+		t.Errorf("TotalSizeBytes(nil) = %d, want 0", got)
+	}
+}
+
+func TestMergeConfigs(t *testing.T) {
+	newBase := func() *GenerateContentConfig {
+		return &GenerateContentConfig{
+			Temperature:    Ptr(0.2),
+			TopK:           Ptr(40.0),
+			StopSequences:  []string{"END"},
+			SafetySettings: []*SafetySetting{{Category: HarmCategoryHarassment, Threshold: HarmBlockThresholdBlockLowAndAbove}},
+			Labels:         map[string]string{"team": "search"},
+			ValidateTools:  true,
+		}
+	}
+	newOverride := func() *GenerateContentConfig {
+		return &GenerateContentConfig{
+			Temperature:      Ptr(0.9),
+			StopSequences:    []string{},
+			ResponseMIMEType: "application/json",
+			Labels:           map[string]string{"request": "42"},
+		}
+	}
+
+	tests := []struct {
+		desc           string
+		base, override *GenerateContentConfig
+		want           *GenerateContentConfig
+	}{
+		{desc: "both nil", want: &GenerateContentConfig{}},
+		{desc: "nil base", override: newOverride(), want: newOverride()},
+		{desc: "nil override", base: newBase(), want: newBase()},
+		{
+			desc:     "both set",
+			base:     newBase(),
+			override: newOverride(),
+			want: &GenerateContentConfig{
+				Temperature:      Ptr(0.9),
+				TopK:             Ptr(40.0),
+				StopSequences:    []string{},
+				SafetySettings:   []*SafetySetting{{Category: HarmCategoryHarassment, Threshold: HarmBlockThresholdBlockLowAndAbove}},
+				Labels:           map[string]string{"request": "42"},
+				ValidateTools:    true,
+				ResponseMIMEType: "application/json",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got := MergeConfigs(tt.base, tt.override)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("MergeConfigs() mismatch (-want +got):\n%s", diff)
+			}
+			if got == tt.base || got == tt.override {
+				t.Error("MergeConfigs() returned one of its inputs, want a new config")
+			}
+			if tt.base != nil {
+				if diff := cmp.Diff(newBase(), tt.base); diff != "" {
+					t.Errorf("MergeConfigs() modified base (-want +got):\n%s", diff)
+				}
+			}
+			if tt.override != nil {
+				if diff := cmp.Diff(newOverride(), tt.override); diff != "" {
+					t.Errorf("MergeConfigs() modified override (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestAddSafetySetting(t *testing.T) {
+	t.Run("appends and overwrites", func(t *testing.T) {
+		config := &GenerateContentConfig{}
+		got := config.
+			AddSafetySetting(HarmCategoryHarassment, HarmBlockThresholdBlockLowAndAbove).
+			AddSafetySetting(HarmCategoryHateSpeech, HarmBlockThresholdBlockOnlyHigh).
+			AddSafetySetting(HarmCategoryHarassment, HarmBlockThresholdBlockNone)
+		if got != config {
+			t.Error("AddSafetySetting() did not return the receiver")
+		}
+		want := []*SafetySetting{
+			{Category: HarmCategoryHarassment, Threshold: HarmBlockThresholdBlockNone},
+			{Category: HarmCategoryHateSpeech, Threshold: HarmBlockThresholdBlockOnlyHigh},
+		}
+		if diff := cmp.Diff(want, config.SafetySettings); diff != "" {
+			t.Errorf("SafetySettings mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("does not modify shared settings", func(t *testing.T) {
+		base := &GenerateContentConfig{SafetySettings: make([]*SafetySetting, 1, 4)}
+		base.SafetySettings[0] = &SafetySetting{Category: HarmCategoryHarassment, Threshold: HarmBlockThresholdBlockLowAndAbove}
+		merged := MergeConfigs(base, nil)
+		merged.AddSafetySetting(HarmCategoryHarassment, HarmBlockThresholdBlockNone).
+			AddSafetySetting(HarmCategoryHateSpeech, HarmBlockThresholdBlockNone)
+		want := []*SafetySetting{{Category: HarmCategoryHarassment, Threshold: HarmBlockThresholdBlockLowAndAbove}}
+		if diff := cmp.Diff(want, base.SafetySettings); diff != "" {
+			t.Errorf("base SafetySettings mismatch (-want +got):\n%s", diff)
+		}
+		if spare := base.SafetySettings[:2][1]; spare != nil {
+			t.Errorf("AddSafetySetting() appended %+v to the backing array of base", spare)
+		}
+	})
+
+	t.Run("WithAllSafetySettingsOff", func(t *testing.T) {
+		config := (&GenerateContentConfig{}).
+			AddSafetySetting(HarmCategoryDangerousContent, HarmBlockThresholdBlockLowAndAbove).
+			AddSafetySetting(HarmCategoryCivicIntegrity, HarmBlockThresholdBlockOnlyHigh).
+			WithAllSafetySettingsOff()
+		want := []*SafetySetting{
+			{Category: HarmCategoryDangerousContent, Threshold: HarmBlockThresholdBlockNone},
+			{Category: HarmCategoryCivicIntegrity, Threshold: HarmBlockThresholdBlockOnlyHigh},
+			{Category: HarmCategoryHarassment, Threshold: HarmBlockThresholdBlockNone},
+			{Category: HarmCategoryHateSpeech, Threshold: HarmBlockThresholdBlockNone},
+			{Category: HarmCategorySexuallyExplicit, Threshold: HarmBlockThresholdBlockNone},
+		}
+		if diff := cmp.Diff(want, config.SafetySettings); diff != "" {
+			t.Errorf("SafetySettings mismatch (-want +got):\n%s", diff)
+		}
+	})
 }
@@ -0,0 +1,250 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// ModelsInterface is the API of [Models]. Client.Models is a Models by default, and
+// can be replaced with a [MockModels] to test code that uses the client.
+type ModelsInterface interface {
+	GenerateContent(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) (*GenerateContentResponse, error)
+	GenerateContentStream(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) iter.Seq2[*GenerateContentResponse, error]
+	GenerateContentStreamChan(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) (<-chan *GenerateContentResponse, <-chan error)
+	GenerateContentBatch(ctx context.Context, model string, requests []*BatchRequest, config *BatchConfig) ([]*BatchResult, error)
+	GenerateContentWithTools(ctx context.Context, model string, contents []*Content, tools map[string]ToolFunc, config *GenerateContentConfig) (*GenerateContentResponse, error)
+	StartChat(model string, config *GenerateContentConfig) *Chat
+	GenerateImages(ctx context.Context, model string, prompt string, config *GenerateImagesConfig) (*GenerateImagesResponse, error)
+	CountTokens(ctx context.Context, model string, contents []*Content, config *CountTokensConfig) (*CountTokensResponse, error)
+	ComputeTokens(ctx context.Context, model string, contents []*Content, config *ComputeTokensConfig) (*ComputeTokensResponse, error)
+	EmbedContent(ctx context.Context, model string, content *Content, config *EmbedContentConfig) (*EmbedContentResponse, error)
+	BatchEmbedContents(ctx context.Context, model string, requests []*EmbedContentRequest, config *BatchEmbedContentsConfig) (*BatchEmbedContentsResponse, error)
+	Get(ctx context.Context, model string, config *GetModelConfig) (*ModelInfo, error)
+	Update(ctx context.Context, model string, config *UpdateModelConfig) (*ModelInfo, error)
+	Delete(ctx context.Context, model string, config *DeleteModelConfig) (*DeleteModelResponse, error)
+	List(ctx context.Context, config *ListModelsConfig) iter.Seq2[*ModelInfo, error]
+}
+
+var (
+	_ ModelsInterface = Models{}
+	_ ModelsInterface = (*MockModels)(nil)
+)
+
+// MockModelsCall is a call recorded by [MockModels].
+type MockModelsCall struct {
+	// Method is the name of the called method, for example "GenerateContent".
+	Method string
+	// Model is the model argument, if the method has one.
+	Model string
+	// Contents are the contents sent, if the method sends contents.
+	Contents []*Content
+	// Config is the config argument of the content generation methods.
+	Config *GenerateContentConfig
+}
+
+// MockModels is a [ModelsInterface] that sends no requests, for testing code that
+// uses the API. It records the calls and returns the response or the error set with
+// SetResponse and SetError. The other methods return the error, or an empty
+// response. The zero value is ready to use and returns an empty response. It is safe
+// for concurrent use.
+type MockModels struct {
+	mu       sync.Mutex
+	response *GenerateContentResponse
+	err      error
+	calls    []MockModelsCall
+}
+
+// SetResponse sets the response returned by later calls, and clears the error.
+func (m *MockModels) SetResponse(resp *GenerateContentResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.response = resp
+	m.err = nil
+}
+
+// SetError sets the error returned by later calls, and clears the response.
+func (m *MockModels) SetError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.response = nil
+	m.err = err
+}
+
+// Calls returns the calls recorded so far, in order.
+func (m *MockModels) Calls() []MockModelsCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MockModelsCall(nil), m.calls...)
+}
+
+// record records a call and returns the result set for it.
+func (m *MockModels) record(method, model string, contents []*Content, config *GenerateContentConfig) (*GenerateContentResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, MockModelsCall{Method: method, Model: model, Contents: contents, Config: config})
+	if m.err != nil {
+		return nil, m.err
+	}
+	if m.response == nil {
+		return &GenerateContentResponse{}, nil
+	}
+	return m.response, nil
+}
+
+// recordOther records a call of a method other than content generation and returns
+// the error set on m, or ctx.Err() if ctx is done.
+func (m *MockModels) recordOther(ctx context.Context, method, model string, contents []*Content) error {
+	_, err := m.record(method, model, contents, nil)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// GenerateContent records the call and returns the response or the error set on m.
+// It returns ctx.Err() if ctx is done.
+func (m *MockModels) GenerateContent(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) (*GenerateContentResponse, error) {
+	resp, err := m.record("GenerateContent", model, contents, config)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return resp, err
+}
+
+// GenerateContentStream records the call and returns an iterator that yields the
+// response or the error set on m once. It yields ctx.Err() if ctx is done.
+func (m *MockModels) GenerateContentStream(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) iter.Seq2[*GenerateContentResponse, error] {
+	resp, err := m.record("GenerateContentStream", model, contents, config)
+	return func(yield func(*GenerateContentResponse, error) bool) {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			yield(nil, ctxErr)
+			return
+		}
+		yield(resp, err)
+	}
+}
+
+// GenerateContentStreamChan is like [Models.GenerateContentStreamChan], with the
+// stream of m.GenerateContentStream.
+func (m *MockModels) GenerateContentStreamChan(ctx context.Context, model string, contents []*Content, config *GenerateContentConfig) (<-chan *GenerateContentResponse, <-chan error) {
+	return generateContentStreamChan(ctx, m, model, contents, config)
+}
+
+// GenerateContentBatch is like [Models.GenerateContentBatch], and records a
+// GenerateContent call for each request.
+func (m *MockModels) GenerateContentBatch(ctx context.Context, model string, requests []*BatchRequest, config *BatchConfig) ([]*BatchResult, error) {
+	return generateContentBatch(ctx, m, model, requests, config)
+}
+
+// GenerateContentWithTools is like [Models.GenerateContentWithTools], and records a
+// GenerateContent call for each request.
+func (m *MockModels) GenerateContentWithTools(ctx context.Context, model string, contents []*Content, tools map[string]ToolFunc, config *GenerateContentConfig) (*GenerateContentResponse, error) {
+	return generateContentWithTools(ctx, m, model, contents, tools, config)
+}
+
+// StartChat starts a conversation whose messages are sent with m.GenerateContent.
+func (m *MockModels) StartChat(model string, config *GenerateContentConfig) *Chat {
+	return &Chat{models: m, model: model, config: config}
+}
+
+// GenerateImages records the call and returns the error set on m, or an empty
+// response.
+func (m *MockModels) GenerateImages(ctx context.Context, model string, prompt string, config *GenerateImagesConfig) (*GenerateImagesResponse, error) {
+	if err := m.recordOther(ctx, "GenerateImages", model, []*Content{{Role: RoleUser, Parts: []*Part{{Text: prompt}}}}); err != nil {
+		return nil, err
+	}
+	return &GenerateImagesResponse{}, nil
+}
+
+// CountTokens records the call and returns the error set on m, or an empty response.
+func (m *MockModels) CountTokens(ctx context.Context, model string, contents []*Content, config *CountTokensConfig) (*CountTokensResponse, error) {
+	if err := m.recordOther(ctx, "CountTokens", model, contents); err != nil {
+		return nil, err
+	}
+	return &CountTokensResponse{}, nil
+}
+
+// ComputeTokens records the call and returns the error set on m, or an empty
+// response.
+func (m *MockModels) ComputeTokens(ctx context.Context, model string, contents []*Content, config *ComputeTokensConfig) (*ComputeTokensResponse, error) {
+	if err := m.recordOther(ctx, "ComputeTokens", model, contents); err != nil {
+		return nil, err
+	}
+	return &ComputeTokensResponse{}, nil
+}
+
+// EmbedContent records the call and returns the error set on m, or an empty
+// response.
+func (m *MockModels) EmbedContent(ctx context.Context, model string, content *Content, config *EmbedContentConfig) (*EmbedContentResponse, error) {
+	if err := m.recordOther(ctx, "EmbedContent", model, []*Content{content}); err != nil {
+		return nil, err
+	}
+	return &EmbedContentResponse{}, nil
+}
+
+// BatchEmbedContents records the call and returns the error set on m, or an empty
+// response.
+func (m *MockModels) BatchEmbedContents(ctx context.Context, model string, requests []*EmbedContentRequest, config *BatchEmbedContentsConfig) (*BatchEmbedContentsResponse, error) {
+	var contents []*Content
+	for _, r := range requests {
+		if r != nil {
+			contents = append(contents, r.Content)
+		}
+	}
+	if err := m.recordOther(ctx, "BatchEmbedContents", model, contents); err != nil {
+		return nil, err
+	}
+	return &BatchEmbedContentsResponse{}, nil
+}
+
+// Get records the call and returns the error set on m, or a ModelInfo with the name
+// of model.
+func (m *MockModels) Get(ctx context.Context, model string, config *GetModelConfig) (*ModelInfo, error) {
+	if err := m.recordOther(ctx, "Get", model, nil); err != nil {
+		return nil, err
+	}
+	return &ModelInfo{Name: model}, nil
+}
+
+// Update records the call and returns the error set on m, or a ModelInfo with the
+// name of model.
+func (m *MockModels) Update(ctx context.Context, model string, config *UpdateModelConfig) (*ModelInfo, error) {
+	if err := m.recordOther(ctx, "Update", model, nil); err != nil {
+		return nil, err
+	}
+	return &ModelInfo{Name: model}, nil
+}
+
+// Delete records the call and returns the error set on m, or an empty response.
+func (m *MockModels) Delete(ctx context.Context, model string, config *DeleteModelConfig) (*DeleteModelResponse, error) {
+	if err := m.recordOther(ctx, "Delete", model, nil); err != nil {
+		return nil, err
+	}
+	return &DeleteModelResponse{}, nil
+}
+
+// List records the call and returns an iterator that yields the error set on m
+// once, or no models.
+func (m *MockModels) List(ctx context.Context, config *ListModelsConfig) iter.Seq2[*ModelInfo, error] {
+	err := m.recordOther(ctx, "List", "", nil)
+	return func(yield func(*ModelInfo, error) bool) {
+		if err != nil {
+			yield(nil, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// summarize is user code under test: it depends on ModelsInterface only.
+func summarize(ctx context.Context, models ModelsInterface, text string) (string, error) {
+	resp, err := models.GenerateContent(ctx, "gemini-2.0-flash", Text("Summarize: "+text), &GenerateContentConfig{Temperature: Ptr(0.2)})
+	if err != nil {
+		return "", fmt.Errorf("summarize: %w", err)
+	}
+	return resp.Text()
+}
+
+// streamSummary is like summarize but streams the response.
+func streamSummary(ctx context.Context, models ModelsInterface, text string) (string, error) {
+	var b strings.Builder
+	for resp, err := range models.GenerateContentStream(ctx, "gemini-2.0-flash", Text("Summarize: "+text), nil) {
+		if err != nil {
+			return "", err
+		}
+		t, err := resp.Text()
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(t)
+	}
+	return b.String(), nil
+}
+
+func TestMockModels(t *testing.T) {
+	ctx := context.Background()
+	resp := &GenerateContentResponse{Candidates: []*Candidate{{Content: NewModelContent(NewPartFromText("Short."))}}}
+
+	t.Run("response", func(t *testing.T) {
+		mock := &MockModels{}
+		mock.SetResponse(resp)
+		got, err := summarize(ctx, mock, "a long text")
+		if err != nil {
+			t.Fatalf("summarize() failed: %v", err)
+		}
+		if got != "Short." {
+			t.Errorf("summarize() = %q, want %q", got, "Short.")
+		}
+		want := []MockModelsCall{{
+			Method:   "GenerateContent",
+			Model:    "gemini-2.0-flash",
+			Contents: Text("Summarize: a long text"),
+			Config:   &GenerateContentConfig{Temperature: Ptr(0.2)},
+		}}
+		if diff := cmp.Diff(want, mock.Calls()); diff != "" {
+			t.Errorf("Calls() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mock := &MockModels{}
+		mock.SetResponse(resp)
+		mock.SetError(ErrQuotaExceeded)
+		if _, err := summarize(ctx, mock, "text"); !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("summarize() error = %v, want %v", err, ErrQuotaExceeded)
+		}
+		if _, err := streamSummary(ctx, mock, "text"); !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("streamSummary() error = %v, want %v", err, ErrQuotaExceeded)
+		}
+		if got := len(mock.Calls()); got != 2 {
+			t.Errorf("len(Calls()) = %d, want 2", got)
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		mock := &MockModels{}
+		mock.SetResponse(resp)
+		got, err := streamSummary(ctx, mock, "text")
+		if err != nil || got != "Short." {
+			t.Errorf("streamSummary() = %q, %v, want %q, nil", got, err, "Short.")
+		}
+		if calls := mock.Calls(); len(calls) != 1 || calls[0].Method != "GenerateContentStream" {
+			t.Errorf("Calls() = %+v, want one GenerateContentStream call", calls)
+		}
+	})
+
+	t.Run("zero value", func(t *testing.T) {
+		got, err := summarize(ctx, &MockModels{}, "text")
+		if err != nil || got != "" {
+			t.Errorf("summarize() = %q, %v, want empty text", got, err)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := summarize(ctx, &MockModels{}, "text"); !errors.Is(err, context.Canceled) {
+			t.Errorf("summarize() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
+
+func TestMockModelsThroughClient(t *testing.T) {
+	ctx := context.Background()
+	mock := &MockModels{}
+	mock.SetResponse(&GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{{Text: "Hi"}}}}}})
+	client := &Client{Models: mock}
+
+	chat := client.Models.StartChat("gemini-2.0-flash", nil)
+	if _, err := chat.SendMessage(ctx, NewPartFromText("Hello")); err != nil {
+		t.Fatalf("SendMessage() failed: %v", err)
+	}
+	results, err := client.Models.GenerateContentBatch(ctx, "gemini-2.0-flash", []*BatchRequest{{ID: "a", Contents: Text("one")}}, nil)
+	if err != nil || len(results) != 1 || results[0].Err != nil {
+		t.Fatalf("GenerateContentBatch() = %+v, %v, want one successful result", results, err)
+	}
+	if _, err := client.Models.CountTokens(ctx, "gemini-2.0-flash", Text("count"), nil); err != nil {
+		t.Errorf("CountTokens() failed: %v", err)
+	}
+	if info, err := client.Models.Get(ctx, "gemini-2.0-flash", nil); err != nil || info.Name != "gemini-2.0-flash" {
+		t.Errorf("Get() = %+v, %v, want the model info of gemini-2.0-flash", info, err)
+	}
+	for _, err := range client.Models.List(ctx, nil) {
+		t.Errorf("List() yielded error %v, want no models", err)
+	}
+
+	var methods []string
+	for _, call := range mock.Calls() {
+		methods = append(methods, call.Method)
+	}
+	want := []string{"GenerateContent", "GenerateContent", "CountTokens", "Get", "List"}
+	if diff := cmp.Diff(want, methods); diff != "" {
+		t.Errorf("Calls() methods mismatch (-want +got):\n%s", diff)
+	}
+
+	mock.SetError(ErrNotFound)
+	if _, err := client.Models.EmbedContent(ctx, "text-embedding-004", Text("hi")[0], nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("EmbedContent() error = %v, want %v", err, ErrNotFound)
+	}
+	for _, err := range client.Models.List(ctx, nil) {
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("List() error = %v, want %v", err, ErrNotFound)
+		}
+	}
+}
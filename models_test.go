@@ -15,17 +15,23 @@
 package genai
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 )
@@ -118,6 +124,57 @@ func fakeClient(ctx context.Context, t *testing.T, server *httptest.Server, back
 	return client
 }
 
+// newTestAPIClient returns an apiClient that sends requests to the given test server.
+// Unlike fakeClient it does not look up credentials, so it works for both backends
+// without any environment setup.
+func newTestAPIClient(ts *httptest.Server, backend Backend) *apiClient {
+	apiVersion := "v1beta"
+	if backend == BackendVertexAI {
+		apiVersion = "v1beta1"
+	}
+	cc := &ClientConfig{
+		Backend:     backend,
+		HTTPOptions: HTTPOptions{BaseURL: ts.URL, APIVersion: apiVersion},
+		HTTPClient:  ts.Client(),
+	}
+	if backend == BackendVertexAI {
+		cc.Project = "test-project"
+		cc.Location = "test-location"
+	} else {
+		cc.APIKey = "test-api-key"
+	}
+	return &apiClient{clientConfig: cc}
+}
+
+// newRecordingTestServer returns a server that records the contents of each request
+// and answers it with reply, which is passed the number of the request, starting at
+// 0, and its contents. reply may be called concurrently. The returned function
+// returns the contents recorded so far.
+func newRecordingTestServer(t *testing.T, reply func(w http.ResponseWriter, r *http.Request, n int, contents []*Content)) (*httptest.Server, func() [][]*Content) {
+	t.Helper()
+	var mu sync.Mutex
+	var gotContents [][]*Content
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request struct {
+			Contents []*Content `json:"contents"`
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			t.Errorf("error unmarshalling request: %v", err)
+		}
+		mu.Lock()
+		n := len(gotContents)
+		gotContents = append(gotContents, request.Contents)
+		mu.Unlock()
+		reply(w, r, n, request.Contents)
+	}))
+	return ts, func() [][]*Content {
+		mu.Lock()
+		defer mu.Unlock()
+		return slices.Clone(gotContents)
+	}
+}
+
 func vertexAIFakeClient(ctx context.Context, t *testing.T) *Client {
 	t.Helper()
 	vertexServer := setupTestServer(t, BackendVertexAI)
@@ -368,3 +425,1793 @@ func TestModelsGenerateContentAudio(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateContentPartMediaResolution(t *testing.T) {
+	contents := []*Content{{Role: RoleUser, Parts: []*Part{
+		{InlineData: &Blob{Data: []byte("thumbnail"), MIMEType: "image/png"}},
+		{FileData: &FileData{FileURI: "gs://bucket/diagram.png", MIMEType: "image/png"}, MediaResolution: MediaResolutionHigh},
+		{Text: "describe", MediaResolution: MediaResolutionHigh},
+	}}}
+	config := &GenerateContentConfig{MediaResolution: MediaResolutionLow}
+
+	parameterMap := make(map[string]any)
+	deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": contents, "config": config}, &parameterMap)
+
+	t.Run("VertexAI", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendVertexAI}}
+		body, err := generateContentParametersToVertex(ac, parameterMap, nil)
+		if err != nil {
+			t.Fatalf("generateContentParametersToVertex() failed: %v", err)
+		}
+		if got := getValueByPath(body, []string{"generationConfig", "mediaResolution"}); got != string(MediaResolutionLow) {
+			t.Errorf("generationConfig.mediaResolution = %v, want %v", got, MediaResolutionLow)
+		}
+		parts := body["contents"].([]map[string]any)[0]["parts"].([]map[string]any)
+		want := []any{nil, string(MediaResolutionHigh), nil}
+		for i, p := range parts {
+			if got := p["mediaResolution"]; got != want[i] {
+				t.Errorf("parts[%d].mediaResolution = %v, want %v", i, got, want[i])
+			}
+		}
+	})
+
+	t.Run("GeminiAPI", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}
+		partMap := map[string]any{"inlineData": map[string]any{"data": "AA=="}, "mediaResolution": string(MediaResolutionHigh)}
+		if _, err := partToMldev(ac, partMap, nil); err == nil {
+			t.Errorf("partToMldev() succeeded, want error for unsupported mediaResolution")
+		}
+	})
+}
+
+func TestGenerateContentMultiSpeakerConfig(t *testing.T) {
+	config := &GenerateContentConfig{
+		ResponseModalities: []string{"AUDIO"},
+		SpeechConfig: &SpeechConfig{
+			MultiSpeakerConfig: &MultiSpeakerVoiceConfig{
+				SpeakerVoiceConfigs: []*SpeakerVoiceConfig{
+					{Speaker: "Alice", VoiceConfig: &VoiceConfig{PrebuiltVoiceConfig: &PrebuiltVoiceConfig{VoiceName: "Kore"}}},
+					{Speaker: "Bob", VoiceConfig: &VoiceConfig{PrebuiltVoiceConfig: &PrebuiltVoiceConfig{VoiceName: "Puck"}}},
+				},
+			},
+		},
+	}
+
+	parameterMap := make(map[string]any)
+	deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": Text("hello"), "config": config}, &parameterMap)
+
+	t.Run("VertexAI", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendVertexAI}}
+		body, err := generateContentParametersToVertex(ac, parameterMap, nil)
+		if err != nil {
+			t.Fatalf("generateContentParametersToVertex() failed: %v", err)
+		}
+		got := getValueByPath(body, []string{"generationConfig", "speechConfig"})
+		want := map[string]any{
+			"multiSpeakerVoiceConfig": map[string]any{
+				"speakerVoiceConfigs": []map[string]any{
+					{"speaker": "Alice", "voiceConfig": map[string]any{"prebuiltVoiceConfig": map[string]any{"voiceName": "Kore"}}},
+					{"speaker": "Bob", "voiceConfig": map[string]any{"prebuiltVoiceConfig": map[string]any{"voiceName": "Puck"}}},
+				},
+			},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("generationConfig.speechConfig mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("GeminiAPI", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}
+		if _, err := generateContentParametersToMldev(ac, parameterMap, nil); err == nil {
+			t.Errorf("generateContentParametersToMldev() succeeded, want error for unsupported multiSpeakerVoiceConfig")
+		}
+	})
+}
+
+func TestModelsGenerateImages(t *testing.T) {
+	ctx := context.Background()
+	config := &GenerateImagesConfig{
+		NumberOfImages:    Ptr[int64](2),
+		OutputMIMEType:    "image/jpeg",
+		NegativePrompt:    "red",
+		AspectRatio:       "1:1",
+		PersonGeneration:  PersonGenerationDontAllow,
+		SafetyFilterLevel: SafetyFilterLevelBlockOnlyHigh,
+		IncludeRAIReason:  true,
+	}
+	wantParameters := `"parameters":{"aspectRatio":"1:1","includeRaiReason":true,"negativePrompt":"red","outputOptions":{"mimeType":"image/jpeg"},"personGeneration":"DONT_ALLOW","safetySetting":"BLOCK_ONLY_HIGH","sampleCount":2}`
+	responseBody := `{"predictions":[{"bytesBase64Encoded":"aW1hZ2U=","mimeType":"image/jpeg"},{"raiFilteredReason":"filtered"}]}`
+	want := &GenerateImagesResponse{GeneratedImages: []*GeneratedImage{
+		{Image: &Image{ImageBytes: []byte("image"), MIMEType: "image/jpeg"}},
+		{Image: &Image{}, RAIFilteredReason: "filtered"},
+	}}
+	tests := []struct {
+		desc     string
+		backend  Backend
+		config   *GenerateImagesConfig
+		wantPath string
+		wantBody string
+		want     *GenerateImagesResponse
+		wantErr  bool
+	}{
+		{
+			desc:     "Gemini API",
+			backend:  BackendGeminiAPI,
+			config:   config,
+			wantPath: "/v1beta/models/imagen-3.0-generate-002:predict",
+			wantBody: `{"instances":{"prompt":"a blue circle"},` + wantParameters + `}`,
+			want:     want,
+		},
+		{
+			desc:     "Vertex AI",
+			backend:  BackendVertexAI,
+			config:   config,
+			wantPath: "/v1beta1/projects/test-project/locations/test-location/publishers/google/models/imagen-3.0-generate-002:predict",
+			wantBody: `{"instances":{"prompt":"a blue circle"},` + wantParameters + `}`,
+			want:     want,
+		},
+		{
+			desc:    "seed unsupported in Gemini API",
+			backend: BackendGeminiAPI,
+			config:  &GenerateImagesConfig{Seed: Ptr[int64](1)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if diff := cmp.Diff(tt.wantBody, strings.TrimSpace(string(body))); diff != "" {
+					t.Errorf("request body mismatch (-want +got):\n%s", diff)
+				}
+				w.Write([]byte(responseBody))
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, tt.backend)}
+			got, err := m.GenerateImages(ctx, "imagen-3.0-generate-002", "a blue circle", tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenerateImages() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("GenerateImages() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateContentValidateResponseSchema(t *testing.T) {
+	ctx := context.Background()
+	schema := &Schema{
+		Type: TypeObject,
+		Properties: map[string]*Schema{
+			"name":  {Type: TypeString},
+			"count": {Type: TypeInteger},
+			"tags":  {Type: TypeArray, Items: &Schema{Type: TypeString, Enum: []string{"a", "b"}}},
+			"note":  {Type: TypeString, Nullable: true},
+		},
+		Required: []string{"name", "count"},
+	}
+	tests := []struct {
+		desc     string
+		text     string
+		validate bool
+		wantErr  string
+	}{
+		{desc: "valid", text: `{"name":"x","count":2,"tags":["a","b"],"note":null}`, validate: true},
+		{desc: "validation disabled", text: `not json`, validate: false},
+		{desc: "invalid JSON", text: `{"name":`, validate: true, wantErr: "not valid JSON"},
+		{desc: "missing required property", text: `{"name":"x"}`, validate: true, wantErr: `$ is missing required property "count"`},
+		{desc: "wrong type", text: `{"name":"x","count":"2"}`, validate: true, wantErr: "$.count is STRING, want INTEGER"},
+		{desc: "not an integer", text: `{"name":"x","count":2.5}`, validate: true, wantErr: "$.count is 2.5, want INTEGER"},
+		{desc: "enum mismatch", text: `{"name":"x","count":2,"tags":["a","c"]}`, validate: true, wantErr: `$.tags[1] is "c"`},
+		{desc: "null not allowed", text: `{"name":null,"count":2}`, validate: true, wantErr: "$.name is null"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				resp := map[string]any{"candidates": []any{map[string]any{"content": map[string]any{"role": "model", "parts": []any{map[string]any{"text": tt.text}}}}}}
+				json.NewEncoder(w).Encode(resp)
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+			config := &GenerateContentConfig{ResponseMIMEType: "application/json", ResponseSchema: schema, ValidateResponseSchema: tt.validate}
+			resp, err := m.GenerateContent(ctx, "gemini-2.0-flash", Text("hello"), config)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("GenerateContent() failed: %v", err)
+				}
+				if resp == nil {
+					t.Errorf("GenerateContent() returned no response")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("GenerateContent() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateContentStreamCancel(t *testing.T) {
+	disconnected := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"first"}]}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+		// Hold the stream open until the client goes away.
+		<-r.Context().Done()
+		close(disconnected)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+	var texts []string
+	var gotErr error
+	for resp, err := range m.GenerateContentStream(ctx, "gemini-2.0-flash", Text("hello"), nil) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		text, _ := resp.Text()
+		texts = append(texts, text)
+		cancel()
+	}
+
+	if diff := cmp.Diff([]string{"first"}, texts); diff != "" {
+		t.Errorf("GenerateContentStream() texts mismatch (-want +got):\n%s", diff)
+	}
+	if !errors.Is(gotErr, context.Canceled) {
+		t.Errorf("GenerateContentStream() error = %v, want %v", gotErr, context.Canceled)
+	}
+	select {
+	case <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Errorf("the HTTP connection was not closed after the context was canceled")
+	}
+}
+
+func TestGenerateContentStreamCancelPromptly(t *testing.T) {
+	chunk := func(text string) string {
+		return `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"` + text + `"}]}}]}` + "\n\n"
+	}
+
+	t.Run("buffered events", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The events arrive together, so they are all buffered by the first read.
+			fmt.Fprint(w, chunk("first")+chunk("second")+chunk("third"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		var texts []string
+		var gotErr error
+		for resp, err := range m.GenerateContentStream(ctx, "gemini-2.0-flash", Text("hello"), nil) {
+			if err != nil {
+				gotErr = err
+				break
+			}
+			text, _ := resp.Text()
+			texts = append(texts, text)
+			cancel()
+		}
+		if diff := cmp.Diff([]string{"first"}, texts); diff != "" {
+			t.Errorf("GenerateContentStream() texts mismatch (-want +got):\n%s", diff)
+		}
+		if !errors.Is(gotErr, context.Canceled) {
+			t.Errorf("GenerateContentStream() error = %v, want %v", gotErr, context.Canceled)
+		}
+	})
+
+	t.Run("blocked read", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, chunk("first"))
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}))
+		defer ts.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		var gotErr error
+		var canceled time.Time
+		for _, err := range m.GenerateContentStream(ctx, "gemini-2.0-flash", Text("hello"), nil) {
+			if err != nil {
+				gotErr = err
+				break
+			}
+			// Cancel while the next read waits for data that never comes.
+			canceled = time.Now()
+			time.AfterFunc(20*time.Millisecond, cancel)
+		}
+		if !errors.Is(gotErr, context.Canceled) {
+			t.Errorf("GenerateContentStream() error = %v, want %v", gotErr, context.Canceled)
+		}
+		if elapsed := time.Since(canceled); elapsed > time.Second {
+			t.Errorf("GenerateContentStream() returned %v after the cancellation, want promptly", elapsed)
+		}
+	})
+}
+
+func TestGenerateContentThinkingConfig(t *testing.T) {
+	config := &GenerateContentConfig{ThinkingConfig: &ThinkingConfig{IncludeThoughts: true, ThinkingBudget: Ptr[int64](1024)}}
+	parameterMap := make(map[string]any)
+	deepMarshal(map[string]any{"model": "gemini-2.0-flash-thinking-exp", "contents": Text("hello"), "config": config}, &parameterMap)
+	want := map[string]any{"includeThoughts": true, "thinkingBudget": float64(1024)}
+
+	tests := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	}{
+		{BackendGeminiAPI, generateContentParametersToMldev},
+		{BackendVertexAI, generateContentParametersToVertex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend.String(), func(t *testing.T) {
+			ac := &apiClient{clientConfig: &ClientConfig{Backend: tt.backend}}
+			body, err := tt.converter(ac, parameterMap, nil)
+			if err != nil {
+				t.Fatalf("converter failed: %v", err)
+			}
+			got := getValueByPath(body, []string{"generationConfig", "thinkingConfig"})
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("generationConfig.thinkingConfig mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateContentValidateTools(t *testing.T) {
+	ctx := context.Background()
+	invalid := []*Tool{{FunctionDeclarations: []*FunctionDeclaration{{Name: "f", Parameters: &Schema{Type: TypeObject, Required: []string{"x"}}}}}}
+	tests := []struct {
+		desc     string
+		validate bool
+		wantErr  bool
+	}{
+		{desc: "validation enabled", validate: true, wantErr: true},
+		{desc: "validation disabled", validate: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			requests := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]}}]}`)
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+			_, err := m.GenerateContent(ctx, "gemini-2.0-flash", Text("hello"), &GenerateContentConfig{Tools: invalid, ValidateTools: tt.validate})
+			if !tt.wantErr {
+				if err != nil || requests != 1 {
+					t.Errorf("GenerateContent() = %v after %d requests, want no error after 1 request", err, requests)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidArgument) {
+				t.Errorf("GenerateContent() error = %v, want %v", err, ErrInvalidArgument)
+			}
+			if requests != 0 {
+				t.Errorf("GenerateContent() sent %d requests, want 0", requests)
+			}
+		})
+	}
+}
+
+func TestGenerateContentCandidateCount(t *testing.T) {
+	converters := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	}{
+		{BackendGeminiAPI, generateContentParametersToMldev},
+		{BackendVertexAI, generateContentParametersToVertex},
+	}
+	for _, count := range []int64{0, 1, MaxCandidateCount, MaxCandidateCount + 1} {
+		for _, c := range converters {
+			t.Run(fmt.Sprintf("%d/%s", count, c.backend), func(t *testing.T) {
+				parameterMap := make(map[string]any)
+				config := &GenerateContentConfig{CandidateCount: Ptr(count)}
+				deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": Text("hello"), "config": config}, &parameterMap)
+				ac := &apiClient{clientConfig: &ClientConfig{Backend: c.backend}}
+				body, err := c.converter(ac, parameterMap, nil)
+				if count > MaxCandidateCount {
+					if !errors.Is(err, ErrInvalidArgument) {
+						t.Errorf("converter error = %v, want %v", err, ErrInvalidArgument)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("converter failed: %v", err)
+				}
+				// 0 is not sent, so the API default of 1 candidate applies.
+				var want any
+				if count > 0 {
+					want = float64(count)
+				}
+				if got := getValueByPath(body, []string{"generationConfig", "candidateCount"}); got != want {
+					t.Errorf("generationConfig.candidateCount = %v, want %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateContentStopSequences(t *testing.T) {
+	converters := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	}{
+		{BackendGeminiAPI, generateContentParametersToMldev},
+		{BackendVertexAI, generateContentParametersToVertex},
+	}
+	tests := []struct {
+		desc          string
+		stopSequences []string
+		wantErr       bool
+	}{
+		{desc: "none", stopSequences: nil},
+		{desc: "maximum", stopSequences: []string{"a", "b", "c", "d", "e"}},
+		{desc: "too many", stopSequences: []string{"a", "b", "c", "d", "e", "f"}, wantErr: true},
+		{desc: "empty sequence", stopSequences: []string{"END", ""}, wantErr: true},
+		{desc: "longest sequence", stopSequences: []string{strings.Repeat("ż", MaxStopSequenceLength)}},
+		{desc: "sequence too long", stopSequences: []string{strings.Repeat("a", MaxStopSequenceLength+1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		for _, c := range converters {
+			t.Run(fmt.Sprintf("%s/%s", tt.desc, c.backend), func(t *testing.T) {
+				parameterMap := make(map[string]any)
+				config := &GenerateContentConfig{StopSequences: tt.stopSequences}
+				deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": Text("hello"), "config": config}, &parameterMap)
+				ac := &apiClient{clientConfig: &ClientConfig{Backend: c.backend}}
+				body, err := c.converter(ac, parameterMap, nil)
+				if tt.wantErr {
+					if !errors.Is(err, ErrInvalidArgument) {
+						t.Errorf("converter error = %v, want %v", err, ErrInvalidArgument)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("converter failed: %v", err)
+				}
+				var want any
+				if len(tt.stopSequences) > 0 {
+					var seqs []any
+					for _, s := range tt.stopSequences {
+						seqs = append(seqs, s)
+					}
+					want = seqs
+				}
+				if diff := cmp.Diff(want, getValueByPath(body, []string{"generationConfig", "stopSequences"})); diff != "" {
+					t.Errorf("generationConfig.stopSequences mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateContentResponseMIMEType(t *testing.T) {
+	converters := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	}{
+		{BackendGeminiAPI, generateContentParametersToMldev},
+		{BackendVertexAI, generateContentParametersToVertex},
+	}
+	tests := []struct {
+		desc     string
+		mimeType string
+		allow    bool
+		wantErr  bool
+	}{
+		{desc: "text", mimeType: MIMETypeText},
+		{desc: "json", mimeType: MIMETypeJSON},
+		{desc: "enum", mimeType: MIMETypeEnum},
+		{desc: "unknown", mimeType: "text/html", wantErr: true},
+		{desc: "unknown allowed", mimeType: "text/html", allow: true},
+	}
+	for _, tt := range tests {
+		for _, c := range converters {
+			t.Run(fmt.Sprintf("%s/%s", tt.desc, c.backend), func(t *testing.T) {
+				parameterMap := make(map[string]any)
+				config := &GenerateContentConfig{ResponseMIMEType: tt.mimeType, AllowCustomMIMEType: tt.allow}
+				deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": Text("hello"), "config": config}, &parameterMap)
+				ac := &apiClient{clientConfig: &ClientConfig{Backend: c.backend}}
+				body, err := c.converter(ac, parameterMap, nil)
+				if tt.wantErr {
+					var clientErr ClientError
+					if !errors.As(err, &clientErr) || !errors.Is(err, ErrInvalidArgument) {
+						t.Errorf("converter error = %v, want a ClientError matching %v", err, ErrInvalidArgument)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("converter failed: %v", err)
+				}
+				if diff := cmp.Diff(tt.mimeType, getValueByPath(body, []string{"generationConfig", "responseMimeType"})); diff != "" {
+					t.Errorf("generationConfig.responseMimeType mismatch (-want +got):\n%s", diff)
+				}
+				if got := getValueByPath(body, []string{"generationConfig", "allowCustomMimeType"}); got != nil {
+					t.Errorf("generationConfig.allowCustomMimeType = %v, want it not sent", got)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateContentRoutingConfig(t *testing.T) {
+	config := &GenerateContentConfig{RoutingConfig: &GenerationConfigRoutingConfig{
+		AutoMode: &GenerationConfigRoutingConfigAutoRoutingMode{ModelRoutingPreference: "BALANCED"},
+	}}
+	parameterMap := make(map[string]any)
+	deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": Text("hello"), "config": config}, &parameterMap)
+
+	tests := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+		want      any
+		wantLog   string
+	}{
+		// The Gemini API has no model router, the field is dropped with a warning.
+		{BackendGeminiAPI, generateContentParametersToMldev, nil, "routing_config parameter is not supported in Gemini API"},
+		{BackendVertexAI, generateContentParametersToVertex, map[string]any{"autoMode": map[string]any{"modelRoutingPreference": "BALANCED"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend.String(), func(t *testing.T) {
+			var logs bytes.Buffer
+			logger := slog.New(slog.NewTextHandler(&logs, nil))
+			ac := &apiClient{clientConfig: &ClientConfig{Backend: tt.backend, Logger: logger}}
+			body, err := tt.converter(ac, parameterMap, nil)
+			if err != nil {
+				t.Fatalf("converter failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, getValueByPath(body, []string{"generationConfig", "routingConfig"})); diff != "" {
+				t.Errorf("generationConfig.routingConfig mismatch (-want +got):\n%s", diff)
+			}
+			if tt.wantLog == "" && logs.Len() > 0 {
+				t.Errorf("converter logged %q, want nothing", logs.String())
+			}
+			if !strings.Contains(logs.String(), tt.wantLog) {
+				t.Errorf("converter logged %q, want a warning containing %q", logs.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestGenerateContentAutoToolConfig(t *testing.T) {
+	ctx := context.Background()
+	tools := []*Tool{{FunctionDeclarations: []*FunctionDeclaration{{Name: "get_weather"}}}}
+	tests := []struct {
+		desc   string
+		config *GenerateContentConfig
+		want   any
+	}{
+		{
+			desc:   "defaults to AUTO",
+			config: &GenerateContentConfig{Tools: tools},
+			want:   map[string]any{"functionCallingConfig": map[string]any{"mode": "AUTO"}},
+		},
+		{
+			desc:   "opted out",
+			config: &GenerateContentConfig{Tools: tools, DisableAutoToolConfig: true},
+		},
+		{
+			desc:   "explicit config kept",
+			config: &GenerateContentConfig{Tools: tools, ToolConfig: &ToolConfig{FunctionCallingConfig: &FunctionCallingConfig{Mode: FunctionCallingConfigModeAny}}},
+			want:   map[string]any{"functionCallingConfig": map[string]any{"mode": "ANY"}},
+		},
+		{
+			desc:   "no function declarations",
+			config: &GenerateContentConfig{Tools: []*Tool{{GoogleSearch: &GoogleSearch{}}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var got any
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]any
+				json.NewDecoder(r.Body).Decode(&body)
+				got = body["toolConfig"]
+				fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]}}]}`)
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+			toolConfig := tt.config.ToolConfig
+			if _, err := m.GenerateContent(ctx, "gemini-2.0-flash", Text("hello"), tt.config); err != nil {
+				t.Fatalf("GenerateContent() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("toolConfig mismatch (-want +got):\n%s", diff)
+			}
+			if tt.config.ToolConfig != toolConfig {
+				t.Errorf("GenerateContent() set config.ToolConfig to %v, want the config unmodified", tt.config.ToolConfig)
+			}
+		})
+	}
+
+	t.Run("shared config", func(t *testing.T) {
+		var mu sync.Mutex
+		var bodies []map[string]any
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			bodies = append(bodies, body)
+			mu.Unlock()
+			fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"hi"}]}}]}`)
+		}))
+		defer ts.Close()
+
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		config := &GenerateContentConfig{Tools: tools}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := m.GenerateContent(ctx, "gemini-2.0-flash", Text("hello"), config); err != nil {
+					t.Errorf("GenerateContent() failed: %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if config.ToolConfig != nil {
+			t.Errorf("GenerateContent() set config.ToolConfig to %v, want the config unmodified", config.ToolConfig)
+		}
+
+		// Once the tools are cleared, no function calling config is sent.
+		config.Tools = nil
+		if _, err := m.GenerateContent(ctx, "gemini-2.0-flash", Text("hello"), config); err != nil {
+			t.Fatalf("GenerateContent() failed: %v", err)
+		}
+		if got := bodies[len(bodies)-1]["toolConfig"]; got != nil {
+			t.Errorf("toolConfig = %v after clearing Tools, want none", got)
+		}
+	})
+}
+
+func TestGenerateContentContentRoles(t *testing.T) {
+	tests := []struct {
+		role    string
+		wantErr bool
+	}{
+		{role: RoleUser},
+		{role: RoleModel},
+		{role: ""},
+		{role: "system", wantErr: true},
+		{role: "User", wantErr: true},
+	}
+	converters := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	}{
+		{BackendGeminiAPI, generateContentParametersToMldev},
+		{BackendVertexAI, generateContentParametersToVertex},
+	}
+	for _, tt := range tests {
+		for _, c := range converters {
+			t.Run(fmt.Sprintf("%q/%s", tt.role, c.backend), func(t *testing.T) {
+				contents := []*Content{NewUserContent(NewPartFromText("hi")), {Role: tt.role, Parts: []*Part{{Text: "hello"}}}}
+				parameterMap := make(map[string]any)
+				deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": contents}, &parameterMap)
+				ac := &apiClient{clientConfig: &ClientConfig{Backend: c.backend}}
+				_, err := c.converter(ac, parameterMap, nil)
+				if tt.wantErr {
+					if !errors.Is(err, ErrInvalidArgument) || !strings.Contains(err.Error(), "contents[1]") {
+						t.Errorf("converter error = %v, want an invalid argument error for contents[1]", err)
+					}
+					return
+				}
+				if err != nil {
+					t.Errorf("converter failed: %v", err)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateContentLabels(t *testing.T) {
+	config := &GenerateContentConfig{Labels: map[string]string{"team": "search", "env": "prod"}}
+	parameterMap := make(map[string]any)
+	deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": Text("hello"), "config": config}, &parameterMap)
+
+	t.Run("Vertex AI", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendVertexAI}}
+		body, err := generateContentParametersToVertex(ac, parameterMap, nil)
+		if err != nil {
+			t.Fatalf("converter failed: %v", err)
+		}
+		want := map[string]any{"team": "search", "env": "prod"}
+		if diff := cmp.Diff(want, body["labels"]); diff != "" {
+			t.Errorf("labels mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("Gemini API", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}
+		if _, err := generateContentParametersToMldev(ac, parameterMap, nil); err == nil || !strings.Contains(err.Error(), "labels parameter is not supported") {
+			t.Errorf("converter error = %v, want labels not supported", err)
+		}
+	})
+}
+
+func TestGenerateContentAudioTimestamp(t *testing.T) {
+	converters := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	}{
+		{BackendGeminiAPI, generateContentParametersToMldev},
+		{BackendVertexAI, generateContentParametersToVertex},
+	}
+	tests := []struct {
+		desc           string
+		audioTimestamp bool
+		want           any
+	}{
+		{desc: "true", audioTimestamp: true, want: true},
+		{desc: "false is omitted", audioTimestamp: false, want: nil},
+	}
+	for _, c := range converters {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("%s %s", c.backend, tt.desc), func(t *testing.T) {
+				config := &GenerateContentConfig{AudioTimestamp: tt.audioTimestamp}
+				parameterMap := make(map[string]any)
+				deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": Text("hello"), "config": config}, &parameterMap)
+				ac := &apiClient{clientConfig: &ClientConfig{Backend: c.backend}}
+				body, err := c.converter(ac, parameterMap, nil)
+				if err != nil {
+					t.Fatalf("converter failed: %v", err)
+				}
+				if diff := cmp.Diff(tt.want, getValueByPath(body, []string{"generationConfig", "audioTimestamp"})); diff != "" {
+					t.Errorf("audioTimestamp mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateContentLogprobs(t *testing.T) {
+	ctx := context.Background()
+	var gotBody map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi"}]},"avgLogprobs":-0.5,` +
+			`"logprobsResult":{"chosenCandidates":[{"token":"Hi","tokenId":42,"logProbability":-0.5}],` +
+			`"topCandidates":[{"candidates":[{"token":"Hi","tokenId":42,"logProbability":-0.5},{"token":"Hello","tokenId":7,"logProbability":-1.5}]}]}}]}`))
+	}))
+	defer ts.Close()
+	m := Models{apiClient: newTestAPIClient(ts, BackendVertexAI)}
+
+	got, err := m.GenerateContent(ctx, "gemini-2.0-flash", Text("hello"), &GenerateContentConfig{ResponseLogprobs: true, Logprobs: Ptr[int64](2)})
+	if err != nil {
+		t.Fatalf("GenerateContent() failed: %v", err)
+	}
+	wantConfig := map[string]any{"responseLogprobs": true, "logprobs": float64(2)}
+	gotConfig, _ := gotBody["generationConfig"].(map[string]any)
+	for k, want := range wantConfig {
+		if diff := cmp.Diff(want, gotConfig[k]); diff != "" {
+			t.Errorf("generationConfig.%s mismatch (-want +got):\n%s", k, diff)
+		}
+	}
+	want := &LogprobsResult{
+		ChosenCandidates: []*LogprobsResultCandidate{{Token: "Hi", TokenID: Ptr[int64](42), LogProbability: Ptr(-0.5)}},
+		TopCandidates: []*LogprobsResultTopCandidates{{Candidates: []*LogprobsResultCandidate{
+			{Token: "Hi", TokenID: Ptr[int64](42), LogProbability: Ptr(-0.5)},
+			{Token: "Hello", TokenID: Ptr[int64](7), LogProbability: Ptr(-1.5)},
+		}}},
+	}
+	if diff := cmp.Diff(want, got.Candidates[0].LogprobsResult); diff != "" {
+		t.Errorf("LogprobsResult mismatch (-want +got):\n%s", diff)
+	}
+
+	_, err = m.GenerateContent(ctx, "gemini-2.0-flash", Text("hello"), &GenerateContentConfig{Logprobs: Ptr[int64](2)})
+	if !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("GenerateContent() error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestGenerateContentSystemInstructionText(t *testing.T) {
+	tests := []struct {
+		desc    string
+		config  *GenerateContentConfig
+		want    any
+		wantErr bool
+	}{
+		{
+			desc:   "text only",
+			config: &GenerateContentConfig{SystemInstructionText: "Be brief."},
+			want:   map[string]any{"parts": []map[string]any{{"text": "Be brief."}}},
+		},
+		{
+			desc:   "content only",
+			config: &GenerateContentConfig{SystemInstruction: &Content{Parts: []*Part{{Text: "Be verbose."}}}},
+			want:   map[string]any{"parts": []map[string]any{{"text": "Be verbose."}}},
+		},
+		{
+			desc: "both set",
+			config: &GenerateContentConfig{
+				SystemInstruction:     &Content{Parts: []*Part{{Text: "Be verbose."}}},
+				SystemInstructionText: "Be brief.",
+			},
+			wantErr: true,
+		},
+	}
+	converters := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	}{
+		{BackendGeminiAPI, generateContentParametersToMldev},
+		{BackendVertexAI, generateContentParametersToVertex},
+	}
+	for _, tt := range tests {
+		for _, c := range converters {
+			t.Run(tt.desc+"/"+c.backend.String(), func(t *testing.T) {
+				parameterMap := make(map[string]any)
+				deepMarshal(map[string]any{"model": "gemini-2.0-flash", "contents": Text("hello"), "config": tt.config}, &parameterMap)
+				ac := &apiClient{clientConfig: &ClientConfig{Backend: c.backend}}
+				body, err := c.converter(ac, parameterMap, nil)
+				if tt.wantErr {
+					if err == nil {
+						t.Fatalf("converter succeeded, want error")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("converter failed: %v", err)
+				}
+				if diff := cmp.Diff(tt.want, getValueByPath(body, []string{"systemInstruction"})); diff != "" {
+					t.Errorf("systemInstruction mismatch (-want +got):\n%s", diff)
+				}
+				if got := getValueByPath(body, []string{"generationConfig", "systemInstructionText"}); got != nil {
+					t.Errorf("generationConfig.systemInstructionText = %v, want unset", got)
+				}
+			})
+		}
+	}
+}
+
+func TestModelsCountTokens(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc         string
+		backend      Backend
+		config       *CountTokensConfig
+		wantPath     string
+		wantBody     string
+		responseBody string
+		want         *CountTokensResponse
+		wantErr      bool
+	}{
+		{
+			desc:         "Gemini API",
+			backend:      BackendGeminiAPI,
+			wantPath:     "/v1beta/models/gemini-2.0-flash:countTokens",
+			wantBody:     `{"contents":[{"parts":[{"text":"hello"}],"role":"user"}]}`,
+			responseBody: `{"totalTokens":5,"cachedContentTokenCount":2}`,
+			want:         &CountTokensResponse{TotalTokens: 5, CachedContentTokenCount: Ptr[int64](2)},
+		},
+		{
+			desc:         "Vertex AI",
+			backend:      BackendVertexAI,
+			config:       &CountTokensConfig{SystemInstruction: &Content{Parts: []*Part{{Text: "be brief"}}}},
+			wantPath:     "/v1beta1/projects/test-project/locations/test-location/publishers/google/models/gemini-2.0-flash:countTokens",
+			wantBody:     `{"contents":[{"parts":[{"text":"hello"}],"role":"user"}],"systemInstruction":{"parts":[{"text":"be brief"}]}}`,
+			responseBody: `{"totalTokens":7}`,
+			want:         &CountTokensResponse{TotalTokens: 7},
+		},
+		{
+			desc:    "generation config unsupported in Gemini API",
+			backend: BackendGeminiAPI,
+			config:  &CountTokensConfig{GenerationConfig: &GenerationConfig{Temperature: Ptr(0.5)}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if diff := cmp.Diff(tt.wantBody, strings.TrimSpace(string(body))); diff != "" {
+					t.Errorf("request body mismatch (-want +got):\n%s", diff)
+				}
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, tt.backend)}
+			got, err := m.CountTokens(ctx, "gemini-2.0-flash", Text("hello"), tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CountTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("CountTokens() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModelsComputeTokens(t *testing.T) {
+	ctx := context.Background()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wantPath := "/v1beta1/projects/test-project/locations/test-location/publishers/google/models/gemini-2.0-flash:computeTokens"
+		if r.URL.Path != wantPath {
+			t.Errorf("request path = %q, want %q", r.URL.Path, wantPath)
+		}
+		body, _ := io.ReadAll(r.Body)
+		wantBody := `{"contents":[{"parts":[{"text":"hello world"}],"role":"user"}]}`
+		if diff := cmp.Diff(wantBody, strings.TrimSpace(string(body))); diff != "" {
+			t.Errorf("request body mismatch (-want +got):\n%s", diff)
+		}
+		w.Write([]byte(`{"tokensInfo":[{"role":"user","tokenIds":["17534","2134"],"tokens":["aGVsbG8=","IHdvcmxk"]}]}`))
+	}))
+	defer ts.Close()
+
+	m := Models{apiClient: newTestAPIClient(ts, BackendVertexAI)}
+	got, err := m.ComputeTokens(ctx, "gemini-2.0-flash", Text("hello world"), nil)
+	if err != nil {
+		t.Fatalf("ComputeTokens() failed: %v", err)
+	}
+	want := &ComputeTokensResponse{TokensInfo: []*TokensInfo{{
+		Role:     RoleUser,
+		TokenIDs: []int64{17534, 2134},
+		Tokens:   [][]byte{[]byte("hello"), []byte(" world")},
+	}}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ComputeTokens() mismatch (-want +got):\n%s", diff)
+	}
+
+	m = Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+	if _, err := m.ComputeTokens(ctx, "gemini-2.0-flash", Text("hello world"), nil); !errors.Is(err, ErrInvalidArgument) {
+		t.Errorf("ComputeTokens() error = %v, want %v", err, ErrInvalidArgument)
+	}
+}
+
+func TestModelsEmbedContent(t *testing.T) {
+	ctx := context.Background()
+	content := &Content{Parts: []*Part{{Text: "What is the meaning of life?"}}}
+	config := &EmbedContentConfig{TaskType: "RETRIEVAL_DOCUMENT", Title: "life", OutputDimensionality: Ptr[int64](3)}
+
+	tests := []struct {
+		desc         string
+		backend      Backend
+		wantPath     string
+		wantBody     string
+		responseBody string
+		want         *EmbedContentResponse
+	}{
+		{
+			desc:         "Gemini API",
+			backend:      BackendGeminiAPI,
+			wantPath:     "/v1beta/models/text-embedding-004:embedContent",
+			wantBody:     `{"content":{"parts":[{"text":"What is the meaning of life?"}]},"outputDimensionality":3,"taskType":"RETRIEVAL_DOCUMENT","title":"life"}`,
+			responseBody: `{"embedding":{"values":[0.1,0.2,0.3]}}`,
+			want:         &EmbedContentResponse{Embedding: &ContentEmbedding{Values: []float32{0.1, 0.2, 0.3}}},
+		},
+		{
+			desc:         "Vertex AI",
+			backend:      BackendVertexAI,
+			wantPath:     "/v1beta1/projects/test-project/locations/test-location/publishers/google/models/text-embedding-004:predict",
+			wantBody:     `{"instances":[{"content":"What is the meaning of life?","task_type":"RETRIEVAL_DOCUMENT","title":"life"}],"parameters":{"outputDimensionality":3}}`,
+			responseBody: `{"predictions":[{"embeddings":{"values":[0.1,0.2,0.3],"statistics":{"truncated":false,"token_count":7}}}]}`,
+			want: &EmbedContentResponse{Embedding: &ContentEmbedding{
+				Values:     []float32{0.1, 0.2, 0.3},
+				Statistics: &ContentEmbeddingStatistics{TokenCount: 7},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if diff := cmp.Diff(tt.wantBody, strings.TrimSpace(string(body))); diff != "" {
+					t.Errorf("request body mismatch (-want +got):\n%s", diff)
+				}
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, tt.backend)}
+			got, err := m.EmbedContent(ctx, "text-embedding-004", content, config)
+			if err != nil {
+				t.Fatalf("EmbedContent() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("EmbedContent() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("Vertex AI rejects non-text parts", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendVertexAI}}
+		m := Models{apiClient: ac}
+		_, err := m.EmbedContent(ctx, "text-embedding-004", &Content{Parts: []*Part{{InlineData: &Blob{Data: []byte("a"), MIMEType: "image/png"}}}}, nil)
+		if err == nil {
+			t.Errorf("EmbedContent() succeeded, want error")
+		}
+	})
+}
+
+func TestModelsBatchEmbedContents(t *testing.T) {
+	ctx := context.Background()
+	requests := []*EmbedContentRequest{
+		{Content: &Content{Parts: []*Part{{Text: "first"}}}, Config: &EmbedContentConfig{TaskType: "RETRIEVAL_QUERY"}},
+		{Content: &Content{Parts: []*Part{{Text: "second"}}}},
+	}
+
+	tests := []struct {
+		desc         string
+		backend      Backend
+		wantPath     string
+		wantBody     string
+		responseBody string
+	}{
+		{
+			desc:         "Gemini API",
+			backend:      BackendGeminiAPI,
+			wantPath:     "/v1beta/models/text-embedding-004:batchEmbedContents",
+			wantBody:     `{"requests":[{"content":{"parts":[{"text":"first"}]},"model":"models/text-embedding-004","taskType":"RETRIEVAL_QUERY"},{"content":{"parts":[{"text":"second"}]},"model":"models/text-embedding-004"}]}`,
+			responseBody: `{"embeddings":[{"values":[1]},{"values":[2]}]}`,
+		},
+		{
+			desc:         "Vertex AI",
+			backend:      BackendVertexAI,
+			wantPath:     "/v1beta1/projects/test-project/locations/test-location/publishers/google/models/text-embedding-004:predict",
+			wantBody:     `{"instances":[{"content":"first","task_type":"RETRIEVAL_QUERY"},{"content":"second"}]}`,
+			responseBody: `{"predictions":[{"embeddings":{"values":[1]}},{"embeddings":{"values":[2]}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if diff := cmp.Diff(tt.wantBody, strings.TrimSpace(string(body))); diff != "" {
+					t.Errorf("request body mismatch (-want +got):\n%s", diff)
+				}
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, tt.backend)}
+			got, err := m.BatchEmbedContents(ctx, "text-embedding-004", requests, nil)
+			if err != nil {
+				t.Fatalf("BatchEmbedContents() failed: %v", err)
+			}
+			want := &BatchEmbedContentsResponse{Embeddings: []*ContentEmbedding{{Values: []float32{1}}, {Values: []float32{2}}}}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("BatchEmbedContents() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("Vertex AI rejects mixed output dimensionality", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendVertexAI}}
+		m := Models{apiClient: ac}
+		_, err := m.BatchEmbedContents(ctx, "text-embedding-004", []*EmbedContentRequest{
+			{Content: &Content{Parts: []*Part{{Text: "a"}}}, Config: &EmbedContentConfig{OutputDimensionality: Ptr[int64](3)}},
+			{Content: &Content{Parts: []*Part{{Text: "b"}}}, Config: &EmbedContentConfig{OutputDimensionality: Ptr[int64](5)}},
+		}, nil)
+		if err == nil {
+			t.Errorf("BatchEmbedContents() succeeded, want error")
+		}
+	})
+}
+
+func TestGenerateContentHTTPOptionsOverride(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("timeout", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Consume the body so that the server notices when the client goes away.
+			io.ReadAll(r.Body)
+			select {
+			case <-r.Context().Done():
+			case <-time.After(2 * time.Second):
+			}
+			w.Write([]byte(`{}`))
+		}))
+		defer ts.Close()
+
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		config := &GenerateContentConfig{HTTPOptions: &HTTPOptions{Timeout: 20}}
+		start := time.Now()
+		_, err := m.GenerateContent(ctx, "gemini-2.0-flash", Text("hello"), config)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("GenerateContent() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("GenerateContent() took %v, want per-request timeout to apply", elapsed)
+		}
+
+		for _, err := range m.GenerateContentStream(ctx, "gemini-2.0-flash", Text("hello"), config) {
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("GenerateContentStream() error = %v, want %v", err, context.DeadlineExceeded)
+			}
+		}
+	})
+
+	t.Run("base URL and API version", func(t *testing.T) {
+		clientServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("request sent to client-level base URL: %s", r.URL)
+		}))
+		defer clientServer.Close()
+		requestServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if want := "/v1alpha/models/gemini-2.0-flash:generateContent"; r.URL.Path != want {
+				t.Errorf("request path = %q, want %q", r.URL.Path, want)
+			}
+			w.Write([]byte(`{}`))
+		}))
+		defer requestServer.Close()
+
+		m := Models{apiClient: newTestAPIClient(clientServer, BackendGeminiAPI)}
+		config := &GenerateContentConfig{HTTPOptions: &HTTPOptions{BaseURL: requestServer.URL, APIVersion: "v1alpha"}}
+		if _, err := m.GenerateContent(ctx, "gemini-2.0-flash", Text("hello"), config); err != nil {
+			t.Errorf("GenerateContent() failed: %v", err)
+		}
+	})
+}
+
+func TestModelsGet(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc         string
+		backend      Backend
+		wantPath     string
+		responseBody string
+		want         *ModelInfo
+	}{
+		{
+			desc:         "Gemini API",
+			backend:      BackendGeminiAPI,
+			wantPath:     "/v1beta/models/gemini-2.0-flash",
+			responseBody: `{"name":"models/gemini-2.0-flash","displayName":"Gemini 2.0 Flash","version":"2.0","supportedGenerationMethods":["generateContent","countTokens"],"inputTokenLimit":1048576,"outputTokenLimit":8192}`,
+			want: &ModelInfo{
+				Name:                       "models/gemini-2.0-flash",
+				DisplayName:                "Gemini 2.0 Flash",
+				Version:                    "2.0",
+				SupportedGenerationMethods: []string{"generateContent", "countTokens"},
+				InputTokenLimit:            1048576,
+				OutputTokenLimit:           8192,
+			},
+		},
+		{
+			desc:         "Vertex AI",
+			backend:      BackendVertexAI,
+			wantPath:     "/v1beta1/projects/test-project/locations/test-location/publishers/google/models/gemini-2.0-flash",
+			responseBody: `{"name":"publishers/google/models/gemini-2.0-flash","versionId":"001"}`,
+			want:         &ModelInfo{Name: "publishers/google/models/gemini-2.0-flash", Version: "001"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("request method = %q, want %q", r.Method, http.MethodGet)
+				}
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				w.Write([]byte(tt.responseBody))
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, tt.backend)}
+			got, err := m.Get(ctx, "gemini-2.0-flash", nil)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModelsUpdate(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc      string
+		backend   Backend
+		model     string
+		config    *UpdateModelConfig
+		wantPath  string
+		wantQuery string
+		wantBody  string
+	}{
+		{
+			desc:      "Gemini API",
+			backend:   BackendGeminiAPI,
+			model:     "tunedModels/my-model-123",
+			config:    &UpdateModelConfig{DisplayName: "My model", Description: "Tuned on support tickets", UpdateMask: []string{"displayName", "description"}},
+			wantPath:  "/v1beta/tunedModels/my-model-123",
+			wantQuery: "updateMask=displayName%2Cdescription",
+			wantBody:  `{"description":"Tuned on support tickets","displayName":"My model"}`,
+		},
+		{
+			desc:     "Vertex AI",
+			backend:  BackendVertexAI,
+			model:    "models/123",
+			config:   &UpdateModelConfig{DisplayName: "My model"},
+			wantPath: "/v1beta1/projects/test-project/locations/test-location/models/123",
+			wantBody: `{"displayName":"My model"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPatch {
+					t.Errorf("request method = %q, want %q", r.Method, http.MethodPatch)
+				}
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				if r.URL.RawQuery != tt.wantQuery {
+					t.Errorf("request query = %q, want %q", r.URL.RawQuery, tt.wantQuery)
+				}
+				body, _ := io.ReadAll(r.Body)
+				if got := strings.TrimSpace(string(body)); got != tt.wantBody {
+					t.Errorf("request body = %s, want %s", got, tt.wantBody)
+				}
+				w.Write([]byte(`{"displayName":"My model"}`))
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, tt.backend)}
+			got, err := m.Update(ctx, tt.model, tt.config)
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if got.DisplayName != "My model" {
+				t.Errorf("Update() DisplayName = %q, want %q", got.DisplayName, "My model")
+			}
+		})
+	}
+}
+
+func TestModelsDelete(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc     string
+		backend  Backend
+		model    string
+		wantPath string
+	}{
+		{
+			desc:     "Gemini API",
+			backend:  BackendGeminiAPI,
+			model:    "tunedModels/my-model-123",
+			wantPath: "/v1beta/tunedModels/my-model-123",
+		},
+		{
+			desc:     "Vertex AI",
+			backend:  BackendVertexAI,
+			model:    "models/123",
+			wantPath: "/v1beta1/projects/test-project/locations/test-location/models/123",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodDelete {
+					t.Errorf("request method = %q, want %q", r.Method, http.MethodDelete)
+				}
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				w.Write([]byte(`{}`))
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, tt.backend)}
+			got, err := m.Delete(ctx, tt.model, nil)
+			if err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			if diff := cmp.Diff(&DeleteModelResponse{}, got); diff != "" {
+				t.Errorf("Delete() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestModelsList(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc       string
+		backend    Backend
+		wantPath   string
+		modelsKey  string
+		config     *ListModelsConfig
+		wantQuery  []string
+		wantErr    bool
+		wantModels []*ModelInfo
+	}{
+		{
+			desc:      "Gemini API",
+			backend:   BackendGeminiAPI,
+			wantPath:  "/v1beta/models",
+			modelsKey: "models",
+			config:    &ListModelsConfig{PageSize: 2},
+			wantQuery: []string{"pageSize=2", "pageSize=2&pageToken=page-2"},
+			wantModels: []*ModelInfo{
+				{Name: "model-1"}, {Name: "model-2"}, {Name: "model-3"},
+			},
+		},
+		{
+			desc:      "Vertex AI",
+			backend:   BackendVertexAI,
+			wantPath:  "/v1beta1/projects/test-project/locations/test-location/publishers/google/models",
+			modelsKey: "publisherModels",
+			config:    &ListModelsConfig{Filter: "labels.foo=bar"},
+			wantQuery: []string{"filter=labels.foo%3Dbar", "filter=labels.foo%3Dbar&pageToken=page-2"},
+			wantModels: []*ModelInfo{
+				{Name: "model-1"}, {Name: "model-2"}, {Name: "model-3"},
+			},
+		},
+		{
+			desc:    "filter unsupported in Gemini API",
+			backend: BackendGeminiAPI,
+			config:  &ListModelsConfig{Filter: "labels.foo=bar"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotQuery []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				gotQuery = append(gotQuery, r.URL.RawQuery)
+				if r.URL.Query().Get("pageToken") == "" {
+					fmt.Fprintf(w, `{"%s":[{"name":"model-1"},{"name":"model-2"}],"nextPageToken":"page-2"}`, tt.modelsKey)
+					return
+				}
+				fmt.Fprintf(w, `{"%s":[{"name":"model-3"}]}`, tt.modelsKey)
+			}))
+			defer ts.Close()
+
+			m := Models{apiClient: newTestAPIClient(ts, tt.backend)}
+			var got []*ModelInfo
+			var err error
+			for model, iterErr := range m.List(ctx, tt.config) {
+				if iterErr != nil {
+					err = iterErr
+					break
+				}
+				got = append(got, model)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("List() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.wantModels, got); diff != "" {
+				t.Errorf("List() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantQuery, gotQuery); diff != "" {
+				t.Errorf("List() queries mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("stops fetching when the caller breaks", func(t *testing.T) {
+		requests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			fmt.Fprint(w, `{"models":[{"name":"model-1"},{"name":"model-2"}],"nextPageToken":"next"}`)
+		}))
+		defer ts.Close()
+
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		for range m.List(ctx, nil) {
+			break
+		}
+		if requests != 1 {
+			t.Errorf("got %d requests, want 1", requests)
+		}
+	})
+}
+
+func TestGenerateContentStreamFormat(t *testing.T) {
+	ctx := context.Background()
+	chunks := []string{
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}]}`,
+		`{"candidates":[{"content":{"role":"model","parts":[{"text":" world"}]}}]}`,
+	}
+
+	tests := []struct {
+		desc      string
+		format    StreamFormat
+		wantQuery string
+		body      string
+	}{
+		{
+			desc:      "default",
+			wantQuery: "alt=sse",
+			body:      "data: " + chunks[0] + "\n\ndata: " + chunks[1] + "\n\n",
+		},
+		{
+			desc:      "SSE",
+			format:    StreamFormatSSE,
+			wantQuery: "alt=sse",
+			body:      "data: " + chunks[0] + "\r\n\r\ndata: " + chunks[1] + "\r\n\r\n",
+		},
+		{
+			desc:   "NDJSON",
+			format: StreamFormatNDJSON,
+			body:   chunks[0] + "\n" + chunks[1] + "\n",
+		},
+		{
+			desc:   "NDJSON with CRLF and no final newline",
+			format: StreamFormatNDJSON,
+			body:   chunks[0] + "\r\n\r\n" + chunks[1],
+		},
+	}
+	for _, backend := range []Backend{BackendGeminiAPI, BackendVertexAI} {
+		for _, tt := range tests {
+			t.Run(backend.String()+"/"+tt.desc, func(t *testing.T) {
+				ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if !strings.HasSuffix(r.URL.Path, ":streamGenerateContent") {
+						t.Errorf("path = %q, want suffix %q", r.URL.Path, ":streamGenerateContent")
+					}
+					if r.URL.RawQuery != tt.wantQuery {
+						t.Errorf("query = %q, want %q", r.URL.RawQuery, tt.wantQuery)
+					}
+					fmt.Fprint(w, tt.body)
+				}))
+				defer ts.Close()
+
+				ac := newTestAPIClient(ts, backend)
+				ac.clientConfig.HTTPOptions.StreamFormat = tt.format
+				m := Models{apiClient: ac}
+				var got []string
+				for response, err := range m.GenerateContentStream(ctx, "gemini-2.0-flash", Text("Hi"), nil) {
+					if err != nil {
+						t.Fatalf("GenerateContentStream() error = %v", err)
+					}
+					text, err := response.Text()
+					if err != nil {
+						t.Fatalf("Text() error = %v", err)
+					}
+					got = append(got, text)
+				}
+				if diff := cmp.Diff([]string{"Hello", " world"}, got); diff != "" {
+					t.Errorf("GenerateContentStream() mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+
+	t.Run("per-request override", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.RawQuery != "" {
+				t.Errorf("query = %q, want none", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, chunks[0]+"\n")
+		}))
+		defer ts.Close()
+
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+		config := &GenerateContentConfig{HTTPOptions: &HTTPOptions{StreamFormat: StreamFormatNDJSON}}
+		for _, err := range m.GenerateContentStream(ctx, "gemini-2.0-flash", Text("Hi"), config) {
+			if err != nil {
+				t.Fatalf("GenerateContentStream() error = %v", err)
+			}
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		m := Models{apiClient: &apiClient{clientConfig: &ClientConfig{
+			Backend:     BackendGeminiAPI,
+			HTTPOptions: HTTPOptions{StreamFormat: "XML"},
+		}}}
+		for _, err := range m.GenerateContentStream(ctx, "gemini-2.0-flash", Text("Hi"), nil) {
+			if err == nil {
+				t.Errorf("GenerateContentStream() succeeded, want error")
+			}
+		}
+	})
+}
+
+func TestGenerateContentValidation(t *testing.T) {
+	ctx := context.Background()
+	newServer := func(t *testing.T) (*httptest.Server, *int) {
+		t.Helper()
+		generateRequests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				fmt.Fprint(w, `{"name":"models/gemini-2.0-flash","outputTokenLimit":100}`)
+				return
+			}
+			generateRequests++
+			if strings.HasSuffix(r.URL.Path, ":streamGenerateContent") {
+				fmt.Fprint(w, `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]}}]}`+"\n\n")
+				return
+			}
+			fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]}}]}`)
+		}))
+		return ts, &generateRequests
+	}
+	imageContents := func(size int) []*Content {
+		return []*Content{{Role: RoleUser, Parts: []*Part{
+			{Text: "Describe this image"},
+			{InlineData: &Blob{Data: make([]byte, size), MIMEType: "image/png"}},
+		}}}
+	}
+	// generate calls GenerateContent, or GenerateContentStream if stream is set, and
+	// returns the first error.
+	generate := func(m Models, stream bool, contents []*Content, config *GenerateContentConfig) error {
+		if !stream {
+			_, err := m.GenerateContent(ctx, "gemini-2.0-flash", contents, config)
+			return err
+		}
+		for _, err := range m.GenerateContentStream(ctx, "gemini-2.0-flash", contents, config) {
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			t.Run("inline data too large", func(t *testing.T) {
+				ts, generateRequests := newServer(t)
+				defer ts.Close()
+				ac := newTestAPIClient(ts, BackendGeminiAPI)
+				ac.clientConfig.MaxInlineDataBytes = 2 * 1024 * 1024
+				m := Models{apiClient: ac}
+
+				err := generate(m, stream, imageContents(2*1024*1024+1), nil)
+				if !errors.Is(err, ErrInvalidArgument) {
+					t.Fatalf("error = %v, want %v", err, ErrInvalidArgument)
+				}
+				want := "inline data of contents[0].Parts[1] is 2097153 bytes and exceeds the 2 MB limit; use the Files API instead"
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %q, want it to contain %q", err, want)
+				}
+				config := &GenerateContentConfig{SystemInstruction: imageContents(2*1024*1024 + 1)[0]}
+				if err := generate(m, stream, Text("Hi"), config); !errors.Is(err, ErrInvalidArgument) {
+					t.Errorf("error = %v, want %v", err, ErrInvalidArgument)
+				}
+				if *generateRequests != 0 {
+					t.Errorf("got %d requests, want none", *generateRequests)
+				}
+			})
+
+			t.Run("inline data within limit", func(t *testing.T) {
+				ts, generateRequests := newServer(t)
+				defer ts.Close()
+				ac := newTestAPIClient(ts, BackendGeminiAPI)
+				ac.clientConfig.MaxInlineDataBytes = 1024
+				m := Models{apiClient: ac}
+
+				if err := generate(m, stream, imageContents(1024), nil); err != nil {
+					t.Fatalf("error = %v, want nil", err)
+				}
+				ac.clientConfig.MaxInlineDataBytes = -1
+				if err := generate(m, stream, imageContents(4096), nil); err != nil {
+					t.Fatalf("error with check disabled = %v, want nil", err)
+				}
+				if *generateRequests != 2 {
+					t.Errorf("got %d requests, want 2", *generateRequests)
+				}
+			})
+
+			t.Run("max output tokens", func(t *testing.T) {
+				ts, generateRequests := newServer(t)
+				defer ts.Close()
+				m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+
+				// The limit of the model is unknown until Models.Get is called.
+				if err := generate(m, stream, Text("Hi"), &GenerateContentConfig{MaxOutputTokens: Ptr[int64](1000)}); err != nil {
+					t.Fatalf("error before Get = %v, want nil", err)
+				}
+				if _, err := m.Get(ctx, "gemini-2.0-flash", nil); err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				err := generate(m, stream, Text("Hi"), &GenerateContentConfig{MaxOutputTokens: Ptr[int64](1000)})
+				if !errors.Is(err, ErrInvalidArgument) {
+					t.Errorf("error after Get = %v, want %v", err, ErrInvalidArgument)
+				}
+				if err := generate(m, stream, Text("Hi"), &GenerateContentConfig{MaxOutputTokens: Ptr[int64](100)}); err != nil {
+					t.Errorf("error at the limit = %v, want nil", err)
+				}
+				if *generateRequests != 2 {
+					t.Errorf("got %d requests, want 2", *generateRequests)
+				}
+			})
+		})
+	}
+}
+
+func TestGenerateContentTemperatureRange(t *testing.T) {
+	ctx := context.Background()
+	newServer := func(t *testing.T, modelResponse string) (*httptest.Server, *int) {
+		t.Helper()
+		getRequests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				getRequests++
+				if modelResponse == "" {
+					http.Error(w, `{"error":{"code":404,"message":"model not found","status":"NOT_FOUND"}}`, http.StatusNotFound)
+					return
+				}
+				fmt.Fprint(w, modelResponse)
+				return
+			}
+			fmt.Fprint(w, `{"candidates":[{"content":{"role":"model","parts":[{"text":"ok"}]}}]}`)
+		}))
+		return ts, &getRequests
+	}
+	generate := func(m Models, temperature float64) error {
+		_, err := m.GenerateContent(ctx, "gemini-1.0-pro", Text("Hi"), &GenerateContentConfig{Temperature: Ptr(temperature)})
+		return err
+	}
+
+	t.Run("out of range", func(t *testing.T) {
+		ts, getRequests := newServer(t, `{"name":"models/gemini-1.0-pro","maxTemperature":1}`)
+		defer ts.Close()
+		ac := newTestAPIClient(ts, BackendGeminiAPI)
+		ac.clientConfig.ValidateModelConstraints = true
+		m := Models{apiClient: ac}
+
+		err := generate(m, 1.5)
+		if !errors.Is(err, ErrInvalidArgument) {
+			t.Fatalf("error = %v, want %v", err, ErrInvalidArgument)
+		}
+		want := "config.Temperature is 1.5, which is outside the range [0, 1] of model models/gemini-1.0-pro"
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to contain %q", err, want)
+		}
+		if err := generate(m, -0.5); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("error = %v, want %v", err, ErrInvalidArgument)
+		}
+		if err := generate(m, 1); err != nil {
+			t.Errorf("error at the maximum = %v, want nil", err)
+		}
+		if *getRequests != 1 {
+			t.Errorf("got %d Get requests, want 1", *getRequests)
+		}
+	})
+
+	t.Run("metadata unavailable", func(t *testing.T) {
+		ts, getRequests := newServer(t, "")
+		defer ts.Close()
+		ac := newTestAPIClient(ts, BackendGeminiAPI)
+		ac.clientConfig.ValidateModelConstraints = true
+		m := Models{apiClient: ac}
+
+		for range 2 {
+			if err := generate(m, 1.5); err != nil {
+				t.Errorf("error = %v, want nil", err)
+			}
+		}
+		if *getRequests != 1 {
+			t.Errorf("got %d Get requests, want 1", *getRequests)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		ts, getRequests := newServer(t, `{"name":"models/gemini-1.0-pro","maxTemperature":1}`)
+		defer ts.Close()
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+
+		if err := generate(m, 1.5); err != nil {
+			t.Errorf("error = %v, want nil", err)
+		}
+		if *getRequests != 0 {
+			t.Errorf("got %d Get requests, want none", *getRequests)
+		}
+		// Metadata fetched explicitly is used for validation.
+		if _, err := m.Get(ctx, "gemini-1.0-pro", nil); err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if err := generate(m, 1.5); !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("error after Get = %v, want %v", err, ErrInvalidArgument)
+		}
+	})
+}
+
+func TestGenerateContentStreamChan(t *testing.T) {
+	ctx := context.Background()
+	chunk := `data: {"candidates":[{"content":{"role":"model","parts":[{"text":"Hello"}]}}]}` + "\n\n"
+
+	t.Run("stream", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, chunk+chunk)
+		}))
+		defer ts.Close()
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+
+		responses, errc := m.GenerateContentStreamChan(ctx, "gemini-2.0-flash", Text("Hi"), nil)
+		var got []string
+		for resp := range responses {
+			text, _ := resp.Text()
+			got = append(got, text)
+		}
+		if err := <-errc; err != nil {
+			t.Errorf("error = %v, want nil", err)
+		}
+		if diff := cmp.Diff([]string{"Hello", "Hello"}, got); diff != "" {
+			t.Errorf("responses mismatch (-want +got):\n%s", diff)
+		}
+		if _, ok := <-errc; ok {
+			t.Error("error channel is open after the error, want closed")
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"code":400,"message":"bad request","status":"INVALID_ARGUMENT"}}`)
+		}))
+		defer ts.Close()
+		m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+
+		responses, errc := m.GenerateContentStreamChan(ctx, "gemini-2.0-flash", Text("Hi"), nil)
+		if resp, ok := <-responses; ok {
+			t.Errorf("got response %v, want none", resp)
+		}
+		if err := <-errc; !errors.Is(err, ErrInvalidArgument) {
+			t.Errorf("error = %v, want %v", err, ErrInvalidArgument)
+		}
+	})
+
+	for _, receive := range []bool{true, false} {
+		t.Run(fmt.Sprintf("cancel/receiving=%v", receive), func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, chunk)
+				w.(http.Flusher).Flush()
+				// The rest of the stream never comes.
+				<-r.Context().Done()
+			}))
+			defer ts.Close()
+			m := Models{apiClient: newTestAPIClient(ts, BackendGeminiAPI)}
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			responses, errc := m.GenerateContentStreamChan(ctx, "gemini-2.0-flash", Text("Hi"), nil)
+			if receive {
+				if _, ok := <-responses; !ok {
+					t.Fatal("responses channel closed before the first response")
+				}
+			}
+			cancel()
+
+			timeout := time.After(time.Second)
+			for open := true; open; {
+				select {
+				case _, open = <-responses:
+				case <-timeout:
+					t.Fatal("responses channel not closed after cancel")
+				}
+			}
+			select {
+			case err := <-errc:
+				if !errors.Is(err, context.Canceled) {
+					t.Errorf("error = %v, want %v", err, context.Canceled)
+				}
+			case <-timeout:
+				t.Fatal("no error after cancel")
+			}
+		})
+	}
+}
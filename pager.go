@@ -0,0 +1,48 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"iter"
+)
+
+// pageFetcher fetches the page identified by pageToken. It returns the items of the
+// page and the token of the next page, which is empty after the last page.
+type pageFetcher[T any] func(ctx context.Context, pageToken string) (items []*T, nextPageToken string, err error)
+
+// allPages returns an iterator over the items of all pages starting at pageToken.
+// Pages are fetched lazily, only when the caller has consumed the previous page. The
+// iterator stops after yielding the first error.
+func allPages[T any](ctx context.Context, pageToken string, fetch pageFetcher[T]) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		for {
+			items, nextPageToken, err := fetch(ctx, pageToken)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if nextPageToken == "" {
+				return
+			}
+			pageToken = nextPageToken
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter limits the rate of the HTTP requests sent by the client. Set it in
+// [ClientConfig.RateLimiter].
+type RateLimiter interface {
+	// Wait blocks until a request may be sent, or returns an error if ctx is done
+	// first.
+	Wait(ctx context.Context) error
+}
+
+// TokenBucketRateLimiter is a [RateLimiter] that allows a number of requests per
+// minute. Up to a burst of requests can be sent at once after the limiter has been
+// idle; the burst defaults to 1, which spaces requests evenly. It is safe for
+// concurrent use.
+type TokenBucketRateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // Time to refill one token.
+	burst    float64
+	tokens   float64
+	last     time.Time // Last time tokens was updated.
+}
+
+// NewTokenBucketRateLimiter returns a limiter that allows rpm requests per minute.
+// It panics if rpm is not positive.
+func NewTokenBucketRateLimiter(rpm int) *TokenBucketRateLimiter {
+	if rpm <= 0 {
+		panic("genai: NewTokenBucketRateLimiter: rpm must be positive")
+	}
+	return &TokenBucketRateLimiter{
+		interval: time.Minute / time.Duration(rpm),
+		burst:    1,
+		tokens:   1,
+		last:     time.Now(),
+	}
+}
+
+// SetBurst sets the maximum number of requests that can be sent at once. Values
+// lower than 1 are treated as 1.
+func (l *TokenBucketRateLimiter) SetBurst(burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.refill(time.Now())
+	l.burst = float64(max(burst, 1))
+	l.tokens = min(l.tokens, l.burst)
+}
+
+// Wait takes a token from the bucket, waiting for one to be refilled if it is
+// empty. If ctx is done first, the token is given back and ctx.Err() is returned.
+func (l *TokenBucketRateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+	// Reserve the token even if it has not been refilled yet, so that concurrent
+	// callers queue up behind each other.
+	l.tokens--
+	wait := time.Duration(-l.tokens * float64(l.interval))
+	l.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+	if err := sleepContext(ctx, wait); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// refill adds the tokens accumulated since the last update. l.mu must be held.
+func (l *TokenBucketRateLimiter) refill(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(l.burst, l.tokens+float64(elapsed)/float64(l.interval))
+		l.last = now
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("sequential calls are spaced", func(t *testing.T) {
+		// 1200 requests per minute is one request every 50ms.
+		l := NewTokenBucketRateLimiter(1200)
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			if err := l.Wait(ctx); err != nil {
+				t.Fatalf("Wait() failed: %v", err)
+			}
+		}
+		// The first call does not wait, the next three wait 50ms each.
+		if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+			t.Errorf("4 calls took %v, want at least 150ms", elapsed)
+		}
+	})
+
+	t.Run("burst", func(t *testing.T) {
+		l := NewTokenBucketRateLimiter(60)
+		l.SetBurst(3)
+		// Let the bucket fill up to the burst.
+		l.mu.Lock()
+		l.tokens = 3
+		l.mu.Unlock()
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			if err := l.Wait(ctx); err != nil {
+				t.Fatalf("Wait() failed: %v", err)
+			}
+		}
+		if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+			t.Errorf("3 calls within the burst took %v, want no wait", elapsed)
+		}
+	})
+
+	t.Run("context cancellation unblocks", func(t *testing.T) {
+		l := NewTokenBucketRateLimiter(1)
+		if err := l.Wait(ctx); err != nil {
+			t.Fatalf("Wait() failed: %v", err)
+		}
+		ctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Wait() error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Wait() returned after %v, want it to return when the context is done", elapsed)
+		}
+		// The canceled call gave its token back.
+		l.mu.Lock()
+		tokens := l.tokens
+		l.mu.Unlock()
+		if tokens < -0.01 {
+			t.Errorf("tokens after canceled Wait() = %v, want about 0", tokens)
+		}
+	})
+}
+
+type countingRateLimiter struct {
+	calls int
+	err   error
+}
+
+func (l *countingRateLimiter) Wait(ctx context.Context) error {
+	l.calls++
+	return l.err
+}
+
+func TestSendRequestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	}))
+	defer ts.Close()
+
+	t.Run("waits before each request", func(t *testing.T) {
+		limiter := &countingRateLimiter{}
+		ac := &apiClient{clientConfig: &ClientConfig{HTTPOptions: HTTPOptions{BaseURL: ts.URL}, HTTPClient: ts.Client(), RateLimiter: limiter}}
+		for i := 0; i < 2; i++ {
+			if _, err := sendRequest(ctx, ac, "foo", http.MethodGet, nil, nil); err != nil {
+				t.Fatalf("sendRequest() failed: %v", err)
+			}
+		}
+		if limiter.calls != 2 {
+			t.Errorf("RateLimiter.Wait() called %d times, want 2", limiter.calls)
+		}
+	})
+
+	t.Run("wait error is returned", func(t *testing.T) {
+		limiter := &countingRateLimiter{err: context.Canceled}
+		ac := &apiClient{clientConfig: &ClientConfig{HTTPOptions: HTTPOptions{BaseURL: ts.URL}, HTTPClient: ts.Client(), RateLimiter: limiter}}
+		if _, err := sendRequest(ctx, ac, "foo", http.MethodGet, nil, nil); !errors.Is(err, context.Canceled) {
+			t.Errorf("sendRequest() error = %v, want %v", err, context.Canceled)
+		}
+	})
+}
@@ -28,6 +28,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -35,12 +36,20 @@ import (
 )
 
 // ReplayAPIClient is a client that reads responses from a replay session file.
+// In record mode, it instead proxies the requests to the real API and writes the
+// interactions to a replay file.
 type replayAPIClient struct {
 	ReplayFile              *replayFile
 	ReplaysDirectory        string
 	currentInteractionIndex int
 	t                       *testing.T
 	server                  *httptest.Server
+
+	// Set in record mode only.
+	mu              sync.Mutex
+	recordPath      string       // File the interactions are written to.
+	upstreamBaseURL string       // Base URL of the real API, such as "https://generativelanguage.googleapis.com".
+	upstream        *http.Client // Client that authenticates the requests to the real API.
 }
 
 // NewReplayAPIClient creates a new ReplayAPIClient from a replay session file.
@@ -62,6 +71,28 @@ func newReplayAPIClient(t *testing.T) *replayAPIClient {
 	return rac
 }
 
+// newRecordingAPIClient creates a ReplayAPIClient in record mode. It sends the
+// requests to upstreamBaseURL with upstream and, when the test ends, writes the
+// interactions to replayFilePath, relative to the replays directory.
+func newRecordingAPIClient(t *testing.T, replayFilePath, upstreamBaseURL string, upstream *http.Client) *replayAPIClient {
+	t.Helper()
+	rac := newReplayAPIClient(t)
+	rac.recordPath = replayFilePath
+	if rac.ReplaysDirectory != "" {
+		rac.recordPath = filepath.Join(rac.ReplaysDirectory, replayFilePath)
+	}
+	rac.upstreamBaseURL = strings.TrimSuffix(upstreamBaseURL, "/")
+	rac.upstream = upstream
+	rac.ReplayFile = &replayFile{ReplayID: strings.TrimSuffix(filepath.Base(replayFilePath), ".json")}
+	// Cleanups run last-in first-out, so the file is written before the server closes.
+	t.Cleanup(func() {
+		if err := rac.writeReplay(); err != nil {
+			t.Errorf("error writing replay file: %v", err)
+		}
+	})
+	return rac
+}
+
 // GetBaseURL returns the URL of the mocked HTTP server.
 func (rac *replayAPIClient) GetBaseURL() string {
 	return rac.server.URL
@@ -98,6 +129,10 @@ func (rac *replayAPIClient) LatestInteraction() *replayInteraction {
 // ServeHTTP mocks serving HTTP requests.
 func (rac *replayAPIClient) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	rac.t.Helper()
+	if rac.recordPath != "" {
+		rac.record(w, req)
+		return
+	}
 	if rac.ReplayFile == nil {
 		rac.t.Fatalf("no replay file loaded")
 	}
@@ -124,6 +159,162 @@ func (rac *replayAPIClient) ServeHTTP(w http.ResponseWriter, req *http.Request)
 	w.Write([]byte(strings.Join(bodySegments, "\n")))
 }
 
+// record sends req to the real API, writes the response to w and appends the
+// interaction to the replay file, with the credentials and the project and location
+// redacted.
+func (rac *replayAPIClient) record(w http.ResponseWriter, req *http.Request) {
+	rac.t.Helper()
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	upstreamReq, err := http.NewRequestWithContext(req.Context(), req.Method, rac.upstreamBaseURL+req.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	upstreamReq.Header = req.Header.Clone()
+	resp, err := rac.upstream.Do(upstreamReq)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	for k, v := range resp.Header {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(resp.StatusCode)
+	w.Write(respBody)
+
+	interaction := &replayInteraction{
+		Request: &replayRequest{
+			Method:  strings.ToLower(req.Method),
+			URL:     recordedURL(req.URL.String()),
+			Headers: redactHeaders(req.Header),
+		},
+		Response: &replayResponse{
+			StatusCode: int64(resp.StatusCode),
+			Headers:    redactHeaders(resp.Header),
+		},
+	}
+	if len(bytes.TrimSpace(body)) > 0 {
+		segment := make(map[string]any)
+		if err := json.Unmarshal(body, &segment); err != nil {
+			rac.t.Errorf("error unmarshalling recorded request body: %v", err)
+		}
+		interaction.Request.BodySegments = []map[string]any{redactRequestBody(segment)}
+	}
+	segments, err := responseBodySegments(respBody)
+	if err != nil {
+		rac.t.Errorf("error unmarshalling recorded response body: %v", err)
+	}
+	interaction.Response.BodySegments = segments
+
+	rac.mu.Lock()
+	defer rac.mu.Unlock()
+	rac.ReplayFile.Interactions = append(rac.ReplayFile.Interactions, interaction)
+	rac.currentInteractionIndex++
+}
+
+// recordedURL returns the URL of a replay file for the URL of a request sent by the
+// SDK, with the host, API version, project and location replaced by a prefix.
+func recordedURL(url string) string {
+	if strings.Contains(url, "project") {
+		return "{VERTEX_URL_PREFIX}/" + redactSDKURL(url)
+	}
+	return "{MLDEV_URL_PREFIX}/" + redactSDKURL(url)
+}
+
+// redactedHeaders are the headers that hold credentials.
+var redactedHeaders = map[string]bool{
+	"Authorization":  true,
+	"X-Goog-Api-Key": true,
+	"Set-Cookie":     true,
+}
+
+// redactHeaders returns the first value of each header, with the credentials
+// replaced by "{REDACTED}".
+func redactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for k, v := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(k)] {
+			redacted[k] = "{REDACTED}"
+		} else if len(v) > 0 {
+			redacted[k] = v[0]
+		}
+	}
+	return redacted
+}
+
+// responseBodySegments splits a response body into its JSON objects: the body
+// itself, or each "data:" line of a server-sent events stream.
+func responseBodySegments(body []byte) ([]map[string]any, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+	if !bytes.HasPrefix(body, []byte("data:")) {
+		segment := make(map[string]any)
+		if err := json.Unmarshal(body, &segment); err != nil {
+			return nil, err
+		}
+		return []map[string]any{segment}, nil
+	}
+	var segments []map[string]any
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		data, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:"))
+		if !ok {
+			continue
+		}
+		segment := make(map[string]any)
+		if err := json.Unmarshal(data, &segment); err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// SetSDKResponse sets the response returned by the SDK for the latest recorded
+// interaction, which only the caller of the SDK knows.
+func (rac *replayAPIClient) SetSDKResponse(segments []map[string]any) {
+	rac.mu.Lock()
+	defer rac.mu.Unlock()
+	if n := len(rac.ReplayFile.Interactions); n > 0 {
+		rac.ReplayFile.Interactions[n-1].Response.SDKResponseSegments = segments
+	}
+}
+
+// writeReplay writes the recorded interactions to the replay file. The output is
+// deterministic: object keys are sorted and indentation is fixed.
+func (rac *replayAPIClient) writeReplay() error {
+	rac.mu.Lock()
+	defer rac.mu.Unlock()
+	data, err := json.Marshal(rac.ReplayFile)
+	if err != nil {
+		return err
+	}
+	// Round trip through a map so that the keys of structs are sorted too.
+	var canonical map[string]any
+	if err := json.Unmarshal(data, &canonical); err != nil {
+		return err
+	}
+	data, err = json.MarshalIndent(canonical, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(rac.recordPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(rac.recordPath, append(data, '\n'), 0o644)
+}
+
 func readFileForReplayTest[T any](path string, output *T) error {
 	dat, err := os.ReadFile(path)
 	if err != nil {
@@ -0,0 +1,99 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestReplayRecordRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	replaysDirectory := t.TempDir()
+	t.Setenv("GOOGLE_GENAI_REPLAYS_DIRECTORY", replaysDirectory)
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-goog-api-key"); got != "secret-key" {
+			t.Errorf("upstream API key = %q, want %q", got, "secret-key")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hi!"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":2,"totalTokenCount":4}}`))
+	}))
+	defer upstream.Close()
+
+	generate := func(t *testing.T, rac *replayAPIClient, apiKey string) *GenerateContentResponse {
+		t.Helper()
+		httpClient, _ := rac.CreateClient(ctx)
+		client, err := NewClient(ctx, &ClientConfig{
+			Backend:     BackendGeminiAPI,
+			APIKey:      apiKey,
+			HTTPClient:  httpClient,
+			HTTPOptions: HTTPOptions{BaseURL: rac.GetBaseURL()},
+		})
+		if err != nil {
+			t.Fatalf("NewClient() failed: %v", err)
+		}
+		resp, err := client.Models.GenerateContent(ctx, "gemini-2.0-flash", Text("Hello"), &GenerateContentConfig{Temperature: Ptr(0.5)})
+		if err != nil {
+			t.Fatalf("GenerateContent() failed: %v", err)
+		}
+		return resp
+	}
+
+	var recorded *GenerateContentResponse
+	for _, name := range []string{"first.mldev.json", "second.mldev.json"} {
+		t.Run("record "+name, func(t *testing.T) {
+			rac := newRecordingAPIClient(t, filepath.Join("models", name), upstream.URL, upstream.Client())
+			recorded = generate(t, rac, "secret-key")
+			rac.SetSDKResponse(convertSDKResponseToMatchReplayType(t, *recorded))
+		})
+	}
+
+	first, err := os.ReadFile(filepath.Join(replaysDirectory, "models", "first.mldev.json"))
+	if err != nil {
+		t.Fatalf("reading the recorded replay failed: %v", err)
+	}
+	second, err := os.ReadFile(filepath.Join(replaysDirectory, "models", "second.mldev.json"))
+	if err != nil {
+		t.Fatalf("reading the recorded replay failed: %v", err)
+	}
+	if bytes.Contains(first, []byte("secret-key")) {
+		t.Errorf("recorded replay contains the API key:\n%s", first)
+	}
+	first = bytes.Replace(first, []byte(`"replayId": "first.mldev"`), []byte(`"replayId": "second.mldev"`), 1)
+	if diff := cmp.Diff(string(first), string(second)); diff != "" {
+		t.Errorf("recordings of the same interaction differ (-first +second):\n%s", diff)
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		rac := newReplayAPIClient(t)
+		rac.LoadReplay(filepath.Join("models", "first.mldev.json"))
+		got := generate(t, rac, "fake-api-key")
+		if diff := cmp.Diff(recorded, got); diff != "" {
+			t.Errorf("replayed response mismatch (-recorded +replayed):\n%s", diff)
+		}
+		want := rac.LatestInteraction().Response.SDKResponseSegments
+		if diff := cmp.Diff(want, convertSDKResponseToMatchReplayType(t, *got), stringComparator); diff != "" {
+			t.Errorf("SDK response segments mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts  = 3
+	defaultRetryInitialDelay = time.Second
+	defaultRetryMaxDelay     = time.Minute
+	defaultRetryMultiplier   = 2.0
+)
+
+// RetryConfig configures automatic retries for transient API errors.
+//
+// A request is retried when the API responds with HTTP 429 (Too Many Requests) or
+// any 5xx status. Client errors other than 429 are never retried. Streaming requests
+// are only retried before the first byte of the response body is read.
+type RetryConfig struct {
+	// Maximum number of attempts, including the first one. Defaults to 3 when zero.
+	MaxAttempts int
+	// Delay before the first retry. Defaults to 1 second when zero.
+	InitialDelay time.Duration
+	// Upper bound for the delay between attempts. Defaults to 1 minute when zero.
+	MaxDelay time.Duration
+	// Factor applied to the delay after each retry. Defaults to 2 when zero.
+	Multiplier float64
+}
+
+func (rc *RetryConfig) maxAttempts() int {
+	if rc.MaxAttempts <= 0 {
+		return defaultRetryMaxAttempts
+	}
+	return rc.MaxAttempts
+}
+
+// delay returns the jittered backoff to wait after the given failed attempt
+// (1-based). The result is in [d/2, d], where d is the exponential backoff capped at
+// MaxDelay.
+func (rc *RetryConfig) delay(attempt int) time.Duration {
+	initial, maxDelay, multiplier := rc.InitialDelay, rc.MaxDelay, rc.Multiplier
+	if initial <= 0 {
+		initial = defaultRetryInitialDelay
+	}
+	if maxDelay <= 0 {
+		maxDelay = defaultRetryMaxDelay
+	}
+	if multiplier <= 0 {
+		multiplier = defaultRetryMultiplier
+	}
+	d := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if d > float64(maxDelay) {
+		d = float64(maxDelay)
+	}
+	half := d / 2
+	return time.Duration(half + rand.Float64()*half)
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// sleepContext waits for d or until ctx is done, whichever happens first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
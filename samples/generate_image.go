@@ -46,18 +46,18 @@ func generateImage(ctx context.Context) {
 		log.Fatal(err)
 	}
 	if client.ClientConfig().Backend == genai.BackendVertexAI {
-		fmt.Println("Calling VertexAI GenerateImage API...")
+		fmt.Println("Calling VertexAI GenerateImages API...")
 	} else {
-		fmt.Println("Calling GeminiAI GenerateImage API...")
+		fmt.Println("Calling GeminiAI GenerateImages API...")
 	}
 	// Pass in basic config
-	var config *genai.GenerateImageConfig = &genai.GenerateImageConfig{
+	var config *genai.GenerateImagesConfig = &genai.GenerateImagesConfig{
 		NumberOfImages:   genai.Ptr[int64](1),
 		OutputMIMEType:   "image/jpeg",
 		IncludeRAIReason: true,
 	}
-	// Call the GenerateContent method.
-	result, err := client.Models.GenerateImage(ctx, *model, "Create a blue circle", config)
+	// Call the GenerateImages method.
+	result, err := client.Models.GenerateImages(ctx, *model, "Create a blue circle", config)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,533 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SchemaFromType returns the [Schema] of the JSON encoding of values of type T.
+//
+// Structs are mapped to objects whose properties are named after the json struct
+// tags of the exported fields. A field is required unless its json tag has the
+// omitempty option. Pointers are mapped to nullable schemas. Slices and arrays are
+// mapped to arrays, and maps with string keys to objects. time.Time is mapped to a
+// string with the date-time format.
+//
+// The genai struct tag sets the description and the enum values of a field, separated
+// by semicolons:
+//
+//	type Weather struct {
+//		City string `json:"city" genai:"description=Name of the city"`
+//		Unit string `json:"unit,omitempty" genai:"enum=celsius,fahrenheit"`
+//	}
+//
+// An error is returned for recursive types and for types that have no JSON schema
+// equivalent, such as channels, functions and interfaces.
+func SchemaFromType[T any]() (*Schema, error) {
+	return schemaFromType(reflect.TypeFor[T]())
+}
+
+func schemaFromType(t reflect.Type) (*Schema, error) {
+	return (&typeSchemaWalker{visiting: map[reflect.Type]bool{}}).schema(t)
+}
+
+// SetJSONSchema configures config so that the model responds with JSON matching
+// the schema of type T, as returned by [SchemaFromType]. Use [ParseJSONResponse] to
+// decode the response.
+func SetJSONSchema[T any](config *GenerateContentConfig) error {
+	schema, err := SchemaFromType[T]()
+	if err != nil {
+		return err
+	}
+	config.ResponseMIMEType = "application/json"
+	config.ResponseSchema = schema
+	return nil
+}
+
+// ParseJSONResponse decodes the text of the first candidate of resp as JSON into a
+// new value of type T.
+func ParseJSONResponse[T any](resp *GenerateContentResponse) (*T, error) {
+	text, err := resp.Text()
+	if err != nil {
+		return nil, err
+	}
+	if text == "" {
+		return nil, fmt.Errorf("ParseJSONResponse: response has no text")
+	}
+	v := new(T)
+	if err := json.Unmarshal([]byte(text), v); err != nil {
+		return nil, fmt.Errorf("ParseJSONResponse: %w", err)
+	}
+	return v, nil
+}
+
+// validateJSONResponse checks that the text of the first candidate of resp is JSON
+// matching schema.
+func validateJSONResponse(resp *GenerateContentResponse, schema *Schema) error {
+	text, err := resp.Text()
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(strings.NewReader(text))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return fmt.Errorf("validateJSONResponse: response text is not valid JSON: %w", err)
+	}
+	if d.More() {
+		return fmt.Errorf("validateJSONResponse: response text has data after the JSON value")
+	}
+	if err := validateValue(v, schema, "$"); err != nil {
+		return fmt.Errorf("validateJSONResponse: response does not match the response schema: %w", err)
+	}
+	return nil
+}
+
+// validateValue checks the decoded JSON value v against s. Numbers must be decoded
+// as json.Number. path locates v in the response, for error messages.
+func validateValue(v any, s *Schema, path string) error {
+	if v == nil {
+		if s.Nullable || s.Type == "" || s.Type == TypeUnspecified {
+			return nil
+		}
+		return fmt.Errorf("%s is null, want %s", path, s.Type)
+	}
+	if len(s.AnyOf) > 0 {
+		for _, alt := range s.AnyOf {
+			if validateValue(v, alt, path) == nil {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s does not match any of the anyOf schemas", path)
+	}
+	switch s.Type {
+	case TypeObject:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is %s, want OBJECT", path, jsonKind(v))
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s is missing required property %q", path, name)
+			}
+		}
+		for name, property := range s.Properties {
+			if pv, ok := obj[name]; ok && property != nil {
+				if err := validateValue(pv, property, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	case TypeArray:
+		arr, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s is %s, want ARRAY", path, jsonKind(v))
+		}
+		if s.MinItems != nil && int64(len(arr)) < *s.MinItems {
+			return fmt.Errorf("%s has %d items, want at least %d", path, len(arr), *s.MinItems)
+		}
+		if s.MaxItems != nil && int64(len(arr)) > *s.MaxItems {
+			return fmt.Errorf("%s has %d items, want at most %d", path, len(arr), *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				if err := validateValue(item, s.Items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case TypeString:
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s is %s, want STRING", path, jsonKind(v))
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return fmt.Errorf("%s is %q, want one of %q", path, str, s.Enum)
+		}
+	case TypeInteger:
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s is %s, want INTEGER", path, jsonKind(v))
+		}
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("%s is %s, want INTEGER", path, n)
+		}
+	case TypeNumber:
+		if _, ok := v.(json.Number); !ok {
+			return fmt.Errorf("%s is %s, want NUMBER", path, jsonKind(v))
+		}
+	case TypeBoolean:
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s is %s, want BOOLEAN", path, jsonKind(v))
+		}
+	}
+	return nil
+}
+
+// functionName matches the names accepted for function declarations.
+var functionName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.-]{0,63}$`)
+
+// Validate reports the errors in t that the API would reject the request for.
+func (t *Tool) Validate() error {
+	if t == nil {
+		return nil
+	}
+	names := make(map[string]bool)
+	for i, fd := range t.FunctionDeclarations {
+		if fd == nil {
+			return fmt.Errorf("function declaration %d is nil", i)
+		}
+		if err := fd.Validate(); err != nil {
+			return err
+		}
+		if names[fd.Name] {
+			return fmt.Errorf("function %q is declared more than once", fd.Name)
+		}
+		names[fd.Name] = true
+	}
+	return nil
+}
+
+// Validate reports the errors in fd that the API would reject the request for: a
+// missing or invalid name, parameters that are not an object, schema types that do
+// not exist, and required properties that are not declared.
+func (fd *FunctionDeclaration) Validate() error {
+	if fd.Name == "" {
+		return fmt.Errorf("function declaration has no name")
+	}
+	if !functionName.MatchString(fd.Name) {
+		return fmt.Errorf("function %q: name must start with a letter or an underscore, contain only a-z, A-Z, 0-9, underscores, dots and dashes, and be at most 64 characters long", fd.Name)
+	}
+	if fd.Parameters != nil {
+		if fd.Parameters.Type != TypeObject {
+			return fmt.Errorf("function %q: parameters have type %q, want %s", fd.Name, fd.Parameters.Type, TypeObject)
+		}
+		if err := validateSchema(fd.Parameters, "parameters"); err != nil {
+			return fmt.Errorf("function %q: %w", fd.Name, err)
+		}
+	}
+	if fd.Response != nil {
+		if err := validateSchema(fd.Response, "response"); err != nil {
+			return fmt.Errorf("function %q: %w", fd.Name, err)
+		}
+	}
+	return nil
+}
+
+// validateSchema checks that the types of s and its subschemas exist and that its
+// required properties are declared. path locates s, for error messages.
+func validateSchema(s *Schema, path string) error {
+	if s == nil {
+		return fmt.Errorf("%s is nil", path)
+	}
+	switch s.Type {
+	case "", TypeString, TypeNumber, TypeInteger, TypeBoolean, TypeArray, TypeObject:
+	default:
+		return fmt.Errorf("%s has unsupported type %q", path, s.Type)
+	}
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok {
+			return fmt.Errorf("%s requires property %q, which is not declared", path, name)
+		}
+	}
+	for name, property := range s.Properties {
+		if err := validateSchema(property, path+".properties."+name); err != nil {
+			return err
+		}
+	}
+	if s.Items != nil {
+		if err := validateSchema(s.Items, path+".items"); err != nil {
+			return err
+		}
+	}
+	for i, alt := range s.AnyOf {
+		if err := validateSchema(alt, fmt.Sprintf("%s.anyOf[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonKind returns the schema type name of the decoded JSON value v.
+func jsonKind(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "OBJECT"
+	case []any:
+		return "ARRAY"
+	case string:
+		return "STRING"
+	case json.Number:
+		return "NUMBER"
+	case bool:
+		return "BOOLEAN"
+	default:
+		return "NULL"
+	}
+}
+
+// typeSchemaWalker converts Go types to schemas by reflection. It is unrelated to
+// SchemaBuilder, which builds schemas field by field.
+type typeSchemaWalker struct {
+	// visiting holds the struct types being converted, to detect recursive types.
+	visiting map[reflect.Type]bool
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+func (b *typeSchemaWalker) schema(t reflect.Type) (*Schema, error) {
+	if t == timeType {
+		return &Schema{Type: TypeString, Format: "date-time"}, nil
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: TypeBoolean}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: TypeInteger, Format: "int32"}, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: TypeInteger, Format: "int64"}, nil
+	case reflect.Float32:
+		return &Schema{Type: TypeNumber, Format: "float"}, nil
+	case reflect.Float64:
+		return &Schema{Type: TypeNumber, Format: "double"}, nil
+	case reflect.String:
+		return &Schema{Type: TypeString}, nil
+	case reflect.Pointer:
+		s, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		s.Nullable = true
+		return s, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are encoded as base64 strings, but byte arrays as arrays of
+			// numbers.
+			return &Schema{Type: TypeString}, nil
+		}
+		items, err := b.schema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: TypeArray, Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("schemaFromType: map key type %s is not supported, only string keys are", t.Key())
+		}
+		return &Schema{Type: TypeObject}, nil
+	case reflect.Struct:
+		return b.structSchema(t)
+	default:
+		return nil, fmt.Errorf("schemaFromType: type %s of kind %s is not supported", t, t.Kind())
+	}
+}
+
+func (b *typeSchemaWalker) structSchema(t reflect.Type) (*Schema, error) {
+	if b.visiting[t] {
+		return nil, fmt.Errorf("schemaFromType: recursive type %s is not supported", t)
+	}
+	b.visiting[t] = true
+	defer delete(b.visiting, t)
+
+	s := &Schema{Type: TypeObject, Properties: map[string]*Schema{}}
+	if err := b.addFields(s, t); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// addFields adds the properties of the fields of the struct type t to s. The fields
+// of embedded structs are promoted, as in the JSON encoding.
+func (b *typeSchemaWalker) addFields(s *Schema, t reflect.Type) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				// Like encoding/json, a struct embedded in itself is skipped.
+				if b.visiting[ft] {
+					continue
+				}
+				b.visiting[ft] = true
+				err := b.addFields(s, ft)
+				delete(b.visiting, ft)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property, err := b.schema(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s.%s: %w", t, field.Name, err)
+		}
+		if err := applySchemaTag(property, field.Tag.Get("genai")); err != nil {
+			return fmt.Errorf("field %s.%s: %w", t, field.Name, err)
+		}
+		s.Properties[name] = property
+		s.PropertyOrdering = append(s.PropertyOrdering, name)
+		if !strings.Contains(","+opts+",", ",omitempty,") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
+
+// applySchemaTag sets the description and enum values given by the genai struct tag
+// of a field.
+func applySchemaTag(s *Schema, tag string) error {
+	if tag == "" {
+		return nil
+	}
+	for _, option := range strings.Split(tag, ";") {
+		key, value, ok := strings.Cut(option, "=")
+		if !ok {
+			return fmt.Errorf("applySchemaTag: invalid genai tag option %q, want key=value", option)
+		}
+		switch strings.TrimSpace(key) {
+		case "description":
+			s.Description = value
+		case "enum":
+			if s.Type != TypeString {
+				return fmt.Errorf("applySchemaTag: enum values are only supported for strings, got %s", s.Type)
+			}
+			s.Enum = strings.Split(value, ",")
+		default:
+			return fmt.Errorf("applySchemaTag: unknown genai tag option %q", key)
+		}
+	}
+	return nil
+}
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+	resultType  = reflect.TypeFor[map[string]any]()
+	closureName = regexp.MustCompile(`^func\d+$`)
+)
+
+// FunctionDeclarationFromFunc returns the declaration of the function fn, to be used
+// in [Tool.FunctionDeclarations]. The name of the declaration is the name of fn.
+//
+// fn must have the signature
+//
+//	func(ctx context.Context, args...) (map[string]any, error)
+//
+// The parameter schemas are derived from the argument types as described in
+// [SchemaFromType]. Since the names of the arguments are not available through
+// reflection, they are either given by paramNames, one per argument after ctx, or
+// taken from the json struct tags of the fields when fn has a single struct argument
+// and no paramNames are given.
+func FunctionDeclarationFromFunc(fn any, description string, paramNames ...string) (*FunctionDeclaration, error) {
+	v := reflect.ValueOf(fn)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("FunctionDeclarationFromFunc: fn must not be nil")
+	}
+	t := v.Type()
+	if t.Kind() != reflect.Func {
+		return nil, fmt.Errorf("FunctionDeclarationFromFunc: %s is not a function", t)
+	}
+	if v.IsNil() {
+		return nil, fmt.Errorf("FunctionDeclarationFromFunc: fn must not be a nil %s", t)
+	}
+	if t.NumIn() == 0 || t.In(0) != contextType {
+		return nil, fmt.Errorf("FunctionDeclarationFromFunc: the first argument of %s must be a context.Context", t)
+	}
+	if t.NumOut() != 2 || t.Out(0) != resultType || t.Out(1) != errorType {
+		return nil, fmt.Errorf("FunctionDeclarationFromFunc: %s must return (map[string]any, error)", t)
+	}
+	if t.IsVariadic() {
+		return nil, fmt.Errorf("FunctionDeclarationFromFunc: variadic function %s is not supported", t)
+	}
+	name, err := funcName(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decl := &FunctionDeclaration{Name: name, Description: description}
+	args := t.NumIn() - 1
+	switch {
+	case args == 0 && len(paramNames) == 0:
+		// The function has no parameters.
+	case args == 1 && len(paramNames) == 0 && indirect(t.In(1)).Kind() == reflect.Struct:
+		decl.Parameters, err = schemaFromType(indirect(t.In(1)))
+		if err != nil {
+			return nil, fmt.Errorf("FunctionDeclarationFromFunc: %w", err)
+		}
+	case args == len(paramNames):
+		decl.Parameters = &Schema{Type: TypeObject, Properties: map[string]*Schema{}}
+		for i, paramName := range paramNames {
+			if _, ok := decl.Parameters.Properties[paramName]; ok {
+				return nil, fmt.Errorf("FunctionDeclarationFromFunc: duplicate parameter name %q", paramName)
+			}
+			s, err := schemaFromType(t.In(i + 1))
+			if err != nil {
+				return nil, fmt.Errorf("FunctionDeclarationFromFunc: parameter %q: %w", paramName, err)
+			}
+			decl.Parameters.Properties[paramName] = s
+			decl.Parameters.PropertyOrdering = append(decl.Parameters.PropertyOrdering, paramName)
+			decl.Parameters.Required = append(decl.Parameters.Required, paramName)
+		}
+	default:
+		return nil, fmt.Errorf("FunctionDeclarationFromFunc: %s has %d arguments after the context but %d parameter names were given", t, args, len(paramNames))
+	}
+	return decl, nil
+}
+
+// funcName returns the unqualified name of the function v.
+func funcName(v reflect.Value) (string, error) {
+	f := runtime.FuncForPC(v.Pointer())
+	if f == nil {
+		return "", fmt.Errorf("FunctionDeclarationFromFunc: cannot get the name of the function")
+	}
+	// Method values are named like "pkg.(*T).Method-fm".
+	name := strings.TrimSuffix(f.Name(), "-fm")
+	name = name[strings.LastIndex(name, ".")+1:]
+	// Closures are named like "pkg.outer.func1".
+	if closureName.MatchString(name) {
+		return "", fmt.Errorf("FunctionDeclarationFromFunc: cannot derive a name for anonymous function %s, use a named function", f.Name())
+	}
+	return name, nil
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
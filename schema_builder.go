@@ -0,0 +1,147 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// SchemaBuilder builds a [Schema] with chained method calls:
+//
+//	schema, err := genai.NewSchemaBuilder(genai.TypeObject).
+//		Description("A city").
+//		Property("name", &genai.Schema{Type: genai.TypeString}, true).
+//		Property("population", &genai.Schema{Type: genai.TypeInteger, Format: "int64"}, false).
+//		Build()
+//
+// Methods that do not apply to the type of the schema, such as Items on an object,
+// make Build return an error.
+type SchemaBuilder struct {
+	schema Schema
+	err    error
+}
+
+// NewSchemaBuilder returns a builder of a schema of type t.
+func NewSchemaBuilder(t Type) *SchemaBuilder {
+	b := &SchemaBuilder{schema: Schema{Type: t}}
+	switch t {
+	case TypeString, TypeNumber, TypeInteger, TypeBoolean, TypeArray, TypeObject:
+	default:
+		b.setErr("unsupported type %q", t)
+	}
+	return b
+}
+
+// setErr records the first error, which is returned by Build.
+func (b *SchemaBuilder) setErr(format string, args ...any) {
+	if b.err == nil {
+		b.err = fmt.Errorf("SchemaBuilder: "+format, args...)
+	}
+}
+
+// Description sets the description of the schema.
+func (b *SchemaBuilder) Description(s string) *SchemaBuilder {
+	b.schema.Description = s
+	return b
+}
+
+// Property adds a property to an object schema. Properties are ordered as they are
+// added.
+func (b *SchemaBuilder) Property(name string, schema *Schema, required bool) *SchemaBuilder {
+	switch {
+	case b.schema.Type != TypeObject:
+		b.setErr("property %q set on a schema of type %s, want %s", name, b.schema.Type, TypeObject)
+	case name == "":
+		b.setErr("property name is empty")
+	case schema == nil:
+		b.setErr("property %q has a nil schema", name)
+	case b.schema.Properties[name] != nil:
+		b.setErr("property %q is set more than once", name)
+	default:
+		if b.schema.Properties == nil {
+			b.schema.Properties = map[string]*Schema{}
+		}
+		b.schema.Properties[name] = schema
+		b.schema.PropertyOrdering = append(b.schema.PropertyOrdering, name)
+		if required {
+			b.schema.Required = append(b.schema.Required, name)
+		}
+	}
+	return b
+}
+
+// Items sets the schema of the items of an array schema.
+func (b *SchemaBuilder) Items(schema *Schema) *SchemaBuilder {
+	switch {
+	case b.schema.Type != TypeArray:
+		b.setErr("items set on a schema of type %s, want %s", b.schema.Type, TypeArray)
+	case schema == nil:
+		b.setErr("items schema is nil")
+	default:
+		b.schema.Items = schema
+	}
+	return b
+}
+
+// Enum sets the possible values of a string schema.
+func (b *SchemaBuilder) Enum(values ...string) *SchemaBuilder {
+	switch {
+	case b.schema.Type != TypeString:
+		b.setErr("enum set on a schema of type %s, want %s", b.schema.Type, TypeString)
+	case len(values) == 0:
+		b.setErr("enum has no values")
+	default:
+		b.schema.Enum = slices.Clone(values)
+	}
+	return b
+}
+
+// Format sets the format of a string, number or integer schema, for example
+// "date-time", "double" or "int64".
+func (b *SchemaBuilder) Format(f string) *SchemaBuilder {
+	switch b.schema.Type {
+	case TypeString, TypeNumber, TypeInteger:
+		b.schema.Format = f
+	default:
+		b.setErr("format set on a schema of type %s", b.schema.Type)
+	}
+	return b
+}
+
+// Nullable sets whether the value of the schema may be null.
+func (b *SchemaBuilder) Nullable(nullable bool) *SchemaBuilder {
+	b.schema.Nullable = nullable
+	return b
+}
+
+// Build returns the schema, or the first error of the method calls. An array schema
+// must have items. The builder can be used again after Build; the returned schema
+// is not affected.
+func (b *SchemaBuilder) Build() (*Schema, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.schema.Type == TypeArray && b.schema.Items == nil {
+		return nil, fmt.Errorf("SchemaBuilder: array schema has no items")
+	}
+	s := b.schema
+	s.Properties = maps.Clone(s.Properties)
+	s.PropertyOrdering = slices.Clone(s.PropertyOrdering)
+	s.Required = slices.Clone(s.Required)
+	s.Enum = slices.Clone(s.Enum)
+	return &s, nil
+}
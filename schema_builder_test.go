@@ -0,0 +1,175 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSchemaBuilder(t *testing.T) {
+	tests := []struct {
+		desc    string
+		builder *SchemaBuilder
+		want    *Schema
+	}{
+		{
+			desc:    "string",
+			builder: NewSchemaBuilder(TypeString).Description("A color").Enum("red", "green").Nullable(true),
+			want:    &Schema{Type: TypeString, Description: "A color", Enum: []string{"red", "green"}, Nullable: true},
+		},
+		{
+			desc:    "string format",
+			builder: NewSchemaBuilder(TypeString).Format("date-time"),
+			want:    &Schema{Type: TypeString, Format: "date-time"},
+		},
+		{
+			desc:    "number",
+			builder: NewSchemaBuilder(TypeNumber).Format("double"),
+			want:    &Schema{Type: TypeNumber, Format: "double"},
+		},
+		{
+			desc:    "integer",
+			builder: NewSchemaBuilder(TypeInteger).Format("int64").Nullable(true).Nullable(false),
+			want:    &Schema{Type: TypeInteger, Format: "int64"},
+		},
+		{
+			desc:    "boolean",
+			builder: NewSchemaBuilder(TypeBoolean).Description("Whether it is done"),
+			want:    &Schema{Type: TypeBoolean, Description: "Whether it is done"},
+		},
+		{
+			desc:    "array",
+			builder: NewSchemaBuilder(TypeArray).Items(&Schema{Type: TypeString}),
+			want:    &Schema{Type: TypeArray, Items: &Schema{Type: TypeString}},
+		},
+		{
+			desc: "object",
+			builder: NewSchemaBuilder(TypeObject).
+				Property("name", &Schema{Type: TypeString}, true).
+				Property("tags", &Schema{Type: TypeArray, Items: &Schema{Type: TypeString}}, false).
+				Property("age", &Schema{Type: TypeInteger}, true),
+			want: &Schema{
+				Type: TypeObject,
+				Properties: map[string]*Schema{
+					"name": {Type: TypeString},
+					"tags": {Type: TypeArray, Items: &Schema{Type: TypeString}},
+					"age":  {Type: TypeInteger},
+				},
+				PropertyOrdering: []string{"name", "tags", "age"},
+				Required:         []string{"name", "age"},
+			},
+		},
+		{
+			desc:    "empty object",
+			builder: NewSchemaBuilder(TypeObject),
+			want:    &Schema{Type: TypeObject},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := tt.builder.Build()
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Build() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSchemaBuilderNested(t *testing.T) {
+	address, err := NewSchemaBuilder(TypeObject).
+		Property("city", &Schema{Type: TypeString}, true).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	addresses, err := NewSchemaBuilder(TypeArray).Items(address).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	got, err := NewSchemaBuilder(TypeObject).Property("addresses", addresses, false).Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want := &Schema{
+		Type: TypeObject,
+		Properties: map[string]*Schema{
+			"addresses": {Type: TypeArray, Items: &Schema{
+				Type:             TypeObject,
+				Properties:       map[string]*Schema{"city": {Type: TypeString}},
+				PropertyOrdering: []string{"city"},
+				Required:         []string{"city"},
+			}},
+		},
+		PropertyOrdering: []string{"addresses"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Build() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSchemaBuilderReuse(t *testing.T) {
+	b := NewSchemaBuilder(TypeObject).Property("a", &Schema{Type: TypeString}, true)
+	first, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if _, err := b.Property("b", &Schema{Type: TypeString}, true).Build(); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	want := &Schema{
+		Type:             TypeObject,
+		Properties:       map[string]*Schema{"a": {Type: TypeString}},
+		PropertyOrdering: []string{"a"},
+		Required:         []string{"a"},
+	}
+	if diff := cmp.Diff(want, first); diff != "" {
+		t.Errorf("first Build() changed by later calls (-want +got):\n%s", diff)
+	}
+}
+
+func TestSchemaBuilderErrors(t *testing.T) {
+	tests := []struct {
+		desc    string
+		builder *SchemaBuilder
+	}{
+		{desc: "unspecified type", builder: NewSchemaBuilder(TypeUnspecified)},
+		{desc: "unknown type", builder: NewSchemaBuilder(Type("DATE"))},
+		{desc: "items on object", builder: NewSchemaBuilder(TypeObject).Items(&Schema{Type: TypeString})},
+		{desc: "nil items", builder: NewSchemaBuilder(TypeArray).Items(nil)},
+		{desc: "array without items", builder: NewSchemaBuilder(TypeArray)},
+		{desc: "property on array", builder: NewSchemaBuilder(TypeArray).Items(&Schema{Type: TypeString}).Property("a", &Schema{Type: TypeString}, true)},
+		{desc: "property on string", builder: NewSchemaBuilder(TypeString).Property("a", &Schema{Type: TypeString}, false)},
+		{desc: "empty property name", builder: NewSchemaBuilder(TypeObject).Property("", &Schema{Type: TypeString}, false)},
+		{desc: "nil property schema", builder: NewSchemaBuilder(TypeObject).Property("a", nil, false)},
+		{desc: "duplicate property", builder: NewSchemaBuilder(TypeObject).Property("a", &Schema{Type: TypeString}, false).Property("a", &Schema{Type: TypeInteger}, false)},
+		{desc: "enum on integer", builder: NewSchemaBuilder(TypeInteger).Enum("1", "2")},
+		{desc: "empty enum", builder: NewSchemaBuilder(TypeString).Enum()},
+		{desc: "format on boolean", builder: NewSchemaBuilder(TypeBoolean).Format("bool")},
+		{desc: "format on object", builder: NewSchemaBuilder(TypeObject).Format("date-time")},
+		{desc: "error is kept after valid calls", builder: NewSchemaBuilder(TypeString).Items(&Schema{Type: TypeString}).Description("ok").Format("enum")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got, err := tt.builder.Build(); err == nil {
+				t.Errorf("Build() = %v, want error", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,477 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type schemaTestAddress struct {
+	Street string `json:"street" genai:"description=Street and number"`
+	City   string `json:"city,omitempty"`
+}
+
+type schemaTestBase struct {
+	ID string `json:"id"`
+}
+
+type schemaTestPerson struct {
+	schemaTestBase
+	Name      string             `json:"name" genai:"description=Full name"`
+	Age       int                `json:"age,omitempty"`
+	Role      string             `json:"role" genai:"description=Role of the person;enum=admin,user"`
+	Address   *schemaTestAddress `json:"address,omitempty"`
+	Tags      []string           `json:"tags,omitempty"`
+	Scores    map[string]float64 `json:"scores,omitempty"`
+	Birthday  time.Time          `json:"birthday"`
+	Untagged  bool
+	Ignored   string `json:"-"`
+	unexposed string
+}
+
+type schemaTestNode struct {
+	Value    int               `json:"value"`
+	Children []*schemaTestNode `json:"children"`
+}
+
+// schemaTestSelfEmbedding embeds itself, which encoding/json ignores.
+type schemaTestSelfEmbedding struct {
+	*schemaTestSelfEmbedding
+	A int `json:"a"`
+}
+
+// schemaTestEmbeddingA and schemaTestEmbeddingB embed each other.
+type schemaTestEmbeddingA struct {
+	*schemaTestEmbeddingB
+	A int `json:"a"`
+}
+
+type schemaTestEmbeddingB struct {
+	*schemaTestEmbeddingA
+	B int `json:"b"`
+}
+
+func TestSchemaFromTypePrimitives(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want *Schema
+	}{
+		{name: "bool", typ: reflect.TypeFor[bool](), want: &Schema{Type: TypeBoolean}},
+		{name: "int", typ: reflect.TypeFor[int](), want: &Schema{Type: TypeInteger, Format: "int64"}},
+		{name: "int8", typ: reflect.TypeFor[int8](), want: &Schema{Type: TypeInteger, Format: "int32"}},
+		{name: "int16", typ: reflect.TypeFor[int16](), want: &Schema{Type: TypeInteger, Format: "int32"}},
+		{name: "int32", typ: reflect.TypeFor[int32](), want: &Schema{Type: TypeInteger, Format: "int32"}},
+		{name: "int64", typ: reflect.TypeFor[int64](), want: &Schema{Type: TypeInteger, Format: "int64"}},
+		{name: "uint", typ: reflect.TypeFor[uint](), want: &Schema{Type: TypeInteger, Format: "int64"}},
+		{name: "uint8", typ: reflect.TypeFor[uint8](), want: &Schema{Type: TypeInteger, Format: "int32"}},
+		{name: "uint16", typ: reflect.TypeFor[uint16](), want: &Schema{Type: TypeInteger, Format: "int32"}},
+		{name: "uint32", typ: reflect.TypeFor[uint32](), want: &Schema{Type: TypeInteger, Format: "int64"}},
+		{name: "uint64", typ: reflect.TypeFor[uint64](), want: &Schema{Type: TypeInteger, Format: "int64"}},
+		{name: "float32", typ: reflect.TypeFor[float32](), want: &Schema{Type: TypeNumber, Format: "float"}},
+		{name: "float64", typ: reflect.TypeFor[float64](), want: &Schema{Type: TypeNumber, Format: "double"}},
+		{name: "string", typ: reflect.TypeFor[string](), want: &Schema{Type: TypeString}},
+		{name: "bytes", typ: reflect.TypeFor[[]byte](), want: &Schema{Type: TypeString}},
+		{name: "time", typ: reflect.TypeFor[time.Time](), want: &Schema{Type: TypeString, Format: "date-time"}},
+		{name: "pointer", typ: reflect.TypeFor[*int64](), want: &Schema{Type: TypeInteger, Format: "int64", Nullable: true}},
+		{name: "pointer to pointer", typ: reflect.TypeFor[**string](), want: &Schema{Type: TypeString, Nullable: true}},
+		{name: "slice", typ: reflect.TypeFor[[]bool](), want: &Schema{Type: TypeArray, Items: &Schema{Type: TypeBoolean}}},
+		{name: "array", typ: reflect.TypeFor[[3]float64](), want: &Schema{Type: TypeArray, Items: &Schema{Type: TypeNumber, Format: "double"}}},
+		{name: "byte array", typ: reflect.TypeFor[[4]byte](), want: &Schema{Type: TypeArray, Items: &Schema{Type: TypeInteger, Format: "int32"}}},
+		{name: "slice of pointers", typ: reflect.TypeFor[[]*string](), want: &Schema{Type: TypeArray, Items: &Schema{Type: TypeString, Nullable: true}}},
+		{name: "map", typ: reflect.TypeFor[map[string]int](), want: &Schema{Type: TypeObject}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schemaFromType(tt.typ)
+			if err != nil {
+				t.Fatalf("schemaFromType(%s) error = %v", tt.typ, err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("schemaFromType(%s) mismatch (-want +got):\n%s", tt.typ, diff)
+			}
+		})
+	}
+}
+
+func TestSchemaFromTypeStruct(t *testing.T) {
+	got, err := SchemaFromType[schemaTestPerson]()
+	if err != nil {
+		t.Fatalf("SchemaFromType() error = %v", err)
+	}
+	want := &Schema{
+		Type: TypeObject,
+		Properties: map[string]*Schema{
+			"id":   {Type: TypeString},
+			"name": {Type: TypeString, Description: "Full name"},
+			"age":  {Type: TypeInteger, Format: "int64"},
+			"role": {Type: TypeString, Description: "Role of the person", Enum: []string{"admin", "user"}},
+			"address": {
+				Type:     TypeObject,
+				Nullable: true,
+				Properties: map[string]*Schema{
+					"street": {Type: TypeString, Description: "Street and number"},
+					"city":   {Type: TypeString},
+				},
+				PropertyOrdering: []string{"street", "city"},
+				Required:         []string{"street"},
+			},
+			"tags":     {Type: TypeArray, Items: &Schema{Type: TypeString}},
+			"scores":   {Type: TypeObject},
+			"birthday": {Type: TypeString, Format: "date-time"},
+			"Untagged": {Type: TypeBoolean},
+		},
+		PropertyOrdering: []string{"id", "name", "age", "role", "address", "tags", "scores", "birthday", "Untagged"},
+		Required:         []string{"id", "name", "role", "birthday", "Untagged"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("SchemaFromType() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSchemaFromTypeErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+	}{
+		{name: "recursive struct", typ: reflect.TypeFor[schemaTestNode]()},
+		{name: "channel", typ: reflect.TypeFor[chan int]()},
+		{name: "function", typ: reflect.TypeFor[func()]()},
+		{name: "interface", typ: reflect.TypeFor[any]()},
+		{name: "complex", typ: reflect.TypeFor[complex128]()},
+		{name: "map with int keys", typ: reflect.TypeFor[map[int]string]()},
+		{name: "unsupported field", typ: reflect.TypeFor[struct{ F chan int }]()},
+		{name: "enum on non-string", typ: reflect.TypeFor[struct {
+			F int `genai:"enum=1,2"`
+		}]()},
+		{name: "unknown tag option", typ: reflect.TypeFor[struct {
+			F string `genai:"format=email"`
+		}]()},
+		{name: "malformed tag option", typ: reflect.TypeFor[struct {
+			F string `genai:"description"`
+		}]()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := schemaFromType(tt.typ); err == nil {
+				t.Errorf("schemaFromType(%s) = %+v, want error", tt.typ, got)
+			}
+		})
+	}
+}
+
+func TestSchemaFromTypeRepeatedType(t *testing.T) {
+	// The same type used twice is not a recursive type.
+	type route struct {
+		From schemaTestAddress `json:"from"`
+		To   schemaTestAddress `json:"to"`
+	}
+	got, err := SchemaFromType[route]()
+	if err != nil {
+		t.Fatalf("SchemaFromType() error = %v", err)
+	}
+	if diff := cmp.Diff(got.Properties["from"], got.Properties["to"]); diff != "" {
+		t.Errorf("from and to schemas differ (-from +to):\n%s", diff)
+	}
+}
+
+func TestSchemaFromTypeRecursiveEmbedding(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  reflect.Type
+		want []string
+	}{
+		{name: "self", typ: reflect.TypeFor[schemaTestSelfEmbedding](), want: []string{"a"}},
+		{name: "mutual", typ: reflect.TypeFor[schemaTestEmbeddingA](), want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schemaFromType(tt.typ)
+			if err != nil {
+				t.Fatalf("schemaFromType(%s) error = %v", tt.typ, err)
+			}
+			if diff := cmp.Diff(tt.want, slices.Sorted(maps.Keys(got.Properties))); diff != "" {
+				t.Errorf("schemaFromType(%s) properties mismatch (-want +got):\n%s", tt.typ, diff)
+			}
+		})
+	}
+}
+
+type weatherArgs struct {
+	City string `json:"city" genai:"description=Name of the city"`
+	Unit string `json:"unit,omitempty" genai:"enum=celsius,fahrenheit"`
+}
+
+func getWeather(ctx context.Context, args weatherArgs) (map[string]any, error) {
+	return nil, nil
+}
+
+func getForecast(ctx context.Context, city string, days int) (map[string]any, error) {
+	return nil, nil
+}
+
+func getTime(ctx context.Context) (map[string]any, error) {
+	return nil, nil
+}
+
+type weatherService struct{}
+
+func (weatherService) GetWeather(ctx context.Context, args *weatherArgs) (map[string]any, error) {
+	return nil, nil
+}
+
+func TestFunctionDeclarationFromFunc(t *testing.T) {
+	weatherParameters := &Schema{
+		Type: TypeObject,
+		Properties: map[string]*Schema{
+			"city": {Type: TypeString, Description: "Name of the city"},
+			"unit": {Type: TypeString, Enum: []string{"celsius", "fahrenheit"}},
+		},
+		PropertyOrdering: []string{"city", "unit"},
+		Required:         []string{"city"},
+	}
+	tests := []struct {
+		name       string
+		fn         any
+		paramNames []string
+		want       *FunctionDeclaration
+	}{
+		{
+			name: "struct argument",
+			fn:   getWeather,
+			want: &FunctionDeclaration{Name: "getWeather", Description: "desc", Parameters: weatherParameters},
+		},
+		{
+			name: "method value with struct pointer argument",
+			fn:   weatherService{}.GetWeather,
+			want: &FunctionDeclaration{Name: "GetWeather", Description: "desc", Parameters: weatherParameters},
+		},
+		{
+			name:       "named arguments",
+			fn:         getForecast,
+			paramNames: []string{"city", "days"},
+			want: &FunctionDeclaration{Name: "getForecast", Description: "desc", Parameters: &Schema{
+				Type: TypeObject,
+				Properties: map[string]*Schema{
+					"city": {Type: TypeString},
+					"days": {Type: TypeInteger, Format: "int64"},
+				},
+				PropertyOrdering: []string{"city", "days"},
+				Required:         []string{"city", "days"},
+			}},
+		},
+		{
+			name: "no arguments",
+			fn:   getTime,
+			want: &FunctionDeclaration{Name: "getTime", Description: "desc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FunctionDeclarationFromFunc(tt.fn, "desc", tt.paramNames...)
+			if err != nil {
+				t.Fatalf("FunctionDeclarationFromFunc() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("FunctionDeclarationFromFunc() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestToolValidate(t *testing.T) {
+	params := &Schema{
+		Type:       TypeObject,
+		Properties: map[string]*Schema{"city": {Type: TypeString}},
+		Required:   []string{"city"},
+	}
+	tests := []struct {
+		desc    string
+		tool    *Tool
+		wantErr string
+	}{
+		{desc: "valid", tool: &Tool{FunctionDeclarations: []*FunctionDeclaration{{Name: "get_weather", Parameters: params}, {Name: "noop"}}}},
+		{desc: "nil tool", tool: nil},
+		{desc: "missing name", tool: &Tool{FunctionDeclarations: []*FunctionDeclaration{{Parameters: params}}}, wantErr: "function declaration has no name"},
+		{desc: "invalid name", tool: &Tool{FunctionDeclarations: []*FunctionDeclaration{{Name: "get weather"}}}, wantErr: `function "get weather": name must start`},
+		{desc: "duplicate name", tool: &Tool{FunctionDeclarations: []*FunctionDeclaration{{Name: "f"}, {Name: "f"}}}, wantErr: `function "f" is declared more than once`},
+		{desc: "nil declaration", tool: &Tool{FunctionDeclarations: []*FunctionDeclaration{nil}}, wantErr: "function declaration 0 is nil"},
+		{
+			desc:    "parameters not an object",
+			tool:    &Tool{FunctionDeclarations: []*FunctionDeclaration{{Name: "f", Parameters: &Schema{Type: TypeString}}}},
+			wantErr: `function "f": parameters have type "STRING", want OBJECT`,
+		},
+		{
+			desc: "invalid type",
+			tool: &Tool{FunctionDeclarations: []*FunctionDeclaration{{Name: "f", Parameters: &Schema{
+				Type:       TypeObject,
+				Properties: map[string]*Schema{"tags": {Type: TypeArray, Items: &Schema{Type: "STRINGS"}}},
+			}}}},
+			wantErr: `function "f": parameters.properties.tags.items has unsupported type "STRINGS"`,
+		},
+		{
+			desc: "extra required field",
+			tool: &Tool{FunctionDeclarations: []*FunctionDeclaration{{Name: "f", Parameters: &Schema{
+				Type:       TypeObject,
+				Properties: map[string]*Schema{"city": {Type: TypeString}},
+				Required:   []string{"city", "country"},
+			}}}},
+			wantErr: `function "f": parameters requires property "country", which is not declared`,
+		},
+		{
+			desc:    "invalid response",
+			tool:    &Tool{FunctionDeclarations: []*FunctionDeclaration{{Name: "f", Response: &Schema{Type: TypeUnspecified}}}},
+			wantErr: `function "f": response has unsupported type "TYPE_UNSPECIFIED"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := tt.tool.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFunctionDeclarationFromFuncErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		fn         any
+		paramNames []string
+	}{
+		{name: "nil", fn: nil},
+		{name: "nil function", fn: (func(context.Context) (map[string]any, error))(nil)},
+		{name: "not a function", fn: "getWeather"},
+		{name: "missing context", fn: func(city string) (map[string]any, error) { return nil, nil }},
+		{name: "wrong results", fn: func(ctx context.Context) error { return nil }},
+		{name: "variadic", fn: func(ctx context.Context, cities ...string) (map[string]any, error) { return nil, nil }},
+		{name: "anonymous function", fn: func(ctx context.Context) (map[string]any, error) { return nil, nil }},
+		{name: "missing parameter names", fn: getForecast},
+		{name: "too many parameter names", fn: getForecast, paramNames: []string{"city", "days", "unit"}},
+		{name: "duplicate parameter names", fn: getForecast, paramNames: []string{"city", "city"}},
+		{name: "unsupported parameter type", fn: unsupportedTool, paramNames: []string{"ch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := FunctionDeclarationFromFunc(tt.fn, "desc", tt.paramNames...); err == nil {
+				t.Errorf("FunctionDeclarationFromFunc() = %+v, want error", got)
+			}
+		})
+	}
+}
+
+func unsupportedTool(ctx context.Context, ch chan int) (map[string]any, error) {
+	return nil, nil
+}
+
+func TestJSONResponseHelpers(t *testing.T) {
+	type recipe struct {
+		Name        string   `json:"name"`
+		Ingredients []string `json:"ingredients,omitempty"`
+	}
+
+	config := &GenerateContentConfig{Temperature: Ptr(0.5)}
+	if err := SetJSONSchema[recipe](config); err != nil {
+		t.Fatalf("SetJSONSchema failed: %v", err)
+	}
+	wantSchema, err := SchemaFromType[recipe]()
+	if err != nil {
+		t.Fatalf("SchemaFromType failed: %v", err)
+	}
+	wantConfig := &GenerateContentConfig{
+		Temperature:      Ptr(0.5),
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   wantSchema,
+	}
+	if diff := cmp.Diff(wantConfig, config); diff != "" {
+		t.Errorf("SetJSONSchema() config mismatch (-want +got):\n%s", diff)
+	}
+
+	resp := &GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{
+		{Text: `{"name":"pancakes",`},
+		{Text: `"ingredients":["flour","milk","eggs"]}`},
+	}}}}}
+	got, err := ParseJSONResponse[recipe](resp)
+	if err != nil {
+		t.Fatalf("ParseJSONResponse failed: %v", err)
+	}
+	want := &recipe{Name: "pancakes", Ingredients: []string{"flour", "milk", "eggs"}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ParseJSONResponse() mismatch (-want +got):\n%s", diff)
+	}
+
+	t.Run("bytes", func(t *testing.T) {
+		type checksum struct {
+			Data   []byte  `json:"data"`
+			Digest [4]byte `json:"digest"`
+		}
+		want := &checksum{Data: []byte("hello"), Digest: [4]byte{1, 2, 3, 4}}
+		text, err := json.Marshal(want)
+		if err != nil {
+			t.Fatalf("json.Marshal failed: %v", err)
+		}
+		schema, err := SchemaFromType[checksum]()
+		if err != nil {
+			t.Fatalf("SchemaFromType failed: %v", err)
+		}
+		resp := &GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: string(text)}}}}}}
+		if err := validateJSONResponse(resp, schema); err != nil {
+			t.Errorf("the JSON encoding %s does not match the schema: %v", text, err)
+		}
+		got, err := ParseJSONResponse[checksum](resp)
+		if err != nil {
+			t.Fatalf("ParseJSONResponse failed: %v", err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("ParseJSONResponse() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if err := SetJSONSchema[chan int](&GenerateContentConfig{}); err == nil {
+			t.Errorf("SetJSONSchema[chan int]() succeeded, want error")
+		}
+		tests := []struct {
+			desc string
+			resp *GenerateContentResponse
+		}{
+			{desc: "no text", resp: &GenerateContentResponse{}},
+			{desc: "invalid JSON", resp: &GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: "not json"}}}}}}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				if _, err := ParseJSONResponse[recipe](tt.resp); err == nil {
+					t.Errorf("ParseJSONResponse() succeeded, want error")
+				}
+			})
+		}
+	})
+}
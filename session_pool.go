@@ -0,0 +1,297 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSessionPoolClosed is returned by SessionPool.Acquire after SessionPool.Close.
+var ErrSessionPoolClosed = errors.New("genai: session pool is closed")
+
+// SessionPoolOptions configures a SessionPool.
+type SessionPoolOptions struct {
+	// MinSessions is the number of sessions that the pool connects in advance and
+	// keeps ready to be acquired.
+	MinSessions int
+	// MaxSessions is the maximum number of open sessions, acquired, ready or
+	// connecting. Acquire waits for a session to be released when it is reached. If
+	// 0, the number of sessions is not limited.
+	MaxSessions int
+	// IdleTimeout is the time after which a ready session that was not acquired is
+	// closed and replaced with a new one, before the server times it out. If 0, ready
+	// sessions are kept open until the pool is closed.
+	IdleTimeout time.Duration
+}
+
+// SessionPool keeps Live sessions connected to a model with the same config ready
+// to be acquired, which saves the latency of the WebSocket handshake and of the
+// setup at the start of each conversation. Sessions are never reused: a released
+// session is closed, so that a conversation is not seen by the next caller, and
+// the pool connects a new one in the background. It is safe for concurrent use.
+// The live module is experimental.
+type SessionPool struct {
+	live    *Live
+	model   string
+	config  *LiveConnectConfig
+	err     error // Error copying the config, returned by Acquire.
+	options SessionPoolOptions
+	ctx     context.Context // Canceled by Close, to stop connecting sessions.
+	cancel  context.CancelFunc
+
+	mu         sync.Mutex
+	ready      []readySession    // Connected sessions never acquired, the oldest first.
+	acquired   map[*Session]bool // Sessions acquired and not released yet.
+	open       int               // Sessions that are ready, acquired or connecting.
+	connecting int               // Sessions connected in the background.
+	closed     bool
+	release    chan struct{} // Closed and replaced when a session is ready, released or closed.
+}
+
+type readySession struct {
+	session   *Session
+	connected time.Time
+}
+
+// NewSessionPool returns a pool of sessions connected to model with config. The
+// pool starts connecting MinSessions sessions in the background. Acquire connects a
+// session itself when none is ready. options may be nil.
+// The live module is experimental.
+func (r *Live) NewSessionPool(model string, config *LiveConnectConfig, options *SessionPoolOptions) *SessionPool {
+	config, err := cloneLiveConnectConfig(config)
+	p := &SessionPool{
+		live:     r,
+		model:    model,
+		config:   config,
+		err:      err,
+		acquired: map[*Session]bool{},
+		release:  make(chan struct{}),
+	}
+	if options != nil {
+		p.options = *options
+	}
+	parent := r.apiClient.closeCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	p.ctx, p.cancel = context.WithCancel(parent)
+	// The pool is closed with the client.
+	context.AfterFunc(p.ctx, func() { p.Close() })
+	if p.options.IdleTimeout > 0 {
+		r.apiClient.goBackground(p.expireReady)
+	}
+	p.refill()
+	return p
+}
+
+// Acquire returns a session that is ready, or connects a new one if there is none.
+// If MaxSessions sessions are open, it waits until one is released or ctx is done.
+// Ready sessions whose connection was terminated are discarded. The session must be
+// returned with Release, and not closed, once the caller is done with it.
+// The live module is experimental.
+func (p *SessionPool) Acquire(ctx context.Context) (*Session, error) {
+	for {
+		p.mu.Lock()
+		// The pool may not be closed yet right after the client is.
+		if p.closed || p.ctx.Err() != nil {
+			p.mu.Unlock()
+			return nil, ErrSessionPoolClosed
+		}
+		if p.err != nil {
+			p.mu.Unlock()
+			return nil, p.err
+		}
+		if len(p.ready) > 0 {
+			s := p.ready[0].session
+			p.ready[0] = readySession{}
+			p.ready = p.ready[1:]
+			if s.IsConnected() {
+				p.acquired[s] = true
+				p.mu.Unlock()
+				p.refill()
+				return s, nil
+			}
+			p.open--
+			p.mu.Unlock()
+			s.Close()
+			continue
+		}
+		if p.options.MaxSessions <= 0 || p.open < p.options.MaxSessions {
+			p.open++
+			p.mu.Unlock()
+			s, err := p.live.Connect(ctx, p.model, p.config)
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if err != nil {
+				p.open--
+				p.signalLocked()
+				return nil, err
+			}
+			p.acquired[s] = true
+			return s, nil
+		}
+		release := p.release
+		p.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-release:
+		}
+	}
+}
+
+// Release closes a session acquired from the pool and lets the pool connect a new
+// one. A session is never reused, since it holds the state of its conversation. A
+// session that was not acquired from the pool, or was already released, is only
+// closed.
+// The live module is experimental.
+func (p *SessionPool) Release(s *Session) {
+	s.Close()
+	p.mu.Lock()
+	if !p.acquired[s] {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.acquired, s)
+	p.open--
+	p.signalLocked()
+	p.mu.Unlock()
+	p.refill()
+}
+
+// Close closes the ready sessions and makes Acquire fail with ErrSessionPoolClosed.
+// Acquired sessions are closed when they are released. It is safe to call Close
+// more than once.
+// The live module is experimental.
+func (p *SessionPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	ready := p.ready
+	p.ready = nil
+	p.open -= len(ready)
+	p.cancel()
+	p.signalLocked()
+	p.mu.Unlock()
+	for _, rs := range ready {
+		rs.session.Close()
+	}
+	return nil
+}
+
+// signalLocked wakes up the Acquire calls waiting for a session. p.mu must be held.
+func (p *SessionPool) signalLocked() {
+	close(p.release)
+	p.release = make(chan struct{})
+}
+
+// refill connects sessions in the background until MinSessions are ready or
+// connecting, without exceeding MaxSessions. It stops at the first error, and is
+// retried on the next Acquire or Release.
+func (p *SessionPool) refill() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.err != nil || p.connecting > 0 || !p.needsSessionLocked() {
+		return
+	}
+	p.connecting++
+	p.open++
+	if !p.live.apiClient.goBackground(p.connectReady) {
+		p.connecting--
+		p.open--
+	}
+}
+
+// needsSessionLocked reports whether the pool should connect another ready
+// session. p.mu must be held.
+func (p *SessionPool) needsSessionLocked() bool {
+	return len(p.ready)+p.connecting < p.options.MinSessions &&
+		(p.options.MaxSessions <= 0 || p.open < p.options.MaxSessions)
+}
+
+// connectReady connects ready sessions for refill, one at a time.
+func (p *SessionPool) connectReady() {
+	for {
+		s, err := p.live.Connect(p.ctx, p.model, p.config)
+		p.mu.Lock()
+		if err != nil || p.closed {
+			p.connecting--
+			p.open--
+			p.signalLocked()
+			p.mu.Unlock()
+			if s != nil {
+				s.Close()
+			}
+			return
+		}
+		p.ready = append(p.ready, readySession{session: s, connected: time.Now()})
+		p.connecting--
+		p.signalLocked()
+		if !p.needsSessionLocked() {
+			p.mu.Unlock()
+			return
+		}
+		p.connecting++
+		p.open++
+		p.mu.Unlock()
+	}
+}
+
+// expireReady replaces the sessions ready for longer than IdleTimeout until the pool
+// is closed.
+func (p *SessionPool) expireReady() {
+	ticker := time.NewTicker(max(p.options.IdleTimeout/2, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.ctx.Done():
+			return
+		case now := <-ticker.C:
+			p.closeExpired(now)
+		}
+	}
+}
+
+// closeExpired closes the sessions connected before now-IdleTimeout and connects new
+// ones to replace them.
+func (p *SessionPool) closeExpired(now time.Time) {
+	p.mu.Lock()
+	var expired []*Session
+	kept := p.ready[:0]
+	for _, rs := range p.ready {
+		if now.Sub(rs.connected) > p.options.IdleTimeout {
+			expired = append(expired, rs.session)
+			continue
+		}
+		kept = append(kept, rs)
+	}
+	clear(p.ready[len(kept):])
+	p.ready = kept
+	p.open -= len(expired)
+	if len(expired) > 0 {
+		p.signalLocked()
+	}
+	p.mu.Unlock()
+	for _, s := range expired {
+		s.Close()
+	}
+	p.refill()
+}
@@ -0,0 +1,290 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// testPoolServer counts the connections made to the test server of a SessionPool.
+type testPoolServer struct {
+	connections atomic.Int32 // Connections made.
+	active      atomic.Int32 // Connections still open.
+}
+
+// newTestSessionPool returns a pool connected to a test server that completes the
+// setup of every connection, then answers each complete turn with the text of all
+// the turns received on the connection, separated by spaces.
+func newTestSessionPool(t *testing.T, options *SessionPoolOptions) (*Client, *SessionPool, *testPoolServer) {
+	t.Helper()
+	server := &testPoolServer{}
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		server.connections.Add(1)
+		server.active.Add(1)
+		defer server.active.Add(-1)
+		mt, _, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := conn.WriteMessage(mt, []byte(`{"setupComplete":{}}`)); err != nil {
+			return
+		}
+		var turns []string
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg LiveClientMessage
+			if err := json.Unmarshal(data, &msg); err != nil || msg.ClientContent == nil {
+				continue
+			}
+			for _, turn := range msg.ClientContent.Turns {
+				for _, part := range turn.Parts {
+					turns = append(turns, part.Text)
+				}
+			}
+			if !msg.ClientContent.TurnComplete {
+				continue
+			}
+			reply, _ := json.Marshal(&LiveServerMessage{ServerContent: &LiveServerContent{
+				ModelTurn:    &Content{Role: RoleModel, Parts: []*Part{{Text: strings.Join(turns, " ")}}},
+				TurnComplete: true,
+			}})
+			if err := conn.WriteMessage(mt, reply); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	client, err := NewClient(context.Background(), &ClientConfig{
+		Backend:     BackendGeminiAPI,
+		APIKey:      "test-api-key",
+		HTTPOptions: HTTPOptions{BaseURL: strings.Replace(ts.URL, "http", "ws", 1)},
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	pool := client.Live.NewSessionPool("test-model", &LiveConnectConfig{}, options)
+	t.Cleanup(func() { pool.Close() })
+	return client, pool, server
+}
+
+// waitFor fails the test if cond is not true within a second.
+func waitFor(t *testing.T, desc string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", desc)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSessionPool(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("pre-warms MinSessions", func(t *testing.T) {
+		_, pool, server := newTestSessionPool(t, &SessionPoolOptions{MinSessions: 2})
+
+		waitFor(t, "2 connections", func() bool { return server.connections.Load() == 2 })
+		if _, err := pool.Acquire(ctx); err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		if _, err := pool.Acquire(ctx); err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		// The acquired sessions are replaced in the background.
+		waitFor(t, "4 connections", func() bool { return server.connections.Load() == 4 })
+		time.Sleep(20 * time.Millisecond)
+		if got := server.connections.Load(); got != 4 {
+			t.Errorf("got %d connections, want 4", got)
+		}
+	})
+
+	t.Run("does not reuse released sessions", func(t *testing.T) {
+		_, pool, _ := newTestSessionPool(t, &SessionPoolOptions{MinSessions: 1, MaxSessions: 1})
+
+		s1, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		// The reply is left unread on the connection.
+		if err := s1.SendText(ctx, "secret"); err != nil {
+			t.Fatalf("SendText() failed: %v", err)
+		}
+		pool.Release(s1)
+		if s1.IsConnected() {
+			t.Error("released session is connected, want closed")
+		}
+
+		s2, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		defer pool.Release(s2)
+		if s2 == s1 {
+			t.Fatal("Acquire() after Release() returned the released session, want a new one")
+		}
+		if err := s2.SendText(ctx, "hello"); err != nil {
+			t.Fatalf("SendText() failed: %v", err)
+		}
+		msg, err := s2.Receive()
+		if err != nil {
+			t.Fatalf("Receive() failed: %v", err)
+		}
+		if got := msg.ServerContent.ModelTurn.Parts[0].Text; got != "hello" {
+			t.Errorf("reply of the new session = %q, want %q without the turns of the previous caller", got, "hello")
+		}
+	})
+
+	t.Run("max sessions", func(t *testing.T) {
+		_, pool, server := newTestSessionPool(t, &SessionPoolOptions{MaxSessions: 1})
+
+		s1, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		if _, err := pool.Acquire(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Acquire() at MaxSessions error = %v, want %v", err, context.DeadlineExceeded)
+		}
+
+		acquired := make(chan *Session)
+		go func() {
+			s, err := pool.Acquire(ctx)
+			if err != nil {
+				t.Errorf("Acquire() failed: %v", err)
+			}
+			acquired <- s
+		}()
+		pool.Release(s1)
+		s2 := <-acquired
+		if s2 == s1 || !s2.IsConnected() {
+			t.Error("waiting Acquire() returned the released session, want a new one")
+		}
+		if got := server.connections.Load(); got != 2 {
+			t.Errorf("got %d connections, want 2", got)
+		}
+	})
+
+	t.Run("unknown and repeated releases", func(t *testing.T) {
+		client, pool, server := newTestSessionPool(t, &SessionPoolOptions{MaxSessions: 1})
+
+		s1, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		pool.Release(s1)
+		pool.Release(s1)
+		other, err := client.Live.Connect(ctx, "test-model", &LiveConnectConfig{})
+		if err != nil {
+			t.Fatalf("Connect() failed: %v", err)
+		}
+		pool.Release(other)
+		if other.IsConnected() {
+			t.Error("session not from the pool is connected after Release(), want closed")
+		}
+
+		// MaxSessions is still enforced.
+		if _, err := pool.Acquire(ctx); err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		if _, err := pool.Acquire(timeoutCtx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Acquire() at MaxSessions error = %v, want %v", err, context.DeadlineExceeded)
+		}
+		if got := server.connections.Load(); got != 3 {
+			t.Errorf("got %d connections, want 3", got)
+		}
+	})
+
+	t.Run("idle timeout", func(t *testing.T) {
+		_, pool, server := newTestSessionPool(t, &SessionPoolOptions{MinSessions: 1, IdleTimeout: 10 * time.Millisecond})
+
+		// The ready session is replaced once it times out.
+		waitFor(t, "a replaced session", func() bool { return server.connections.Load() >= 3 })
+		if got := server.active.Load(); got > 2 {
+			t.Errorf("got %d open connections, want at most 2", got)
+		}
+		s, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		if !s.IsConnected() {
+			t.Error("Acquire() returned a closed session")
+		}
+	})
+
+	t.Run("close", func(t *testing.T) {
+		_, pool, server := newTestSessionPool(t, &SessionPoolOptions{MinSessions: 1})
+
+		acquired, err := pool.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("Acquire() failed: %v", err)
+		}
+		waitFor(t, "a ready session", func() bool { return server.active.Load() == 2 })
+		if err := pool.Close(); err != nil {
+			t.Fatalf("Close() failed: %v", err)
+		}
+		waitFor(t, "the ready session to be closed", func() bool { return server.active.Load() == 1 })
+		if !acquired.IsConnected() {
+			t.Error("acquired session was closed by Close(), want it closed on Release()")
+		}
+		pool.Release(acquired)
+		if acquired.IsConnected() {
+			t.Error("session released after Close() is connected, want closed")
+		}
+		if _, err := pool.Acquire(ctx); !errors.Is(err, ErrSessionPoolClosed) {
+			t.Errorf("Acquire() after Close() error = %v, want %v", err, ErrSessionPoolClosed)
+		}
+		if err := pool.Close(); err != nil {
+			t.Errorf("second Close() failed: %v", err)
+		}
+	})
+
+	t.Run("closed with the client", func(t *testing.T) {
+		client, pool, server := newTestSessionPool(t, &SessionPoolOptions{MinSessions: 1})
+
+		waitFor(t, "a ready session", func() bool { return server.active.Load() == 1 })
+		if err := client.Close(); err != nil {
+			t.Fatalf("Client.Close() failed: %v", err)
+		}
+		if _, err := pool.Acquire(ctx); !errors.Is(err, ErrSessionPoolClosed) {
+			t.Errorf("Acquire() after Client.Close() error = %v, want %v", err, ErrSessionPoolClosed)
+		}
+		waitFor(t, "the ready session to be closed", func() bool { return server.active.Load() == 0 })
+	})
+}
@@ -0,0 +1,164 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"iter"
+)
+
+// StreamAccumulator merges the chunks of a [Models.GenerateContentStream] response
+// into a single response. The zero value is ready to use.
+//
+//	var acc genai.StreamAccumulator
+//	for chunk, err := range client.Models.GenerateContentStream(ctx, model, contents, config) {
+//		if err != nil {
+//			return err
+//		}
+//		acc.Accumulate(chunk)
+//	}
+//	response := acc.Response()
+type StreamAccumulator struct {
+	response GenerateContentResponse
+}
+
+// Accumulate merges the chunk into the accumulated response. Candidates are matched
+// by index. The parts of their contents are appended, with adjacent text parts
+// concatenated, and the other candidate fields are replaced by the non-empty values
+// of the chunk. The model version and prompt feedback of the last chunk that has them
+// are kept. The prompt and cached token counts of the usage metadata are taken from
+// the first chunk that has them and the candidate token counts of all chunks are
+// summed. The chunk is not modified.
+func (a *StreamAccumulator) Accumulate(chunk *GenerateContentResponse) {
+	if chunk == nil {
+		return
+	}
+	for i, c := range chunk.Candidates {
+		if c != nil {
+			a.accumulateCandidate(i, c)
+		}
+	}
+	if chunk.ModelVersion != "" {
+		a.response.ModelVersion = chunk.ModelVersion
+	}
+	if chunk.PromptFeedback != nil {
+		a.response.PromptFeedback = chunk.PromptFeedback
+	}
+	if chunk.UsageMetadata != nil {
+		a.accumulateUsageMetadata(chunk.UsageMetadata)
+	}
+}
+
+func (a *StreamAccumulator) accumulateUsageMetadata(u *GenerateContentResponseUsageMetadata) {
+	acc := a.response.UsageMetadata
+	if acc == nil {
+		acc = &GenerateContentResponseUsageMetadata{}
+		a.response.UsageMetadata = acc
+	}
+	if acc.PromptTokenCount == nil && u.PromptTokenCount != nil {
+		acc.PromptTokenCount = Ptr(*u.PromptTokenCount)
+	}
+	if acc.CachedContentTokenCount == nil && u.CachedContentTokenCount != nil {
+		acc.CachedContentTokenCount = Ptr(*u.CachedContentTokenCount)
+	}
+	if u.CandidatesTokenCount != nil {
+		if acc.CandidatesTokenCount == nil {
+			acc.CandidatesTokenCount = Ptr[int64](0)
+		}
+		*acc.CandidatesTokenCount += *u.CandidatesTokenCount
+	}
+	acc.TotalTokenCount = acc.TotalTokens()
+}
+
+func (a *StreamAccumulator) accumulateCandidate(position int, c *Candidate) {
+	var acc *Candidate
+	for i, candidate := range a.response.Candidates {
+		if candidateIndex(candidate, i) == candidateIndex(c, position) {
+			acc = candidate
+			break
+		}
+	}
+	if acc == nil {
+		acc = &Candidate{Index: c.Index}
+		a.response.Candidates = append(a.response.Candidates, acc)
+	}
+
+	if c.Content != nil {
+		if acc.Content == nil {
+			acc.Content = &Content{}
+		}
+		if c.Content.Role != "" {
+			acc.Content.Role = c.Content.Role
+		}
+		acc.Content.Parts = mergeTextParts(append(acc.Content.Parts, c.Content.Parts...))
+	}
+	if c.CitationMetadata != nil {
+		acc.CitationMetadata = c.CitationMetadata
+	}
+	if c.FinishMessage != "" {
+		acc.FinishMessage = c.FinishMessage
+	}
+	if c.TokenCount != nil {
+		acc.TokenCount = c.TokenCount
+	}
+	if c.AvgLogprobs != nil {
+		acc.AvgLogprobs = c.AvgLogprobs
+	}
+	if c.FinishReason != "" {
+		acc.FinishReason = c.FinishReason
+	}
+	if c.GroundingMetadata != nil {
+		acc.GroundingMetadata = c.GroundingMetadata
+	}
+	if c.LogprobsResult != nil {
+		acc.LogprobsResult = c.LogprobsResult
+	}
+	if c.SafetyRatings != nil {
+		acc.SafetyRatings = c.SafetyRatings
+	}
+}
+
+// candidateIndex returns the index of the candidate, or position if the API did
+// not return one.
+func candidateIndex(c *Candidate, position int) int64 {
+	if c.Index != nil {
+		return *c.Index
+	}
+	return int64(position)
+}
+
+// Response returns the response accumulated so far. The returned response is updated
+// by later calls to Accumulate.
+func (a *StreamAccumulator) Response() *GenerateContentResponse {
+	return &a.response
+}
+
+// CollectStream returns the chunks of a [Models.GenerateContentStream] response, in
+// order, without merging them. It stops at the first error, or when ctx is done, and
+// returns the chunks received before it with the error. It is meant for tests that
+// assert on the chunks; use [StreamAccumulator] to merge them into a response.
+func CollectStream(ctx context.Context, seq iter.Seq2[*GenerateContentResponse, error]) ([]*GenerateContentResponse, error) {
+	var chunks []*GenerateContentResponse
+	for chunk, err := range seq {
+		if err != nil {
+			return chunks, err
+		}
+		if err := ctx.Err(); err != nil {
+			return chunks, err
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStreamAccumulator(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []*GenerateContentResponse
+		want   *GenerateContentResponse
+	}{
+		{
+			name: "No Chunks",
+			want: &GenerateContentResponse{},
+		},
+		{
+			name: "Text Concatenated",
+			chunks: []*GenerateContentResponse{
+				{Candidates: []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{{Text: "Hello"}}}}}, ModelVersion: "gemini-2.0-flash"},
+				{Candidates: []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{{Text: ", "}}}}}},
+				nil,
+				{
+					Candidates:    []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{{Text: "world"}}}, FinishReason: FinishReasonStop}},
+					UsageMetadata: &GenerateContentResponseUsageMetadata{PromptTokenCount: Ptr[int64](3), CandidatesTokenCount: Ptr[int64](4), TotalTokenCount: 7},
+				},
+			},
+			want: &GenerateContentResponse{
+				Candidates:    []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{{Text: "Hello, world"}}}, FinishReason: FinishReasonStop}},
+				ModelVersion:  "gemini-2.0-flash",
+				UsageMetadata: &GenerateContentResponseUsageMetadata{PromptTokenCount: Ptr[int64](3), CandidatesTokenCount: Ptr[int64](4), TotalTokenCount: 7},
+			},
+		},
+		{
+			name: "Function Calls Collected",
+			chunks: []*GenerateContentResponse{
+				{Candidates: []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{{Text: "Let me check."}}}}}},
+				{Candidates: []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{{FunctionCall: &FunctionCall{Name: "getWeather"}}}}}}},
+				{Candidates: []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{{FunctionCall: &FunctionCall{Name: "getTime"}}}}}}},
+			},
+			want: &GenerateContentResponse{
+				Candidates: []*Candidate{{Content: &Content{Role: RoleModel, Parts: []*Part{
+					{Text: "Let me check."},
+					{FunctionCall: &FunctionCall{Name: "getWeather"}},
+					{FunctionCall: &FunctionCall{Name: "getTime"}},
+				}}}},
+			},
+		},
+		{
+			name: "Thoughts Kept Apart",
+			chunks: []*GenerateContentResponse{
+				{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: "Thinking", Thought: true}}}}}},
+				{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: "...", Thought: true}}}}}},
+				{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: "Answer"}}}}}},
+			},
+			want: &GenerateContentResponse{
+				Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: "Thinking...", Thought: true}, {Text: "Answer"}}}}},
+			},
+		},
+		{
+			name: "Candidates Matched By Index",
+			chunks: []*GenerateContentResponse{
+				{Candidates: []*Candidate{
+					{Index: Ptr[int64](0), Content: &Content{Parts: []*Part{{Text: "a"}}}},
+					{Index: Ptr[int64](1), Content: &Content{Parts: []*Part{{Text: "b"}}}},
+				}},
+				{Candidates: []*Candidate{
+					{Index: Ptr[int64](1), Content: &Content{Parts: []*Part{{Text: "B"}}}, FinishReason: FinishReasonMaxTokens},
+				}},
+				{Candidates: []*Candidate{
+					{Index: Ptr[int64](0), Content: &Content{Parts: []*Part{{Text: "A"}}}, FinishReason: FinishReasonStop},
+				}},
+			},
+			want: &GenerateContentResponse{
+				Candidates: []*Candidate{
+					{Index: Ptr[int64](0), Content: &Content{Parts: []*Part{{Text: "aA"}}}, FinishReason: FinishReasonStop},
+					{Index: Ptr[int64](1), Content: &Content{Parts: []*Part{{Text: "bB"}}}, FinishReason: FinishReasonMaxTokens},
+				},
+			},
+		},
+		{
+			name: "Usage Metadata Summed",
+			chunks: []*GenerateContentResponse{
+				{UsageMetadata: &GenerateContentResponseUsageMetadata{PromptTokenCount: Ptr[int64](10), CachedContentTokenCount: Ptr[int64](4), CandidatesTokenCount: Ptr[int64](2), TotalTokenCount: 12}},
+				{UsageMetadata: &GenerateContentResponseUsageMetadata{CandidatesTokenCount: Ptr[int64](3)}},
+				{UsageMetadata: &GenerateContentResponseUsageMetadata{PromptTokenCount: Ptr[int64](99), CandidatesTokenCount: Ptr[int64](5)}},
+			},
+			want: &GenerateContentResponse{
+				UsageMetadata: &GenerateContentResponseUsageMetadata{PromptTokenCount: Ptr[int64](10), CachedContentTokenCount: Ptr[int64](4), CandidatesTokenCount: Ptr[int64](10), TotalTokenCount: 20},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var acc StreamAccumulator
+			for _, chunk := range tt.chunks {
+				acc.Accumulate(chunk)
+			}
+			if diff := cmp.Diff(tt.want, acc.Response()); diff != "" {
+				t.Errorf("Response() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestStreamAccumulatorDoesNotModifyChunks(t *testing.T) {
+	first := &GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: "Hello"}}}}}}
+	second := &GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: " world"}}}}}}
+
+	var acc StreamAccumulator
+	acc.Accumulate(first)
+	acc.Accumulate(second)
+
+	if got := first.Candidates[0].Content.Parts[0].Text; got != "Hello" {
+		t.Errorf("first chunk text = %q, want %q", got, "Hello")
+	}
+	if got, _ := acc.Response().Text(); got != "Hello world" {
+		t.Errorf("Response().Text() = %q, want %q", got, "Hello world")
+	}
+}
+
+func TestCollectStream(t *testing.T) {
+	first := &GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: "Hello"}}}}}}
+	second := &GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: " world"}}}}}}
+	errStream := errors.New("stream error")
+
+	// stream yields the chunks, then err if it is not nil, and records how many
+	// items were requested.
+	stream := func(pulled *int, err error, chunks ...*GenerateContentResponse) iter.Seq2[*GenerateContentResponse, error] {
+		return func(yield func(*GenerateContentResponse, error) bool) {
+			for _, c := range chunks {
+				*pulled++
+				if !yield(c, nil) {
+					return
+				}
+			}
+			if err != nil {
+				*pulled++
+				if !yield(nil, err) {
+					return
+				}
+				*pulled++
+				yield(second, nil)
+			}
+		}
+	}
+
+	t.Run("all chunks", func(t *testing.T) {
+		var pulled int
+		got, err := CollectStream(context.Background(), stream(&pulled, nil, first, second))
+		if err != nil {
+			t.Fatalf("CollectStream() failed: %v", err)
+		}
+		if diff := cmp.Diff([]*GenerateContentResponse{first, second}, got); diff != "" {
+			t.Errorf("CollectStream() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("error in the middle", func(t *testing.T) {
+		var pulled int
+		got, err := CollectStream(context.Background(), stream(&pulled, errStream, first))
+		if !errors.Is(err, errStream) {
+			t.Errorf("CollectStream() error = %v, want %v", err, errStream)
+		}
+		if diff := cmp.Diff([]*GenerateContentResponse{first}, got); diff != "" {
+			t.Errorf("CollectStream() mismatch (-want +got):\n%s", diff)
+		}
+		if pulled != 2 {
+			t.Errorf("CollectStream() pulled %d items, want it to stop after the error at 2", pulled)
+		}
+	})
+
+	t.Run("canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var pulled int
+		got, err := CollectStream(ctx, stream(&pulled, nil, first, second))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("CollectStream() error = %v, want %v", err, context.Canceled)
+		}
+		if len(got) != 0 {
+			t.Errorf("CollectStream() returned %d chunks, want 0", len(got))
+		}
+	})
+}
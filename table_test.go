@@ -18,6 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"path"
 	"path/filepath"
@@ -26,6 +27,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"golang.org/x/oauth2/google"
 )
 
 func snakeToPascal(s string) string {
@@ -111,9 +113,30 @@ func createReplayAPIClient(t *testing.T, testTableDirectory string, testTableIte
 	return replayAPIClient
 }
 
-// TestTable only runs in apiMode or replayMode.
+// createRecordingAPIClient returns a replayAPIClient that records the interactions
+// with the real API of backend to the replay file of testTableItem. The credentials
+// are taken from the environment, as by NewClient.
+func createRecordingAPIClient(ctx context.Context, t *testing.T, testTableDirectory string, testTableItem *testTableItem, backend Backend, backendName string) *replayAPIClient {
+	t.Helper()
+	replayFileName := testTableItem.Name
+	if testTableItem.OverrideReplayID != "" {
+		replayFileName = testTableItem.OverrideReplayID
+	}
+	replayFilePath := path.Join(testTableDirectory, fmt.Sprintf("%s.%s.json", replayFileName, backendName))
+	if backend == BackendVertexAI {
+		upstream, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/cloud-platform")
+		if err != nil {
+			t.Fatalf("error finding default credentials: %v", err)
+		}
+		upstreamBaseURL := fmt.Sprintf("https://%s-aiplatform.googleapis.com", os.Getenv("GOOGLE_CLOUD_LOCATION"))
+		return newRecordingAPIClient(t, replayFilePath, upstreamBaseURL, upstream)
+	}
+	return newRecordingAPIClient(t, replayFilePath, "https://generativelanguage.googleapis.com", http.DefaultClient)
+}
+
+// TestTable only runs in apiMode, replayMode or recordMode.
 func TestTable(t *testing.T) {
-	if *mode != apiMode && *mode != replayMode {
+	if *mode != apiMode && *mode != replayMode && *mode != recordMode {
 		t.Skipf("Skipping test table because client env mode is enabled and affect environment variables")
 	}
 	ctx := context.Background()
@@ -149,15 +172,23 @@ func TestTable(t *testing.T) {
 								t.Skipf("Skipping because it has union")
 							}
 							config := ClientConfig{Backend: backend.Backend}
-							replayClient := createReplayAPIClient(t, testTableDirectory, testTableItem, backend.name)
-							if *mode == "replay" {
+							var replayClient *replayAPIClient
+							if *mode == recordMode {
+								replayClient = createRecordingAPIClient(ctx, t, testTableDirectory, testTableItem, backend.Backend, backend.name)
+							} else {
+								replayClient = createReplayAPIClient(t, testTableDirectory, testTableItem, backend.name)
+							}
+							if *mode == replayMode || *mode == recordMode {
 								config.HTTPOptions.BaseURL = replayClient.GetBaseURL()
 								config.HTTPClient, err = replayClient.CreateClient(ctx)
 							}
-							if backend.Backend == BackendVertexAI {
+							switch {
+							case *mode == recordMode:
+								// The real credentials are read from the environment by NewClient.
+							case backend.Backend == BackendVertexAI:
 								config.Project = "fake-project"
 								config.Location = "fake-location"
-							} else {
+							default:
 								config.APIKey = "fake-api-key"
 							}
 							client, err := NewClient(ctx, &config)
@@ -169,7 +200,9 @@ func TestTable(t *testing.T) {
 
 							// Inject unknown fields to the replay file to simulate the case where the SDK adds
 							// unknown fields to the response.
-							injectUnknownFields(t, replayClient)
+							if *mode != recordMode {
+								injectUnknownFields(t, replayClient)
+							}
 
 							response := method.Call(args)
 							wantException := extractWantException(testTableItem, backend.Backend)
@@ -192,6 +225,10 @@ func TestTable(t *testing.T) {
 								}
 								// Assert the response when the call is successful.
 								got := convertSDKResponseToMatchReplayType(t, response[0].Elem().Interface())
+								if *mode == recordMode {
+									replayClient.SetSDKResponse(got)
+									return
+								}
 								want := replayClient.LatestInteraction().Response.SDKResponseSegments
 								opts := cmp.Options{stringComparator}
 								if diff := cmp.Diff(got, want, opts); diff != "" {
@@ -223,35 +260,83 @@ func convertSDKResponseToMatchReplayType(t *testing.T, response any) []map[strin
 	return responseMap
 }
 
+// maxInjectDepth is the nesting depth below which injectUnknownFields stops
+// recursing, so that deeply nested responses don't overflow the stack.
+const maxInjectDepth = 20
+
 func injectUnknownFields(t *testing.T, replayClient *replayAPIClient) {
 	t.Helper()
-	var inject func(in any) int
-	inject = func(in any) int {
-		counter := 0
-		switch in.(type) {
-		case map[string]any:
-			m := in.(map[string]any)
-			for _, v := range m {
-				inject(v)
-			}
-			m["unknownFieldString"] = "unknownValue"
-			m["unknownFieldNumber"] = 0
-			m["unknownFieldMap"] = map[string]any{"unknownFieldString": "unknownValue"}
-			m["unknownFieldArray"] = []any{map[string]any{"unknownFieldString": "unknownValue"}}
-			counter++
-		case []any:
-			for _, v := range in.([]any) {
-				inject(v)
-			}
-		}
-		return counter
-	}
 	for _, interaction := range replayClient.ReplayFile.Interactions {
 		for _, bodySegment := range interaction.Response.BodySegments {
 			// This ensures that the injection actually happened to avoid false positives test results.
-			if inject(bodySegment) == 0 {
+			if injectUnknownFieldsInto(bodySegment, 0, maxInjectDepth, map[uintptr]bool{}) == 0 {
 				t.Fatal("No unknown fields were injected. There must be at least one unknown field added to the body segments.")
 			}
 		}
 	}
 }
+
+// injectUnknownFieldsInto adds unknown fields to the maps nested in in, up to
+// maxDepth levels deep, and returns the number of maps modified. Maps already in
+// seen are skipped, which stops the recursion on circular structures.
+func injectUnknownFieldsInto(in any, depth, maxDepth int, seen map[uintptr]bool) int {
+	if depth > maxDepth {
+		return 0
+	}
+	counter := 0
+	switch v := in.(type) {
+	case map[string]any:
+		p := reflect.ValueOf(v).Pointer()
+		if seen[p] {
+			return 0
+		}
+		seen[p] = true
+		for _, e := range v {
+			counter += injectUnknownFieldsInto(e, depth+1, maxDepth, seen)
+		}
+		v["unknownFieldString"] = "unknownValue"
+		v["unknownFieldNumber"] = 0
+		v["unknownFieldMap"] = map[string]any{"unknownFieldString": "unknownValue"}
+		v["unknownFieldArray"] = []any{map[string]any{"unknownFieldString": "unknownValue"}}
+		counter++
+	case []any:
+		for _, e := range v {
+			counter += injectUnknownFieldsInto(e, depth+1, maxDepth, seen)
+		}
+	}
+	return counter
+}
+
+func TestInjectUnknownFields(t *testing.T) {
+	t.Run("deep", func(t *testing.T) {
+		root := map[string]any{}
+		m := root
+		for i := 0; i < 10000; i++ {
+			child := map[string]any{}
+			m["child"] = []any{child}
+			m = child
+		}
+		// Maps are at every other level, below a list each.
+		if got, want := injectUnknownFieldsInto(root, 0, maxInjectDepth, map[uintptr]bool{}), maxInjectDepth/2+1; got != want {
+			t.Errorf("injectUnknownFieldsInto() = %d, want %d", got, want)
+		}
+		if _, ok := root["unknownFieldString"]; !ok {
+			t.Error("injectUnknownFieldsInto() did not inject unknown fields at the root")
+		}
+	})
+
+	t.Run("circular", func(t *testing.T) {
+		a := map[string]any{}
+		b := map[string]any{"parent": a}
+		a["child"] = b
+		a["children"] = []any{b, a}
+		if got, want := injectUnknownFieldsInto(a, 0, maxInjectDepth, map[uintptr]bool{}), 2; got != want {
+			t.Errorf("injectUnknownFieldsInto() = %d, want %d", got, want)
+		}
+		for name, m := range map[string]map[string]any{"a": a, "b": b} {
+			if _, ok := m["unknownFieldString"]; !ok {
+				t.Errorf("injectUnknownFieldsInto() did not inject unknown fields in %s", name)
+			}
+		}
+	})
+}
@@ -16,7 +16,9 @@ package genai
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
+	"unicode/utf8"
 )
 
 func tResourceName(ac *apiClient, resourceName string, collectionIdentifier string, collectionHierarchyDepth int) string {
@@ -49,33 +51,167 @@ func tCachedContentName(ac *apiClient, name any) (string, error) {
 	return tResourceName(ac, name.(string), "cachedContents", 2), nil
 }
 
+// tFileName returns the "files/{id}" resource name of a file given its ID, its
+// resource name or its URI.
+func tFileName(_ *apiClient, name any) (string, error) {
+	n, ok := name.(string)
+	if !ok {
+		return "", fmt.Errorf("tFileName: name is not a string")
+	}
+	if i := strings.LastIndex(n, "files/"); i >= 0 {
+		n = n[i+len("files/"):]
+	}
+	if n == "" {
+		return "", fmt.Errorf("tFileName: name is empty")
+	}
+	return "files/" + n, nil
+}
+
+// tTuningJobName returns the resource name of a tuning job: a tuning job in Vertex
+// AI, and a tuned model in Gemini API.
+func tTuningJobName(ac *apiClient, name any) (string, error) {
+	n, ok := name.(string)
+	if !ok {
+		return "", fmt.Errorf("tTuningJobName: name is not a string")
+	}
+	if n == "" {
+		return "", fmt.Errorf("tTuningJobName: name is empty")
+	}
+	if ac.clientConfig.Backend == BackendVertexAI {
+		return tResourceName(ac, n, "tuningJobs", 2), nil
+	}
+	return tResourceName(ac, n, "tunedModels", 2), nil
+}
+
+// tTuningJobState maps the state of a Gemini API tuned model to the state of a
+// tuning job.
+func tTuningJobState(_ *apiClient, state any) (any, error) {
+	switch state {
+	case "CREATING":
+		return JobStateRunning, nil
+	case "ACTIVE":
+		return JobStateSucceeded, nil
+	case "FAILED":
+		return JobStateFailed, nil
+	default:
+		return JobStateUnspecified, nil
+	}
+}
+
+// tCandidateIndex checks that the index of a response candidate is non-negative.
+func tCandidateIndex(_ *apiClient, index any) (any, error) {
+	if i, ok := index.(float64); ok && i < 0 {
+		return nil, fmt.Errorf("tCandidateIndex: candidate index %v is negative", i)
+	}
+	return index, nil
+}
+
+// tDynamicRetrievalConfig checks the mode and the threshold of a dynamic retrieval
+// config.
+func tDynamicRetrievalConfig(_ *apiClient, config any) (any, error) {
+	m, ok := config.(map[string]any)
+	if !ok {
+		return config, nil
+	}
+	c := new(DynamicRetrievalConfig)
+	if err := mapToStruct(m, c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// tCandidateCount checks that the requested number of candidates is at most
+// MaxCandidateCount.
+func tCandidateCount(_ *apiClient, count any) (any, error) {
+	if n, ok := count.(float64); ok && n > MaxCandidateCount {
+		return nil, newInvalidArgumentError("candidate count %v exceeds the maximum of %d", n, MaxCandidateCount)
+	}
+	return count, nil
+}
+
+// tStopSequences checks that there are at most MaxStopSequences stop sequences, and
+// that each one is non-empty and at most MaxStopSequenceLength characters long.
+func tStopSequences(_ *apiClient, sequences any) (any, error) {
+	seqs, ok := sequences.([]any)
+	if !ok {
+		return sequences, nil
+	}
+	if len(seqs) > MaxStopSequences {
+		return nil, newInvalidArgumentError("got %d stop sequences, which exceeds the maximum of %d", len(seqs), MaxStopSequences)
+	}
+	for i, seq := range seqs {
+		str, _ := seq.(string)
+		if str == "" {
+			return nil, newInvalidArgumentError("stop sequence %d is empty", i)
+		}
+		if n := utf8.RuneCountInString(str); n > MaxStopSequenceLength {
+			return nil, newInvalidArgumentError("stop sequence %d is %d characters long, which exceeds the maximum of %d", i, n, MaxStopSequenceLength)
+		}
+	}
+	return sequences, nil
+}
+
+// tResponseMIMEType checks that the response MIME type is one of MIMETypeText,
+// MIMETypeJSON and MIMETypeEnum.
+func tResponseMIMEType(_ *apiClient, mimeType any) (any, error) {
+	switch mimeType {
+	case MIMETypeText, MIMETypeJSON, MIMETypeEnum:
+		return mimeType, nil
+	}
+	return nil, newInvalidArgumentError("unsupported response MIME type %q, want %q, %q or %q; set AllowCustomMIMEType to send it anyway", mimeType, MIMETypeText, MIMETypeJSON, MIMETypeEnum)
+}
+
+// maxCachedModelNames is the number of model names cached by tModel. Names are not
+// cached once it is reached, so that a client that is passed arbitrary model names
+// does not grow without bound.
+const maxCachedModelNames = 64
+
+// tModel returns the resource name of a model. The names are cached by the client,
+// since the same few models are used for every request.
 func tModel(ac *apiClient, origin any) (string, error) {
 	switch model := origin.(type) {
 	case string:
 		if model == "" {
 			return "", fmt.Errorf("tModel: model is empty")
 		}
-		if ac.clientConfig.Backend == BackendVertexAI {
-			if strings.HasPrefix(model, "projects/") || strings.HasPrefix(model, "models/") || strings.HasPrefix(model, "publishers/") {
-				return model, nil
-			} else if strings.Contains(model, "/") {
-				parts := strings.SplitN(model, "/", 2)
-				return fmt.Sprintf("publishers/%s/models/%s", parts[0], parts[1]), nil
-			} else {
-				return fmt.Sprintf("publishers/google/models/%s", model), nil
-			}
-		} else {
-			if strings.HasPrefix(model, "models/") || strings.HasPrefix(model, "tunedModels/") {
-				return model, nil
-			} else {
-				return fmt.Sprintf("models/%s", model), nil
+		if name, ok := ac.modelNames.Load(model); ok {
+			return name.(string), nil
+		}
+		name := modelResourceName(ac.clientConfig.Backend, model)
+		if ac.modelNamesLen.Load() < maxCachedModelNames {
+			if _, loaded := ac.modelNames.LoadOrStore(model, name); !loaded {
+				ac.modelNamesLen.Add(1)
 			}
 		}
+		return name, nil
 	default:
 		return "", fmt.Errorf("tModel: model is not a string")
 	}
 }
 
+// modelResourceName returns the resource name of a non-empty model for backend.
+func modelResourceName(backend Backend, model string) string {
+	if backend == BackendVertexAI {
+		if strings.HasPrefix(model, "projects/") || strings.HasPrefix(model, "models/") || strings.HasPrefix(model, "publishers/") {
+			return model
+		} else if strings.Contains(model, "/") {
+			parts := strings.SplitN(model, "/", 2)
+			return fmt.Sprintf("publishers/%s/models/%s", parts[0], parts[1])
+		} else {
+			return fmt.Sprintf("publishers/google/models/%s", model)
+		}
+	} else {
+		if strings.HasPrefix(model, "models/") || strings.HasPrefix(model, "tunedModels/") {
+			return model
+		} else {
+			return fmt.Sprintf("models/%s", model)
+		}
+	}
+}
+
 func tModelFullName(ac *apiClient, origin any) (string, error) {
 	switch model := origin.(type) {
 	case string:
@@ -103,7 +239,28 @@ func tContent(_ *apiClient, content any) (any, error) {
 	return content, nil
 }
 
+func tSystemInstructionText(_ *apiClient, text any) (any, error) {
+	return map[string]any{"parts": []any{map[string]any{"text": text}}}, nil
+}
+
+// tContents checks that the role of each content is RoleUser or RoleModel. An empty
+// role is left to the API.
 func tContents(_ *apiClient, contents any) (any, error) {
+	list, ok := contents.([]any)
+	if !ok {
+		return contents, nil
+	}
+	for i, c := range list {
+		m, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch role := m["role"]; role {
+		case nil, "", RoleUser, RoleModel:
+		default:
+			return nil, newInvalidArgumentError("contents[%d] has role %q, want %q or %q", i, role, RoleUser, RoleModel)
+		}
+	}
 	return contents, nil
 }
 
@@ -115,6 +272,18 @@ func tTools(_ *apiClient, tools any) (any, error) {
 	return tools, nil
 }
 
+// tAutoToolConfig returns the tool config selecting the AUTO function calling mode
+// if any of tools has function declarations, and nil otherwise.
+func tAutoToolConfig(_ *apiClient, tools any) (any, error) {
+	list, _ := tools.([]any)
+	for _, tool := range list {
+		if t, ok := tool.(map[string]any); ok && getValueByPath(t, []string{"functionDeclarations"}) != nil {
+			return map[string]any{"functionCallingConfig": map[string]any{"mode": string(FunctionCallingConfigModeAuto)}}, nil
+		}
+	}
+	return nil, nil
+}
+
 func tSchema(_ *apiClient, origin any) (any, error) {
 	return origin, nil
 }
@@ -127,3 +296,46 @@ func tBytes(_ *apiClient, fromImageBytes any) (any, error) {
 	// TODO(b/389133914): Remove dummy bytes converter.
 	return fromImageBytes, nil
 }
+
+// tContentForEmbed converts a content into the plain text instance expected by the
+// Vertex AI text embedding models.
+func tContentForEmbed(_ *apiClient, content any) (any, error) {
+	c, ok := content.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("tContentForEmbed: content is not an object")
+	}
+	parts, _ := c["parts"].([]any)
+	var text strings.Builder
+	for i, p := range parts {
+		part, ok := p.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("tContentForEmbed: part %d is not an object", i)
+		}
+		for k := range part {
+			if k != "text" {
+				return nil, fmt.Errorf("tContentForEmbed: part %d has unsupported field %q, only text parts can be embedded in Vertex AI", i, k)
+			}
+		}
+		s, _ := part["text"].(string)
+		text.WriteString(s)
+	}
+	return text.String(), nil
+}
+
+// tEmbedParameters removes the per-request parameters from the given Vertex AI
+// embedding instances and returns the parameters shared by all of them.
+func tEmbedParameters(_ *apiClient, instances []map[string]any) (map[string]any, error) {
+	var parameters map[string]any
+	for i, instance := range instances {
+		p, _ := instance["_parameters"].(map[string]any)
+		delete(instance, "_parameters")
+		if len(p) == 0 {
+			p = nil
+		}
+		if i > 0 && !reflect.DeepEqual(p, parameters) {
+			return nil, fmt.Errorf("tEmbedParameters: all requests must use the same output_dimensionality in Vertex AI")
+		}
+		parameters = p
+	}
+	return parameters, nil
+}
@@ -15,6 +15,7 @@
 package genai
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -135,3 +136,65 @@ func TestModelTransformer(t *testing.T) {
 		}
 	})
 }
+
+func TestModelTransformerCache(t *testing.T) {
+	newClient := func(backend Backend) *apiClient {
+		return &apiClient{clientConfig: &ClientConfig{Backend: backend, Project: "test-project", Location: "test-location"}}
+	}
+	vertex, gemini := newClient(BackendVertexAI), newClient(BackendGeminiAPI)
+	for i := 0; i < 2; i++ {
+		if got, err := tModel(vertex, "gemini-2.0-flash"); err != nil || got != "publishers/google/models/gemini-2.0-flash" {
+			t.Errorf("call %d: tModel() on Vertex AI = %q, %v, want %q", i, got, err, "publishers/google/models/gemini-2.0-flash")
+		}
+		if got, err := tModel(gemini, "gemini-2.0-flash"); err != nil || got != "models/gemini-2.0-flash" {
+			t.Errorf("call %d: tModel() on the Gemini API = %q, %v, want %q", i, got, err, "models/gemini-2.0-flash")
+		}
+	}
+	if got, ok := vertex.modelNames.Load("gemini-2.0-flash"); !ok || got != "publishers/google/models/gemini-2.0-flash" {
+		t.Errorf("cached Vertex AI name = %v, %v, want %q", got, ok, "publishers/google/models/gemini-2.0-flash")
+	}
+
+	// Errors are not cached.
+	if _, err := tModel(vertex, ""); err == nil {
+		t.Error("tModel() with an empty model succeeded, want error")
+	}
+	if _, ok := vertex.modelNames.Load(""); ok {
+		t.Error("the empty model is cached, want not cached")
+	}
+
+	// Names are still resolved, but no longer cached, once the cache is full.
+	for i := 0; i < 2*maxCachedModelNames; i++ {
+		model := fmt.Sprintf("model-%d", i)
+		if got, err := tModel(gemini, model); err != nil || got != "models/"+model {
+			t.Errorf("tModel(%q) = %q, %v, want %q", model, got, err, "models/"+model)
+		}
+	}
+	var cached int
+	gemini.modelNames.Range(func(_, _ any) bool {
+		cached++
+		return true
+	})
+	if cached != maxCachedModelNames {
+		t.Errorf("got %d cached names, want %d", cached, maxCachedModelNames)
+	}
+}
+
+func BenchmarkTModel(b *testing.B) {
+	for _, backend := range []Backend{BackendGeminiAPI, BackendVertexAI} {
+		b.Run(backend.String()+"/cached", func(b *testing.B) {
+			ac := &apiClient{clientConfig: &ClientConfig{Backend: backend}}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := tModel(ac, "gemini-2.0-flash"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(backend.String()+"/uncached", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				modelResourceName(backend, "gemini-2.0-flash")
+			}
+		})
+	}
+}
@@ -0,0 +1,744 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+)
+
+func tuningDatasetToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+	if getValueByPath(fromObject, []string{"gcsUri"}) != nil {
+		return nil, fmt.Errorf("gcsUri parameter is not supported in Gemini API")
+	}
+
+	fromExamples := getValueByPath(fromObject, []string{"examples"})
+	if fromExamples != nil {
+		setValueByPath(toObject, []string{"examples", "examples"}, fromExamples)
+	}
+
+	return toObject, nil
+}
+
+func tuningDatasetToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromGCSURI := getValueByPath(fromObject, []string{"gcsUri"})
+	if fromGCSURI != nil {
+		setValueByPath(parentObject, []string{"supervisedTuningSpec", "trainingDatasetUri"}, fromGCSURI)
+	}
+
+	if getValueByPath(fromObject, []string{"examples"}) != nil {
+		return nil, fmt.Errorf("examples parameter is not supported in Vertex AI")
+	}
+
+	return toObject, nil
+}
+
+func tuningHyperParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromEpochCount := getValueByPath(fromObject, []string{"epochCount"})
+	if fromEpochCount != nil {
+		setValueByPath(toObject, []string{"epochCount"}, fromEpochCount)
+	}
+
+	fromLearningRateMultiplier := getValueByPath(fromObject, []string{"learningRateMultiplier"})
+	if fromLearningRateMultiplier != nil {
+		setValueByPath(toObject, []string{"learningRateMultiplier"}, fromLearningRateMultiplier)
+	}
+
+	fromBatchSize := getValueByPath(fromObject, []string{"batchSize"})
+	if fromBatchSize != nil {
+		setValueByPath(toObject, []string{"batchSize"}, fromBatchSize)
+	}
+
+	fromLearningRate := getValueByPath(fromObject, []string{"learningRate"})
+	if fromLearningRate != nil {
+		setValueByPath(toObject, []string{"learningRate"}, fromLearningRate)
+	}
+
+	return toObject, nil
+}
+
+func tuningHyperParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromEpochCount := getValueByPath(fromObject, []string{"epochCount"})
+	if fromEpochCount != nil {
+		setValueByPath(toObject, []string{"epochCount"}, fromEpochCount)
+	}
+
+	fromLearningRateMultiplier := getValueByPath(fromObject, []string{"learningRateMultiplier"})
+	if fromLearningRateMultiplier != nil {
+		setValueByPath(toObject, []string{"learningRateMultiplier"}, fromLearningRateMultiplier)
+	}
+
+	if getValueByPath(fromObject, []string{"batchSize"}) != nil {
+		return nil, fmt.Errorf("batchSize parameter is not supported in Vertex AI")
+	}
+
+	if getValueByPath(fromObject, []string{"learningRate"}) != nil {
+		return nil, fmt.Errorf("learningRate parameter is not supported in Vertex AI")
+	}
+
+	return toObject, nil
+}
+
+func createTuningJobConfigToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromBaseModel := getValueByPath(fromObject, []string{"baseModel"})
+	if fromBaseModel != nil {
+		fromBaseModel, err = tModel(ac, fromBaseModel)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(parentObject, []string{"baseModel"}, fromBaseModel)
+	}
+
+	fromTrainingDataset := getValueByPath(fromObject, []string{"trainingDataset"})
+	if fromTrainingDataset != nil {
+		fromTrainingDataset, err = tuningDatasetToMldev(ac, fromTrainingDataset.(map[string]any), parentObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(parentObject, []string{"tuningTask", "trainingData"}, fromTrainingDataset)
+	}
+
+	fromHyperParameters := getValueByPath(fromObject, []string{"hyperParameters"})
+	if fromHyperParameters != nil {
+		fromHyperParameters, err = tuningHyperParametersToMldev(ac, fromHyperParameters.(map[string]any), parentObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(parentObject, []string{"tuningTask", "hyperparameters"}, fromHyperParameters)
+	}
+
+	fromTunedModelDisplayName := getValueByPath(fromObject, []string{"tunedModelDisplayName"})
+	if fromTunedModelDisplayName != nil {
+		setValueByPath(parentObject, []string{"displayName"}, fromTunedModelDisplayName)
+	}
+
+	return toObject, nil
+}
+
+func createTuningJobConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromBaseModel := getValueByPath(fromObject, []string{"baseModel"})
+	if fromBaseModel != nil {
+		setValueByPath(parentObject, []string{"baseModel"}, fromBaseModel)
+	}
+
+	fromTrainingDataset := getValueByPath(fromObject, []string{"trainingDataset"})
+	if fromTrainingDataset != nil {
+		_, err = tuningDatasetToVertex(ac, fromTrainingDataset.(map[string]any), parentObject)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fromHyperParameters := getValueByPath(fromObject, []string{"hyperParameters"})
+	if fromHyperParameters != nil {
+		fromHyperParameters, err = tuningHyperParametersToVertex(ac, fromHyperParameters.(map[string]any), parentObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(parentObject, []string{"supervisedTuningSpec", "hyperParameters"}, fromHyperParameters)
+	}
+
+	fromTunedModelDisplayName := getValueByPath(fromObject, []string{"tunedModelDisplayName"})
+	if fromTunedModelDisplayName != nil {
+		setValueByPath(parentObject, []string{"tunedModelDisplayName"}, fromTunedModelDisplayName)
+	}
+
+	return toObject, nil
+}
+
+func createTuningJobParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = createTuningJobConfigToMldev(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func createTuningJobParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = createTuningJobConfigToVertex(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func getTuningJobParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		fromName, err = tTuningJobName(ac, fromName)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"_url", "name"}, fromName)
+	}
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func getTuningJobParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		fromName, err = tTuningJobName(ac, fromName)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"_url", "name"}, fromName)
+	}
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func cancelTuningJobParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		fromName, err = tTuningJobName(ac, fromName)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"_url", "name"}, fromName)
+	}
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func listTuningJobsConfigToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromPageSize := getValueByPath(fromObject, []string{"pageSize"})
+	if fromPageSize != nil {
+		setValueByPath(parentObject, []string{"_query", "pageSize"}, fromPageSize)
+	}
+
+	fromPageToken := getValueByPath(fromObject, []string{"pageToken"})
+	if fromPageToken != nil {
+		setValueByPath(parentObject, []string{"_query", "pageToken"}, fromPageToken)
+	}
+
+	fromFilter := getValueByPath(fromObject, []string{"filter"})
+	if fromFilter != nil {
+		setValueByPath(parentObject, []string{"_query", "filter"}, fromFilter)
+	}
+
+	return toObject, nil
+}
+
+func listTuningJobsConfigToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromPageSize := getValueByPath(fromObject, []string{"pageSize"})
+	if fromPageSize != nil {
+		setValueByPath(parentObject, []string{"_query", "pageSize"}, fromPageSize)
+	}
+
+	fromPageToken := getValueByPath(fromObject, []string{"pageToken"})
+	if fromPageToken != nil {
+		setValueByPath(parentObject, []string{"_query", "pageToken"}, fromPageToken)
+	}
+
+	fromFilter := getValueByPath(fromObject, []string{"filter"})
+	if fromFilter != nil {
+		setValueByPath(parentObject, []string{"_query", "filter"}, fromFilter)
+	}
+
+	return toObject, nil
+}
+
+func listTuningJobsParametersToMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = listTuningJobsConfigToMldev(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+func listTuningJobsParametersToVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromConfig := getValueByPath(fromObject, []string{"config"})
+	if fromConfig != nil {
+		fromConfig, err = listTuningJobsConfigToVertex(ac, fromConfig.(map[string]any), toObject)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"config"}, fromConfig)
+	}
+
+	return toObject, nil
+}
+
+// tuningJobFromMldev converts a Gemini API tuned model to a tuning job.
+func tuningJobFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		setValueByPath(toObject, []string{"name"}, fromName)
+		setValueByPath(toObject, []string{"tunedModelName"}, fromName)
+	}
+
+	fromState := getValueByPath(fromObject, []string{"state"})
+	if fromState != nil {
+		fromState, err = tTuningJobState(ac, fromState)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"state"}, fromState)
+	}
+
+	fromBaseModel := getValueByPath(fromObject, []string{"baseModel"})
+	if fromBaseModel != nil {
+		setValueByPath(toObject, []string{"baseModel"}, fromBaseModel)
+	}
+
+	fromCreateTime := getValueByPath(fromObject, []string{"createTime"})
+	if fromCreateTime != nil {
+		setValueByPath(toObject, []string{"createTime"}, fromCreateTime)
+	}
+
+	fromStartTime := getValueByPath(fromObject, []string{"tuningTask", "startTime"})
+	if fromStartTime != nil {
+		setValueByPath(toObject, []string{"startTime"}, fromStartTime)
+	}
+
+	fromEndTime := getValueByPath(fromObject, []string{"tuningTask", "completeTime"})
+	if fromEndTime != nil {
+		setValueByPath(toObject, []string{"endTime"}, fromEndTime)
+	}
+
+	fromUpdateTime := getValueByPath(fromObject, []string{"updateTime"})
+	if fromUpdateTime != nil {
+		setValueByPath(toObject, []string{"updateTime"}, fromUpdateTime)
+	}
+
+	return toObject, nil
+}
+
+func tuningJobFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromName := getValueByPath(fromObject, []string{"name"})
+	if fromName != nil {
+		setValueByPath(toObject, []string{"name"}, fromName)
+	}
+
+	fromState := getValueByPath(fromObject, []string{"state"})
+	if fromState != nil {
+		setValueByPath(toObject, []string{"state"}, fromState)
+	}
+
+	fromError := getValueByPath(fromObject, []string{"error"})
+	if fromError != nil {
+		setValueByPath(toObject, []string{"error"}, fromError)
+	}
+
+	fromBaseModel := getValueByPath(fromObject, []string{"baseModel"})
+	if fromBaseModel != nil {
+		setValueByPath(toObject, []string{"baseModel"}, fromBaseModel)
+	}
+
+	fromTunedModelName := getValueByPath(fromObject, []string{"tunedModel", "model"})
+	if fromTunedModelName != nil {
+		setValueByPath(toObject, []string{"tunedModelName"}, fromTunedModelName)
+	}
+
+	fromCreateTime := getValueByPath(fromObject, []string{"createTime"})
+	if fromCreateTime != nil {
+		setValueByPath(toObject, []string{"createTime"}, fromCreateTime)
+	}
+
+	fromStartTime := getValueByPath(fromObject, []string{"startTime"})
+	if fromStartTime != nil {
+		setValueByPath(toObject, []string{"startTime"}, fromStartTime)
+	}
+
+	fromEndTime := getValueByPath(fromObject, []string{"endTime"})
+	if fromEndTime != nil {
+		setValueByPath(toObject, []string{"endTime"}, fromEndTime)
+	}
+
+	fromUpdateTime := getValueByPath(fromObject, []string{"updateTime"})
+	if fromUpdateTime != nil {
+		setValueByPath(toObject, []string{"updateTime"}, fromUpdateTime)
+	}
+
+	return toObject, nil
+}
+
+// tuningOperationFromMldev converts the long-running operation returned when a
+// tuned model is created in Gemini API to a queued tuning job.
+func tuningOperationFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromTunedModel := getValueByPath(fromObject, []string{"metadata", "tunedModel"})
+	if fromTunedModel != nil {
+		setValueByPath(toObject, []string{"name"}, fromTunedModel)
+		setValueByPath(toObject, []string{"tunedModelName"}, fromTunedModel)
+	}
+	setValueByPath(toObject, []string{"state"}, JobStateQueued)
+
+	return toObject, nil
+}
+
+func listTuningJobsResponseFromMldev(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromNextPageToken := getValueByPath(fromObject, []string{"nextPageToken"})
+	if fromNextPageToken != nil {
+		setValueByPath(toObject, []string{"nextPageToken"}, fromNextPageToken)
+	}
+
+	fromTuningJobs := getValueByPath(fromObject, []string{"tunedModels"})
+	if fromTuningJobs != nil {
+		fromTuningJobs, err = applyConverterToSlice(ac, fromTuningJobs.([]any), tuningJobFromMldev)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"tuningJobs"}, fromTuningJobs)
+	}
+
+	return toObject, nil
+}
+
+func listTuningJobsResponseFromVertex(ac *apiClient, fromObject map[string]any, parentObject map[string]any) (toObject map[string]any, err error) {
+	toObject = make(map[string]any)
+
+	fromNextPageToken := getValueByPath(fromObject, []string{"nextPageToken"})
+	if fromNextPageToken != nil {
+		setValueByPath(toObject, []string{"nextPageToken"}, fromNextPageToken)
+	}
+
+	fromTuningJobs := getValueByPath(fromObject, []string{"tuningJobs"})
+	if fromTuningJobs != nil {
+		fromTuningJobs, err = applyConverterToSlice(ac, fromTuningJobs.([]any), tuningJobFromVertex)
+		if err != nil {
+			return nil, err
+		}
+
+		setValueByPath(toObject, []string{"tuningJobs"}, fromTuningJobs)
+	}
+
+	return toObject, nil
+}
+
+// TuningJobs provides methods for supervised fine-tuning of models. In Vertex AI,
+// they manage tuning jobs. In Gemini API, they manage tuned models, each of which
+// is presented as the tuning job that produces it.
+// You don't need to initiate this struct. Create a client instance via NewClient, and
+// then access TuningJobs through client.TuningJobs field.
+type TuningJobs struct {
+	apiClient *apiClient
+}
+
+// Create starts a supervised fine-tuning job of config.BaseModel on
+// config.TrainingDataset. The job runs asynchronously; use Get to follow its state.
+func (m TuningJobs) Create(ctx context.Context, config *CreateTuningJobConfig) (*TuningJob, error) {
+	if config == nil || config.BaseModel == "" {
+		return nil, fmt.Errorf("Create: config.BaseModel is required")
+	}
+	if config.TrainingDataset == nil {
+		return nil, fmt.Errorf("Create: config.TrainingDataset is required")
+	}
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	httpOptions := mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+
+	var response = new(TuningJob)
+	var responseMap map[string]any
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = createTuningJobParametersToVertex
+		fromConverter = tuningJobFromVertex
+	} else {
+		toConverter = createTuningJobParametersToMldev
+		fromConverter = tuningOperationFromMldev
+	}
+
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var path string
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		path = "tuningJobs"
+	} else {
+		path = "tunedModels"
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Get returns the tuning job with the given name.
+func (m TuningJobs) Get(ctx context.Context, name string, config *GetTuningJobConfig) (*TuningJob, error) {
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"name": name, "config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(TuningJob)
+	var responseMap map[string]any
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = getTuningJobParametersToVertex
+		fromConverter = tuningJobFromVertex
+	} else {
+		toConverter = getTuningJobParametersToMldev
+		fromConverter = tuningJobFromMldev
+	}
+
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	path, err := formatMap("{name}", urlParams)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodGet, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Cancel requests the cancellation of the tuning job with the given name. The job
+// may still complete before it is cancelled; use Get to follow its state. Only
+// supported in Vertex AI.
+func (m TuningJobs) Cancel(ctx context.Context, name string, config *CancelTuningJobConfig) error {
+	if m.apiClient.clientConfig.Backend != BackendVertexAI {
+		return fmt.Errorf("Cancel is not supported in Gemini API")
+	}
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"name": name, "config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	body, err := cancelTuningJobParametersToVertex(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return err
+	}
+	var urlParams map[string]any
+	if _, ok := body["_url"]; ok {
+		urlParams = body["_url"].(map[string]any)
+		delete(body, "_url")
+	}
+	path, err := formatMap("{name}:cancel", urlParams)
+	if err != nil {
+		return fmt.Errorf("invalid url params: %#v.\n%w", urlParams, err)
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	_, err = sendRequest(ctx, m.apiClient, path, http.MethodPost, &body, httpOptions)
+	return err
+}
+
+func (m TuningJobs) list(ctx context.Context, config *ListTuningJobsConfig) (*ListTuningJobsResponse, error) {
+	parameterMap := make(map[string]any)
+
+	kwargs := map[string]any{"config": config}
+	deepMarshal(kwargs, &parameterMap)
+
+	var httpOptions *HTTPOptions
+	if config == nil {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, nil)
+	} else {
+		httpOptions = mergeHTTPOptions(m.apiClient.clientConfig, config.HTTPOptions)
+	}
+
+	var response = new(ListTuningJobsResponse)
+	var responseMap map[string]any
+	var fromConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	var toConverter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		toConverter = listTuningJobsParametersToVertex
+		fromConverter = listTuningJobsResponseFromVertex
+	} else {
+		toConverter = listTuningJobsParametersToMldev
+		fromConverter = listTuningJobsResponseFromMldev
+	}
+
+	body, err := toConverter(m.apiClient, parameterMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	var path string
+	if m.apiClient.clientConfig.Backend == BackendVertexAI {
+		path = "tuningJobs"
+	} else {
+		path = "tunedModels"
+	}
+	if _, ok := body["_query"]; ok {
+		path += "?" + createURLQuery(body["_query"].(map[string]any))
+		delete(body, "_query")
+	}
+
+	if _, ok := body["config"]; ok {
+		delete(body, "config")
+	}
+	responseMap, err = sendRequest(ctx, m.apiClient, path, http.MethodGet, &body, httpOptions)
+	if err != nil {
+		return nil, err
+	}
+	responseMap, err = fromConverter(m.apiClient, responseMap, nil)
+	if err != nil {
+		return nil, err
+	}
+	err = mapToStruct(responseMap, response)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// List returns an iterator over the tuning jobs. Pages are fetched lazily as the
+// iteration advances.
+func (m TuningJobs) List(ctx context.Context, config *ListTuningJobsConfig) iter.Seq2[*TuningJob, error] {
+	var pageToken string
+	if config != nil {
+		pageToken = config.PageToken
+	}
+	return allPages(ctx, pageToken, func(ctx context.Context, pageToken string) ([]*TuningJob, string, error) {
+		pageConfig := &ListTuningJobsConfig{}
+		if config != nil {
+			*pageConfig = *config
+		}
+		pageConfig.PageToken = pageToken
+		response, err := m.list(ctx, pageConfig)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.TuningJobs, response.NextPageToken, nil
+	})
+}
@@ -0,0 +1,333 @@
+// Copyright 2024 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package genai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTuningJobsCreate(t *testing.T) {
+	ctx := context.Background()
+	createTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		desc         string
+		backend      Backend
+		config       *CreateTuningJobConfig
+		wantPath     string
+		wantBody     map[string]any
+		fakeResponse string
+		want         *TuningJob
+	}{
+		{
+			desc:    "Gemini API",
+			backend: BackendGeminiAPI,
+			config: &CreateTuningJobConfig{
+				BaseModel: "gemini-1.0-pro-001",
+				TrainingDataset: &TuningDataset{Examples: []*TuningExample{
+					{TextInput: "1", Output: "2"},
+				}},
+				HyperParameters:       &TuningHyperParameters{EpochCount: Ptr[int64](5), BatchSize: Ptr[int64](4)},
+				TunedModelDisplayName: "counter",
+			},
+			wantPath: "/v1beta/tunedModels",
+			wantBody: map[string]any{
+				"baseModel":   "models/gemini-1.0-pro-001",
+				"displayName": "counter",
+				"tuningTask": map[string]any{
+					"trainingData": map[string]any{"examples": map[string]any{"examples": []any{
+						map[string]any{"textInput": "1", "output": "2"},
+					}}},
+					"hyperparameters": map[string]any{"epochCount": float64(5), "batchSize": float64(4)},
+				},
+			},
+			fakeResponse: `{"name":"tunedModels/counter-123/operations/abc","metadata":{"tunedModel":"tunedModels/counter-123","totalSteps":10}}`,
+			want: &TuningJob{
+				Name:           "tunedModels/counter-123",
+				State:          JobStateQueued,
+				TunedModelName: "tunedModels/counter-123",
+			},
+		},
+		{
+			desc:    "Vertex AI",
+			backend: BackendVertexAI,
+			config: &CreateTuningJobConfig{
+				BaseModel:             "gemini-1.5-flash-002",
+				TrainingDataset:       &TuningDataset{GCSURI: "gs://bucket/train.jsonl"},
+				HyperParameters:       &TuningHyperParameters{EpochCount: Ptr[int64](3), LearningRateMultiplier: Ptr(0.5)},
+				TunedModelDisplayName: "counter",
+			},
+			wantPath: "/v1beta1/projects/test-project/locations/test-location/tuningJobs",
+			wantBody: map[string]any{
+				"baseModel":             "gemini-1.5-flash-002",
+				"tunedModelDisplayName": "counter",
+				"supervisedTuningSpec": map[string]any{
+					"trainingDatasetUri": "gs://bucket/train.jsonl",
+					"hyperParameters":    map[string]any{"epochCount": float64(3), "learningRateMultiplier": 0.5},
+				},
+			},
+			fakeResponse: `{"name":"projects/test-project/locations/test-location/tuningJobs/123","state":"JOB_STATE_PENDING","baseModel":"gemini-1.5-flash-002","createTime":"2025-01-02T03:04:05Z"}`,
+			want: &TuningJob{
+				Name:       "projects/test-project/locations/test-location/tuningJobs/123",
+				State:      JobStatePending,
+				BaseModel:  "gemini-1.5-flash-002",
+				CreateTime: &createTime,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("request method = %q, want %q", r.Method, http.MethodPost)
+				}
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				body, _ := io.ReadAll(r.Body)
+				var gotBody map[string]any
+				if err := json.Unmarshal(body, &gotBody); err != nil {
+					t.Errorf("error unmarshalling request: %v", err)
+				}
+				if diff := cmp.Diff(tt.wantBody, gotBody); diff != "" {
+					t.Errorf("request body mismatch (-want +got):\n%s", diff)
+				}
+				fmt.Fprint(w, tt.fakeResponse)
+			}))
+			defer ts.Close()
+
+			m := TuningJobs{apiClient: newTestAPIClient(ts, tt.backend)}
+			got, err := m.Create(ctx, tt.config)
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Create() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("invalid config", func(t *testing.T) {
+		ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}
+		vertex := &apiClient{clientConfig: &ClientConfig{Backend: BackendVertexAI}}
+		tests := []struct {
+			desc   string
+			ac     *apiClient
+			config *CreateTuningJobConfig
+		}{
+			{desc: "nil config", ac: ac, config: nil},
+			{desc: "no base model", ac: ac, config: &CreateTuningJobConfig{TrainingDataset: &TuningDataset{}}},
+			{desc: "no training dataset", ac: ac, config: &CreateTuningJobConfig{BaseModel: "gemini-1.0-pro-001"}},
+			{desc: "GCS URI in Gemini API", ac: ac, config: &CreateTuningJobConfig{
+				BaseModel:       "gemini-1.0-pro-001",
+				TrainingDataset: &TuningDataset{GCSURI: "gs://bucket/train.jsonl"},
+			}},
+			{desc: "examples in Vertex AI", ac: vertex, config: &CreateTuningJobConfig{
+				BaseModel:       "gemini-1.5-flash-002",
+				TrainingDataset: &TuningDataset{Examples: []*TuningExample{{TextInput: "1", Output: "2"}}},
+			}},
+			{desc: "batch size in Vertex AI", ac: vertex, config: &CreateTuningJobConfig{
+				BaseModel:       "gemini-1.5-flash-002",
+				TrainingDataset: &TuningDataset{GCSURI: "gs://bucket/train.jsonl"},
+				HyperParameters: &TuningHyperParameters{BatchSize: Ptr[int64](4)},
+			}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				m := TuningJobs{apiClient: tt.ac}
+				if _, err := m.Create(ctx, tt.config); err == nil {
+					t.Errorf("Create() succeeded, want error")
+				}
+			})
+		}
+	})
+}
+
+func TestTuningJobsGet(t *testing.T) {
+	ctx := context.Background()
+	startTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	endTime := startTime.Add(time.Hour)
+	tests := []struct {
+		desc         string
+		backend      Backend
+		name         string
+		wantPath     string
+		fakeResponse string
+		want         *TuningJob
+	}{
+		{
+			desc:         "Gemini API",
+			backend:      BackendGeminiAPI,
+			name:         "counter-123",
+			wantPath:     "/v1beta/tunedModels/counter-123",
+			fakeResponse: `{"name":"tunedModels/counter-123","baseModel":"models/gemini-1.0-pro-001","state":"ACTIVE","tuningTask":{"startTime":"2025-01-02T03:04:05Z","completeTime":"2025-01-02T04:04:05Z"}}`,
+			want: &TuningJob{
+				Name:           "tunedModels/counter-123",
+				State:          JobStateSucceeded,
+				BaseModel:      "models/gemini-1.0-pro-001",
+				TunedModelName: "tunedModels/counter-123",
+				StartTime:      &startTime,
+				EndTime:        &endTime,
+			},
+		},
+		{
+			desc:         "Vertex AI",
+			backend:      BackendVertexAI,
+			name:         "tuningJobs/123",
+			wantPath:     "/v1beta1/projects/test-project/locations/test-location/tuningJobs/123",
+			fakeResponse: `{"name":"projects/test-project/locations/test-location/tuningJobs/123","state":"JOB_STATE_FAILED","error":{"code":3,"message":"invalid dataset"},"tunedModel":{"model":"projects/test-project/locations/test-location/models/456","endpoint":"e"}}`,
+			want: &TuningJob{
+				Name:           "projects/test-project/locations/test-location/tuningJobs/123",
+				State:          JobStateFailed,
+				Error:          &JobError{Code: 3, Message: "invalid dataset"},
+				TunedModelName: "projects/test-project/locations/test-location/models/456",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet {
+					t.Errorf("request method = %q, want %q", r.Method, http.MethodGet)
+				}
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				fmt.Fprint(w, tt.fakeResponse)
+			}))
+			defer ts.Close()
+
+			m := TuningJobs{apiClient: newTestAPIClient(ts, tt.backend)}
+			got, err := m.Get(ctx, tt.name, nil)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Get() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTuningJobsList(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		desc      string
+		backend   Backend
+		wantPath  string
+		firstPage string
+		lastPage  string
+		want      []*TuningJob
+	}{
+		{
+			desc:      "Gemini API",
+			backend:   BackendGeminiAPI,
+			wantPath:  "/v1beta/tunedModels",
+			firstPage: `{"tunedModels":[{"name":"tunedModels/a","state":"CREATING"}],"nextPageToken":"page-2"}`,
+			lastPage:  `{"tunedModels":[{"name":"tunedModels/b","state":"FAILED"}]}`,
+			want: []*TuningJob{
+				{Name: "tunedModels/a", State: JobStateRunning, TunedModelName: "tunedModels/a"},
+				{Name: "tunedModels/b", State: JobStateFailed, TunedModelName: "tunedModels/b"},
+			},
+		},
+		{
+			desc:      "Vertex AI",
+			backend:   BackendVertexAI,
+			wantPath:  "/v1beta1/projects/test-project/locations/test-location/tuningJobs",
+			firstPage: `{"tuningJobs":[{"name":"tuningJobs/1","state":"JOB_STATE_RUNNING"}],"nextPageToken":"page-2"}`,
+			lastPage:  `{"tuningJobs":[{"name":"tuningJobs/2","state":"JOB_STATE_CANCELLED"}]}`,
+			want: []*TuningJob{
+				{Name: "tuningJobs/1", State: JobStateRunning},
+				{Name: "tuningJobs/2", State: JobStateCancelled},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			var gotQuery []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("request path = %q, want %q", r.URL.Path, tt.wantPath)
+				}
+				gotQuery = append(gotQuery, r.URL.RawQuery)
+				if r.URL.Query().Get("pageToken") == "" {
+					fmt.Fprint(w, tt.firstPage)
+					return
+				}
+				fmt.Fprint(w, tt.lastPage)
+			}))
+			defer ts.Close()
+
+			m := TuningJobs{apiClient: newTestAPIClient(ts, tt.backend)}
+			var got []*TuningJob
+			for job, err := range m.List(ctx, &ListTuningJobsConfig{PageSize: 1}) {
+				if err != nil {
+					t.Fatalf("List() error = %v", err)
+				}
+				got = append(got, job)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("List() mismatch (-want +got):\n%s", diff)
+			}
+			wantQuery := []string{"pageSize=1", "pageSize=1&pageToken=page-2"}
+			if diff := cmp.Diff(wantQuery, gotQuery); diff != "" {
+				t.Errorf("List() queries mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestTuningJobsCancel(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Vertex AI", func(t *testing.T) {
+		requests := 0
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.Method != http.MethodPost {
+				t.Errorf("request method = %q, want %q", r.Method, http.MethodPost)
+			}
+			wantPath := "/v1beta1/projects/test-project/locations/test-location/tuningJobs/123:cancel"
+			if r.URL.Path != wantPath {
+				t.Errorf("request path = %q, want %q", r.URL.Path, wantPath)
+			}
+			fmt.Fprint(w, `{}`)
+		}))
+		defer ts.Close()
+
+		m := TuningJobs{apiClient: newTestAPIClient(ts, BackendVertexAI)}
+		if err := m.Cancel(ctx, "123", nil); err != nil {
+			t.Fatalf("Cancel() error = %v", err)
+		}
+		if requests != 1 {
+			t.Errorf("got %d requests, want 1", requests)
+		}
+	})
+
+	t.Run("Gemini API", func(t *testing.T) {
+		m := TuningJobs{apiClient: &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}}
+		if err := m.Cancel(ctx, "tunedModels/a", nil); err == nil {
+			t.Errorf("Cancel() succeeded, want error")
+		}
+	})
+}
@@ -17,10 +17,15 @@
 package genai
 
 import (
+	"bytes"
 	"cloud.google.com/go/civil"
+	"cmp"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -317,6 +322,62 @@ const (
 	ModalityAudio Modality = "AUDIO"
 )
 
+// State of a file uploaded to the Gemini API.
+type FileState string
+
+const (
+	// The default value. This value is used if the state is omitted.
+	FileStateUnspecified FileState = "STATE_UNSPECIFIED"
+	// File is being processed and cannot be used for inference yet.
+	FileStateProcessing FileState = "PROCESSING"
+	// File is processed and available for inference.
+	FileStateActive FileState = "ACTIVE"
+	// File failed processing.
+	FileStateFailed FileState = "FAILED"
+)
+
+// Format of streamed responses.
+type StreamFormat string
+
+const (
+	// Server-sent events, requested with alt=sse. This is the default.
+	StreamFormatSSE StreamFormat = "SSE"
+	// Newline-delimited JSON, one response per line. Use it when a proxy does not
+	// forward server-sent events.
+	StreamFormatNDJSON StreamFormat = "NDJSON"
+)
+
+// State of a tuning job.
+type JobState string
+
+const (
+	// The job state is unspecified.
+	JobStateUnspecified JobState = "JOB_STATE_UNSPECIFIED"
+	// The job has been just created or resumed and processing has not yet begun.
+	JobStateQueued JobState = "JOB_STATE_QUEUED"
+	// The service is preparing to run the job.
+	JobStatePending JobState = "JOB_STATE_PENDING"
+	// The job is in progress.
+	JobStateRunning JobState = "JOB_STATE_RUNNING"
+	// The job completed successfully.
+	JobStateSucceeded JobState = "JOB_STATE_SUCCEEDED"
+	// The job failed.
+	JobStateFailed JobState = "JOB_STATE_FAILED"
+	// The job is being cancelled. From this state the job may only go to
+	// either `JOB_STATE_SUCCEEDED`, `JOB_STATE_FAILED` or `JOB_STATE_CANCELLED`.
+	JobStateCancelling JobState = "JOB_STATE_CANCELLING"
+	// The job has been cancelled.
+	JobStateCancelled JobState = "JOB_STATE_CANCELLED"
+	// The job has been stopped, and can be resumed.
+	JobStatePaused JobState = "JOB_STATE_PAUSED"
+	// The job has expired.
+	JobStateExpired JobState = "JOB_STATE_EXPIRED"
+	// The job is being updated.
+	JobStateUpdating JobState = "JOB_STATE_UPDATING"
+	// The job is partially succeeded, some results may be missing due to errors.
+	JobStatePartiallySucceeded JobState = "JOB_STATE_PARTIALLY_SUCCEEDED"
+)
+
 // Metadata describes the input video content.
 type VideoMetadata struct {
 	// Optional. The end offset of the video.
@@ -413,6 +474,10 @@ type Part struct {
 	InlineData *Blob `json:"inlineData,omitempty"`
 	// Optional. Text part (can be code).
 	Text string `json:"text,omitempty"`
+	// Optional. Media resolution for this part. Only used when FileData or InlineData
+	// is set. If empty, GenerateContentConfig.MediaResolution applies. Only supported
+	// in Vertex AI.
+	MediaResolution MediaResolution `json:"mediaResolution,omitempty"`
 }
 
 // NewPartFromURI builds a Part from a given file URI and mime type.
@@ -425,6 +490,33 @@ func NewPartFromURI(fileURI, mimeType string) *Part {
 	}
 }
 
+// NewPartFromGCS builds a Part from a Google Cloud Storage object and mime type. The
+// file URI of the part is gs://bucket/object. It panics if bucket is empty, is
+// already a URI or contains a slash, or if object is empty.
+func NewPartFromGCS(bucket, object, mimeType string) *Part {
+	if bucket == "" || object == "" {
+		panic(fmt.Sprintf("genai.NewPartFromGCS: bucket %q and object %q must not be empty", bucket, object))
+	}
+	if strings.Contains(bucket, "/") {
+		panic(fmt.Sprintf("genai.NewPartFromGCS: bucket %q must be a bucket name, not a URI or path", bucket))
+	}
+	return NewPartFromURI("gs://"+bucket+"/"+object, mimeType)
+}
+
+// NewPartFromHTTP builds a Part from a given http or https URL and mime type. It
+// panics if uri is not an absolute http or https URL; use [NewPartFromURI] for
+// other URIs.
+func NewPartFromHTTP(uri, mimeType string) *Part {
+	u, err := url.Parse(uri)
+	if err != nil {
+		panic(fmt.Sprintf("genai.NewPartFromHTTP: invalid URL %q: %v", uri, err))
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		panic(fmt.Sprintf("genai.NewPartFromHTTP: URL %q must be an absolute http or https URL", uri))
+	}
+	return NewPartFromURI(uri, mimeType)
+}
+
 // NewPartFromText builds a Part from a given text.
 func NewPartFromText(text string) *Part {
 	return &Part{
@@ -492,6 +584,179 @@ func NewPartFromCodeExecutionResult(outcome Outcome, output string) *Part {
 	}
 }
 
+// AsText returns the text of the part and whether it is set.
+func (p *Part) AsText() (string, bool) {
+	if p == nil || p.Text == "" {
+		return "", false
+	}
+	return p.Text, true
+}
+
+// AsFunctionCall returns the function call of the part and whether it is set.
+func (p *Part) AsFunctionCall() (*FunctionCall, bool) {
+	if p == nil || p.FunctionCall == nil {
+		return nil, false
+	}
+	return p.FunctionCall, true
+}
+
+// AsFunctionResponse returns the function response of the part and whether it is set.
+func (p *Part) AsFunctionResponse() (*FunctionResponse, bool) {
+	if p == nil || p.FunctionResponse == nil {
+		return nil, false
+	}
+	return p.FunctionResponse, true
+}
+
+// AsInlineData returns the inline data of the part and whether it is set.
+func (p *Part) AsInlineData() (*Blob, bool) {
+	if p == nil || p.InlineData == nil {
+		return nil, false
+	}
+	return p.InlineData, true
+}
+
+// AsFileData returns the file data of the part and whether it is set.
+func (p *Part) AsFileData() (*FileData, bool) {
+	if p == nil || p.FileData == nil {
+		return nil, false
+	}
+	return p.FileData, true
+}
+
+// AsExecutableCode returns the executable code of the part and whether it is set.
+func (p *Part) AsExecutableCode() (*ExecutableCode, bool) {
+	if p == nil || p.ExecutableCode == nil {
+		return nil, false
+	}
+	return p.ExecutableCode, true
+}
+
+// AsCodeExecutionResult returns the code execution result of the part and whether it is set.
+func (p *Part) AsCodeExecutionResult() (*CodeExecutionResult, bool) {
+	if p == nil || p.CodeExecutionResult == nil {
+		return nil, false
+	}
+	return p.CodeExecutionResult, true
+}
+
+// IsAudio reports whether the part holds inline data with an audio MIME type, such
+// as "audio/pcm;rate=24000".
+func (p *Part) IsAudio() bool {
+	return p != nil && p.InlineData != nil && strings.HasPrefix(strings.ToLower(p.InlineData.MIMEType), "audio/")
+}
+
+// SizeBytes returns the size in bytes of the data of the part: the text, the inline
+// data before base64 encoding, the file URI, the code or output of code execution,
+// the name and the JSON arguments or response of a function call or response, and
+// the video offsets. It can be compared to ClientConfig.MaxInlineDataBytes before
+// sending a request. It returns 0 if p is nil.
+func (p *Part) SizeBytes() int64 {
+	if p == nil {
+		return 0
+	}
+	size := int64(len(p.Text))
+	if p.InlineData != nil {
+		size += int64(len(p.InlineData.Data))
+	}
+	if p.FileData != nil {
+		size += int64(len(p.FileData.FileURI))
+	}
+	if p.FunctionCall != nil {
+		b, _ := json.Marshal(p.FunctionCall.Args)
+		size += int64(len(p.FunctionCall.Name) + len(b))
+	}
+	if p.FunctionResponse != nil {
+		b, _ := json.Marshal(p.FunctionResponse.Response)
+		size += int64(len(p.FunctionResponse.Name) + len(b))
+	}
+	if p.ExecutableCode != nil {
+		size += int64(len(p.ExecutableCode.Code))
+	}
+	if p.CodeExecutionResult != nil {
+		size += int64(len(p.CodeExecutionResult.Output))
+	}
+	if p.VideoMetadata != nil {
+		size += int64(len(p.VideoMetadata.StartOffset) + len(p.VideoMetadata.EndOffset))
+	}
+	return size
+}
+
+// DeepCopy returns a copy of p that shares no memory with p, so that either can be
+// modified without affecting the other. The maps and slices of function call
+// arguments and responses are copied recursively. It returns nil if p is nil.
+func (p *Part) DeepCopy() *Part {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	if p.VideoMetadata != nil {
+		v := *p.VideoMetadata
+		c.VideoMetadata = &v
+	}
+	if p.CodeExecutionResult != nil {
+		v := *p.CodeExecutionResult
+		c.CodeExecutionResult = &v
+	}
+	if p.ExecutableCode != nil {
+		v := *p.ExecutableCode
+		c.ExecutableCode = &v
+	}
+	if p.FileData != nil {
+		v := *p.FileData
+		c.FileData = &v
+	}
+	if p.FunctionCall != nil {
+		v := *p.FunctionCall
+		v.Args = deepCopyMap(v.Args)
+		c.FunctionCall = &v
+	}
+	if p.FunctionResponse != nil {
+		v := *p.FunctionResponse
+		v.Response = deepCopyMap(v.Response)
+		c.FunctionResponse = &v
+	}
+	if p.InlineData != nil {
+		v := *p.InlineData
+		v.Data = bytes.Clone(v.Data)
+		c.InlineData = &v
+	}
+	return &c
+}
+
+// deepCopyMap copies m and the maps and slices it contains. Other values, such as
+// strings and numbers, are copied by assignment.
+func deepCopyMap(m map[string]any) map[string]any {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]any, len(m))
+	for k, v := range m {
+		c[k] = deepCopyValue(v)
+	}
+	return c
+}
+
+func deepCopyValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		return deepCopyMap(v)
+	case []any:
+		if v == nil {
+			return v
+		}
+		c := make([]any, len(v))
+		for i, e := range v {
+			c[i] = deepCopyValue(e)
+		}
+		return c
+	case []byte:
+		return bytes.Clone(v)
+	default:
+		return v
+	}
+}
+
 // Contains the multi-part content of a message.
 type Content struct {
 	// List of parts that constitute a single message. Each part may have
@@ -513,6 +778,18 @@ type HTTPOptions struct {
 	// Timeout sets the timeout for HTTP requests in milliseconds. If unset, defaults to
 	// "v1beta" for the Gemini API, and "v1beta1" for the Vertex AI.
 	Timeout int64 `json:"timeout,omitempty"`
+	// StreamFormat sets the format of streamed responses. If unset, defaults to
+	// StreamFormatSSE.
+	StreamFormat StreamFormat `json:"streamFormat,omitempty"`
+	// ExtraHeaders are additional HTTP headers sent with the request, for example
+	// for tracing or experimentation. Per-request headers take precedence over the
+	// client-level ones. The Content-Type and authentication headers set by the SDK
+	// are never overwritten.
+	ExtraHeaders map[string]string `json:"extraHeaders,omitempty"`
+	// CompressRequests compresses JSON request bodies with gzip, which reduces the
+	// upload time of large requests such as ones with many inline images. File
+	// uploads are not compressed.
+	CompressRequests bool `json:"compressRequests,omitempty"`
 }
 
 // Schema that defines the format of input and output data. Represents a select subset
@@ -620,6 +897,23 @@ type DynamicRetrievalConfig struct {
 	DynamicThreshold *float64 `json:"dynamicThreshold,omitempty"`
 }
 
+// Validate returns an error if Mode is not a known mode or if DynamicThreshold is set
+// and outside [0, 1].
+func (c *DynamicRetrievalConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+	switch c.Mode {
+	case "", DynamicRetrievalConfigModeUnspecified, DynamicRetrievalConfigModeDynamic:
+	default:
+		return fmt.Errorf("DynamicRetrievalConfig: unknown mode %q", c.Mode)
+	}
+	if c.DynamicThreshold != nil && (*c.DynamicThreshold < 0 || *c.DynamicThreshold > 1) {
+		return fmt.Errorf("DynamicRetrievalConfig: dynamic threshold %v is outside [0, 1]", *c.DynamicThreshold)
+	}
+	return nil
+}
+
 // Tool to retrieve public web data for grounding, powered by Google.
 type GoogleSearchRetrieval struct {
 	// Specifies the dynamic retrieval configuration for the given source.
@@ -721,10 +1015,27 @@ type VoiceConfig struct {
 	PrebuiltVoiceConfig *PrebuiltVoiceConfig `json:"prebuiltVoiceConfig,omitempty"`
 }
 
+// The configuration for the voice of a single speaker in a multi-speaker setup.
+type SpeakerVoiceConfig struct {
+	// The name of the speaker. Must match the speaker name used in the prompt.
+	Speaker string `json:"speaker,omitempty"`
+	// The configuration for the voice of this speaker.
+	VoiceConfig *VoiceConfig `json:"voiceConfig,omitempty"`
+}
+
+// The configuration for multi-speaker audio generation.
+type MultiSpeakerVoiceConfig struct {
+	// The configuration for each of the speakers.
+	SpeakerVoiceConfigs []*SpeakerVoiceConfig `json:"speakerVoiceConfigs,omitempty"`
+}
+
 // The speech generation configuration.
 type SpeechConfig struct {
 	// The configuration for the speaker to use.
 	VoiceConfig *VoiceConfig `json:"voiceConfig,omitempty"`
+	// Optional. The configuration for multi-speaker audio generation. Not supported
+	// in Gemini API.
+	MultiSpeakerConfig *MultiSpeakerVoiceConfig `json:"multiSpeakerVoiceConfig,omitempty"`
 }
 
 // The thinking features configuration.
@@ -732,6 +1043,9 @@ type ThinkingConfig struct {
 	// Indicates whether to include thoughts in the response. If true, thoughts are returned
 	// only if the model supports thought and thoughts are available.
 	IncludeThoughts bool `json:"includeThoughts,omitempty"`
+	// Optional. The number of thought tokens the model may generate. 0 disables
+	// thinking on models that allow it. If nil, the model decides.
+	ThinkingBudget *int64 `json:"thinkingBudget,omitempty"`
 }
 
 // When automated routing is specified, the routing will be determined by the pretrained
@@ -759,10 +1073,15 @@ type GenerationConfigRoutingConfig struct {
 // more details at https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/inference#generationconfig
 // and https://cloud.google.com/vertex-ai/generative-ai/docs/multimodal/content-generation-parameters.
 type GenerateContentConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 	// Instructions for the model to steer it toward better performance.
 	// For example, "Answer as concisely as possible" or "Don't use technical
 	// terms in your response".
 	SystemInstruction *Content `json:"systemInstruction,omitempty"`
+	// Shorthand for a SystemInstruction made of a single text part. It cannot be
+	// set together with SystemInstruction.
+	SystemInstructionText string `json:"systemInstructionText,omitempty"`
 	// Value that controls the degree of randomness in token selection.
 	// Lower temperatures are good for prompts that require a less open-ended or
 	// creative response, while higher temperatures can lead to more diverse or
@@ -778,7 +1097,7 @@ type GenerateContentConfig struct {
 	// a lower number for less random responses and a higher number for more
 	// random responses.
 	TopK *float64 `json:"topK,omitempty"`
-	// Number of response variations to return.
+	// Number of response variations to return, at most [MaxCandidateCount].
 	CandidateCount *int64 `json:"candidateCount,omitempty"`
 	// Maximum number of tokens that can be generated in the response.
 	MaxOutputTokens *int64 `json:"maxOutputTokens,omitempty"`
@@ -803,10 +1122,22 @@ type GenerateContentConfig struct {
 	// effort to provide the same response for repeated requests. By default, a
 	// random number is used.
 	Seed *int64 `json:"seed,omitempty"`
-	// Output response media type of the generated candidate text.
+	// Output response media type of the generated candidate text. It must be one of
+	// MIMETypeText, MIMETypeJSON and MIMETypeEnum, unless AllowCustomMIMEType is set.
 	ResponseMIMEType string `json:"responseMimeType,omitempty"`
+	// Optional. If true, ResponseMIMEType is sent to the API even if it is not one of
+	// the known MIME types. It is not sent to the API.
+	AllowCustomMIMEType bool `json:"allowCustomMimeType,omitempty"`
 	// Schema that the generated candidate text must adhere to.
 	ResponseSchema *Schema `json:"responseSchema,omitempty"`
+	// Optional. If true and ResponseSchema is set, GenerateContent checks that the
+	// text of the response is JSON matching ResponseSchema and returns an error
+	// otherwise. The check is done by the client.
+	ValidateResponseSchema bool `json:"-"`
+	// Optional. If true, GenerateContent and GenerateContentStream call
+	// [Tool.Validate] on Tools and return an error instead of sending an invalid
+	// request. The check is done by the client.
+	ValidateTools bool `json:"-"`
 	// Configuration for model router requests.
 	RoutingConfig *GenerationConfigRoutingConfig `json:"routingConfig,omitempty"`
 	// Safety settings in the request to block unsafe content in the
@@ -815,9 +1146,14 @@ type GenerateContentConfig struct {
 	// Code that enables the system to interact with external systems to
 	// perform an action outside of the knowledge and scope of the model.
 	Tools []*Tool `json:"tools,omitempty"`
-	// Associates model output to a specific function call.
+	// Associates model output to a specific function call. If nil and Tools declare
+	// functions, it defaults to the AUTO function calling mode.
 	ToolConfig *ToolConfig `json:"toolConfig,omitempty"`
-	// Labels with user-defined metadata to break down billed charges.
+	// Optional. If true, ToolConfig is left nil when it is not set, instead of
+	// defaulting to the AUTO function calling mode. It is not sent to the API.
+	DisableAutoToolConfig bool `json:"disableAutoToolConfig,omitempty"`
+	// Labels with user-defined metadata to break down billed charges. At most 64
+	// labels can be set. Only supported in Vertex AI.
 	Labels map[string]string `json:"labels,omitempty"`
 	// Resource name of a context cache that can be used in subsequent
 	// requests.
@@ -830,10 +1166,22 @@ type GenerateContentConfig struct {
 	// The speech generation configuration.
 	SpeechConfig *SpeechConfig `json:"speechConfig,omitempty"`
 	// If enabled, audio timestamp will be included in the request to the
-	// model.
+	// model. It is only sent when true, since some models reject the field.
 	AudioTimestamp bool `json:"audioTimestamp,omitempty"`
 	// The thinking features configuration.
 	ThinkingConfig *ThinkingConfig `json:"thinkingConfig,omitempty"`
+	// Configures the function calling loop of [Models.GenerateContentWithTools]. It is
+	// not sent to the API.
+	AutomaticFunctionCalling *AutomaticFunctionCallingConfig `json:"automaticFunctionCalling,omitempty"`
+}
+
+// Configuration of the function calling loop of [Models.GenerateContentWithTools].
+type AutomaticFunctionCallingConfig struct {
+	// Maximum number of model calls made by the loop. Defaults to 10.
+	MaxIterations int64 `json:"maxIterations,omitempty"`
+	// Maximum number of functions run concurrently. If zero, all the function calls of
+	// a response run concurrently.
+	MaxParallelCalls int64 `json:"maxParallelCalls,omitempty"`
 }
 
 // Config for models.generate_content parameters.
@@ -1055,6 +1403,30 @@ type GenerateContentResponseUsageMetadata struct {
 	TotalTokenCount int64 `json:"totalTokenCount,omitempty"`
 }
 
+// TotalTokens returns the number of tokens of the prompt and of the response
+// candidates. Cached content tokens are part of the prompt tokens, so they are not
+// counted separately.
+func (u *GenerateContentResponseUsageMetadata) TotalTokens() int64 {
+	if u == nil {
+		return 0
+	}
+	var total int64
+	if u.PromptTokenCount != nil {
+		total += *u.PromptTokenCount
+	}
+	if u.CandidatesTokenCount != nil {
+		total += *u.CandidatesTokenCount
+	}
+	return total
+}
+
+// EstimatedCostUSD returns the cost of TotalTokens at the given price per million
+// tokens. It does not account for prices that differ between input, output and
+// cached tokens.
+func (u *GenerateContentResponseUsageMetadata) EstimatedCostUSD(pricePerMillionTokens float64) float64 {
+	return float64(u.TotalTokens()) * pricePerMillionTokens / 1e6
+}
+
 // Response message for PredictionService.GenerateContent.
 type GenerateContentResponse struct {
 	// Response variations returned by the model.
@@ -1125,18 +1497,198 @@ func (r *GenerateContentResponse) FunctionCalls() []*FunctionCall {
 		log.Printf("Warning: there are multiple candidates in the response, returning function calls from the first one.")
 	}
 
+	return r.Candidates[0].FunctionCalls()
+}
+
+// FunctionCalls returns the list of function calls in the content of the candidate.
+func (c *Candidate) FunctionCalls() []*FunctionCall {
+	if c == nil || c.Content == nil {
+		return nil
+	}
+
 	var functionCalls []*FunctionCall
-	for _, part := range r.Candidates[0].Content.Parts {
-		if part.FunctionCall != nil {
+	for _, part := range c.Content.Parts {
+		if part != nil && part.FunctionCall != nil {
 			functionCalls = append(functionCalls, part.FunctionCall)
 		}
 	}
 
-	if len(functionCalls) == 0 {
+	return functionCalls
+}
+
+// CodeExecutionResults returns the list of code execution results in the
+// GenerateContentResponse.
+func (r *GenerateContentResponse) CodeExecutionResults() []*CodeExecutionResult {
+	if len(r.Candidates) == 0 {
 		return nil
 	}
 
-	return functionCalls
+	if len(r.Candidates) > 1 {
+		log.Printf("Warning: there are multiple candidates in the response, returning code execution results from the first one.")
+	}
+
+	return r.Candidates[0].CodeExecutionResults()
+}
+
+// CodeExecutionResults returns the list of code execution results in the content
+// of the candidate.
+func (c *Candidate) CodeExecutionResults() []*CodeExecutionResult {
+	if c == nil || c.Content == nil {
+		return nil
+	}
+
+	var results []*CodeExecutionResult
+	for _, part := range c.Content.Parts {
+		if part != nil && part.CodeExecutionResult != nil {
+			results = append(results, part.CodeExecutionResult)
+		}
+	}
+
+	return results
+}
+
+// ExecutableCodes returns the list of executable codes in the content of the
+// candidate.
+func (c *Candidate) ExecutableCodes() []*ExecutableCode {
+	if c == nil || c.Content == nil {
+		return nil
+	}
+
+	var codes []*ExecutableCode
+	for _, part := range c.Content.Parts {
+		if part != nil && part.ExecutableCode != nil {
+			codes = append(codes, part.ExecutableCode)
+		}
+	}
+
+	return codes
+}
+
+// ThoughtText returns the concatenated text of the thought parts of the candidate,
+// which are returned by thinking models when ThinkingConfig.IncludeThoughts is set.
+func (c *Candidate) ThoughtText() string {
+	if c == nil || c.Content == nil {
+		return ""
+	}
+	var texts []string
+	for _, part := range c.Content.Parts {
+		if part != nil && part.Thought && part.Text != "" {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "")
+}
+
+// SafetyBlocked reports whether the candidate was blocked by the safety filters,
+// either because it finished with FinishReasonSafety or because one of its safety
+// ratings is blocked.
+func (c *Candidate) SafetyBlocked() bool {
+	if c == nil {
+		return false
+	}
+	if c.FinishReason == FinishReasonSafety {
+		return true
+	}
+	for _, rating := range c.SafetyRatings {
+		if rating != nil && rating.Blocked {
+			return true
+		}
+	}
+	return false
+}
+
+// SortedCandidates returns the candidates of the response sorted by Index. A
+// candidate without an Index is ordered by its position in Candidates. The response
+// is not modified.
+func (r *GenerateContentResponse) SortedCandidates() []*Candidate {
+	type indexed struct {
+		index     int64
+		candidate *Candidate
+	}
+	var sorted []indexed
+	for i, c := range r.Candidates {
+		if c != nil {
+			sorted = append(sorted, indexed{candidateIndex(c, i), c})
+		}
+	}
+	slices.SortStableFunc(sorted, func(a, b indexed) int {
+		return cmp.Compare(a.index, b.index)
+	})
+	candidates := make([]*Candidate, len(sorted))
+	for i, c := range sorted {
+		candidates[i] = c.candidate
+	}
+	return candidates
+}
+
+// CandidateByIndex returns the candidate whose Index is n, or whose position in
+// Candidates is n if it has no Index. The lookup is constant-time when the
+// candidates are in index order, as returned by the API for unary requests.
+func (r *GenerateContentResponse) CandidateByIndex(n int) (*Candidate, bool) {
+	if n < 0 {
+		return nil, false
+	}
+	if n < len(r.Candidates) {
+		if c := r.Candidates[n]; c != nil && candidateIndex(c, n) == int64(n) {
+			return c, true
+		}
+	}
+	for i, c := range r.Candidates {
+		if c != nil && candidateIndex(c, i) == int64(n) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// SafetyBlocked reports whether all the candidates of the response were blocked by
+// the safety filters. It returns false if the response has no candidates; check
+// PromptFeedback to know whether the prompt itself was blocked.
+func (r *GenerateContentResponse) SafetyBlocked() bool {
+	if len(r.Candidates) == 0 {
+		return false
+	}
+	for _, c := range r.Candidates {
+		if !c.SafetyBlocked() {
+			return false
+		}
+	}
+	return true
+}
+
+// AudioData returns the data and the MIME type of the first audio part of the
+// response, looking through the candidates in order. It returns an error if the
+// response has no audio part.
+func (r *GenerateContentResponse) AudioData() (data []byte, mimeType string, err error) {
+	for _, c := range r.Candidates {
+		if c == nil || c.Content == nil {
+			continue
+		}
+		for _, part := range c.Content.Parts {
+			if part.IsAudio() {
+				return part.InlineData.Data, part.InlineData.MIMEType, nil
+			}
+		}
+	}
+	return nil, "", errors.New("GenerateContentResponse.AudioData: the response has no audio part")
+}
+
+// GroundingMetadata returns the grounding metadata of the first candidate, or nil
+// if the response has no candidates.
+func (r *GenerateContentResponse) GroundingMetadata() *GroundingMetadata {
+	if len(r.Candidates) == 0 || r.Candidates[0] == nil {
+		return nil
+	}
+	return r.Candidates[0].GroundingMetadata
+}
+
+// SearchQueries returns the web search queries that grounded the first candidate,
+// for example to display them along with the search entry point.
+func (r *GenerateContentResponse) SearchQueries() []string {
+	if m := r.GroundingMetadata(); m != nil {
+		return m.WebSearchQueries
+	}
+	return nil
 }
 
 // The configuration for generating images. You can find API default values and more
@@ -1144,6 +1696,8 @@ func (r *GenerateContentResponse) FunctionCalls() []*FunctionCall {
 // VertexAI: https://cloud.google.com/vertex-ai/generative-ai/docs/model-reference/imagen-api.
 // GeminiAPI: https://ai.google.dev/gemini-api/docs/imagen#imagen-model
 type GenerateImagesConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 	// Cloud Storage URI used to store the generated images.
 	OutputGCSURI string `json:"outputGcsUri,omitempty"`
 	// Description of what to discourage in the generated images.
@@ -1266,6 +1820,8 @@ type GenerationConfig struct {
 
 // Config for the count_tokens method.
 type CountTokensConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 	// Instructions for the model to steer it toward better performance.
 	SystemInstruction *Content `json:"systemInstruction,omitempty"`
 	// Code that enables the system to interact with external systems to
@@ -1298,6 +1854,8 @@ type CountTokensResponse struct {
 
 // Optional parameters for computing tokens.
 type ComputeTokensConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 }
 
 // Parameters for computing tokens.
@@ -1354,8 +1912,142 @@ type ComputeTokensResponse struct {
 	TokensInfo []*TokensInfo `json:"tokensInfo,omitempty"`
 }
 
+// Optional parameters for the embed content method.
+type EmbedContentConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+	// Type of task for which the embedding will be used, for example
+	// "RETRIEVAL_QUERY", "RETRIEVAL_DOCUMENT" or "SEMANTIC_SIMILARITY".
+	TaskType string `json:"taskType,omitempty"`
+	// Title for the text. Only applicable when TaskType is
+	// `RETRIEVAL_DOCUMENT`.
+	Title string `json:"title,omitempty"`
+	// Reduced dimension for the output embedding. If set,
+	// excessive values in the output embedding are truncated from the end.
+	OutputDimensionality *int64 `json:"outputDimensionality,omitempty"`
+}
+
+// A single request of a batch embed contents call.
+type EmbedContentRequest struct {
+	// The content to embed. Vertex AI only supports text parts.
+	Content *Content `json:"content,omitempty"`
+	// Optional parameters for the request. In Vertex AI, all requests of a batch must
+	// use the same OutputDimensionality.
+	Config *EmbedContentConfig `json:"config,omitempty"`
+}
+
+// Optional parameters for the batch embed contents method.
+type BatchEmbedContentsConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+}
+
+// Statistics of the input text associated with the result of content embedding.
+// Only returned by Vertex AI.
+type ContentEmbeddingStatistics struct {
+	// Whether the input content was truncated before generating the embedding.
+	Truncated bool `json:"truncated,omitempty"`
+	// Number of tokens of the input text.
+	TokenCount float64 `json:"tokenCount,omitempty"`
+}
+
+// The embedding generated from an input content.
+type ContentEmbedding struct {
+	// A list of floats representing an embedding.
+	Values []float32 `json:"values,omitempty"`
+	// Vertex API only. Statistics of the input text associated with this
+	// embedding.
+	Statistics *ContentEmbeddingStatistics `json:"statistics,omitempty"`
+}
+
+// Response for the embed content method.
+type EmbedContentResponse struct {
+	// The embedding generated from the input content.
+	Embedding *ContentEmbedding `json:"embedding,omitempty"`
+}
+
+// Response for the batch embed contents method.
+type BatchEmbedContentsResponse struct {
+	// The embeddings for each request, in the same order as the requests.
+	Embeddings []*ContentEmbedding `json:"embeddings,omitempty"`
+}
+
+// Optional parameters for models.get method.
+type GetModelConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+}
+
+// Metadata of a model.
+type ModelInfo struct {
+	// Resource name of the model.
+	Name string `json:"name,omitempty"`
+	// Display name of the model.
+	DisplayName string `json:"displayName,omitempty"`
+	// Description of the model.
+	Description string `json:"description,omitempty"`
+	// Version ID of the model.
+	Version string `json:"version,omitempty"`
+	// The model's supported generation methods, for example "generateContent".
+	// Only returned by the Gemini API.
+	SupportedGenerationMethods []string `json:"supportedGenerationMethods,omitempty"`
+	// Maximum number of input tokens allowed for this model. Only returned by the
+	// Gemini API.
+	InputTokenLimit int64 `json:"inputTokenLimit,omitempty"`
+	// Maximum number of output tokens available for this model. Only returned by the
+	// Gemini API.
+	OutputTokenLimit int64 `json:"outputTokenLimit,omitempty"`
+	// Maximum temperature this model supports. Only returned by the Gemini API.
+	MaxTemperature float64 `json:"maxTemperature,omitempty"`
+}
+
+// Optional parameters for models.list method.
+type ListModelsConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+	// Maximum number of models to return per page.
+	PageSize int64 `json:"pageSize,omitempty"`
+	// Page token of the first page to return.
+	PageToken string `json:"pageToken,omitempty"`
+	// Filter expression applied to the listed models.
+	Filter string `json:"filter,omitempty"`
+}
+
+// A page of models returned by models.list method.
+type ListModelsResponse struct {
+	// Token to retrieve the next page. Empty on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+	// The models of this page.
+	Models []*ModelInfo `json:"models,omitempty"`
+}
+
+// Optional parameters for models.update method.
+type UpdateModelConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+	// The new display name of the model.
+	DisplayName string `json:"displayName,omitempty"`
+	// The new description of the model.
+	Description string `json:"description,omitempty"`
+	// The fields to update, for example "displayName". If empty, the API decides
+	// which fields are updated.
+	UpdateMask []string `json:"updateMask,omitempty"`
+}
+
+// Optional parameters for models.delete method.
+type DeleteModelConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+}
+
+// Empty response for models.delete method.
+type DeleteModelResponse struct {
+}
+
 // Optional configuration for cached content creation.
 type CreateCachedContentConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 	// The TTL for this resource. The expiration time is computed: now + TTL.
 	TTL string `json:"ttl,omitempty"`
 	// Timestamp of when this resource is considered expired.
@@ -1416,6 +2108,8 @@ type CachedContent struct {
 
 // Optional parameters for caches.get method.
 type GetCachedContentConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 }
 
 // Parameters for caches.get method.
@@ -1428,6 +2122,8 @@ type GetCachedContentParameters struct {
 
 // Optional parameters for caches.delete method.
 type DeleteCachedContentConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 }
 
 // Parameters for caches.delete method.
@@ -1444,6 +2140,8 @@ type DeleteCachedContentResponse struct {
 
 // Optional parameters for caches.update method.
 type UpdateCachedContentConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 	// The TTL for this resource. The expiration time is computed: now + TTL.
 	TTL string `json:"ttl,omitempty"`
 	// Timestamp of when this resource is considered expired.
@@ -1457,6 +2155,210 @@ type UpdateCachedContentParameters struct {
 	Config *UpdateCachedContentConfig `json:"config,omitempty"`
 }
 
+// Optional parameters for caches.list method.
+type ListCachedContentsConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+	// Maximum number of cached contents to return per page.
+	PageSize int64 `json:"pageSize,omitempty"`
+	// Page token of the first page to return.
+	PageToken string `json:"pageToken,omitempty"`
+}
+
+// Parameters for caches.list method.
+type ListCachedContentsParameters struct {
+	// Configuration that contains optional parameters.
+	Config *ListCachedContentsConfig `json:"config,omitempty"`
+}
+
+// A page of cached contents returned by caches.list method.
+type ListCachedContentsResponse struct {
+	// Token to retrieve the next page. Empty on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+	// The cached contents of this page.
+	CachedContents []*CachedContent `json:"cachedContents,omitempty"`
+}
+
+// Status of a file that failed processing.
+type FileStatus struct {
+	// A developer-facing error message.
+	Message string `json:"message,omitempty"`
+	// The status code.
+	Code int64 `json:"code,omitempty"`
+}
+
+// A file uploaded to the Gemini API.
+type File struct {
+	// The resource name of the file. For example: "files/abc-123".
+	Name string `json:"name,omitempty"`
+	// The human-readable display name of the file.
+	DisplayName string `json:"displayName,omitempty"`
+	// MIME type of the file.
+	MIMEType string `json:"mimeType,omitempty"`
+	// Size of the file in bytes.
+	SizeBytes int64 `json:"sizeBytes,omitempty,string"`
+	// Creation time of the file.
+	CreateTime *time.Time `json:"createTime,omitempty"`
+	// Last update time of the file.
+	UpdateTime *time.Time `json:"updateTime,omitempty"`
+	// The time when the file is deleted.
+	ExpirationTime *time.Time `json:"expirationTime,omitempty"`
+	// SHA-256 hash of the uploaded bytes, base64 encoded.
+	SHA256Hash string `json:"sha256Hash,omitempty"`
+	// The URI of the file. Use it in [FileData] to reference the file in a prompt.
+	URI string `json:"uri,omitempty"`
+	// Processing state of the file.
+	State FileState `json:"state,omitempty"`
+	// Error status if the file failed processing.
+	Error *FileStatus `json:"error,omitempty"`
+}
+
+// Optional parameters for files.get method.
+type GetFileConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+}
+
+// Optional parameters for files.delete method.
+type DeleteFileConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+}
+
+// Empty response for files.delete method.
+type DeleteFileResponse struct {
+}
+
+// Optional parameters for files.list method.
+type ListFilesConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+	// Maximum number of files to return per page.
+	PageSize int64 `json:"pageSize,omitempty"`
+	// Page token of the first page to return.
+	PageToken string `json:"pageToken,omitempty"`
+}
+
+// A page of files returned by files.list method.
+type ListFilesResponse struct {
+	// Token to retrieve the next page. Empty on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+	// The files of this page.
+	Files []*File `json:"files,omitempty"`
+}
+
+// A single example for tuning.
+type TuningExample struct {
+	// Text model input.
+	TextInput string `json:"textInput,omitempty"`
+	// The expected model output.
+	Output string `json:"output,omitempty"`
+}
+
+// Supervised fine-tuning training dataset.
+type TuningDataset struct {
+	// GCS URI of the file containing training dataset in JSONL format. Only
+	// supported in Vertex AI.
+	GCSURI string `json:"gcsUri,omitempty"`
+	// Inline examples. Only supported in Gemini API.
+	Examples []*TuningExample `json:"examples,omitempty"`
+}
+
+// Hyperparameters for supervised fine-tuning.
+type TuningHyperParameters struct {
+	// Number of complete passes the model makes over the entire training dataset
+	// during training.
+	EpochCount *int64 `json:"epochCount,omitempty"`
+	// Multiplier for adjusting the default learning rate.
+	LearningRateMultiplier *float64 `json:"learningRateMultiplier,omitempty"`
+	// The batch size hyperparameter for tuning. Only supported in Gemini API.
+	BatchSize *int64 `json:"batchSize,omitempty"`
+	// The learning rate hyperparameter for tuning. Only supported in Gemini API.
+	LearningRate *float64 `json:"learningRate,omitempty"`
+}
+
+// Supervised fine-tuning job creation request.
+type CreateTuningJobConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+	// The base model that is being tuned, for example "gemini-1.5-flash-002".
+	BaseModel string `json:"baseModel,omitempty"`
+	// The training dataset.
+	TrainingDataset *TuningDataset `json:"trainingDataset,omitempty"`
+	// Optional. Hyperparameters of the tuning. If unset, the service defaults apply.
+	HyperParameters *TuningHyperParameters `json:"hyperParameters,omitempty"`
+	// Optional. The display name of the tuned model.
+	TunedModelDisplayName string `json:"tunedModelDisplayName,omitempty"`
+}
+
+// Optional parameters for tunings.get method.
+type GetTuningJobConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+}
+
+// Optional parameters for tunings.list method.
+type ListTuningJobsConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+	// Maximum number of tuning jobs to return per page.
+	PageSize int64 `json:"pageSize,omitempty"`
+	// Page token of the first page to return.
+	PageToken string `json:"pageToken,omitempty"`
+	// Optional. A filter on the tuning jobs.
+	Filter string `json:"filter,omitempty"`
+}
+
+// Optional parameters for tunings.cancel method.
+type CancelTuningJobConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
+}
+
+// The error of a failed tuning job.
+type JobError struct {
+	// The status code.
+	Code int64 `json:"code,omitempty"`
+	// A developer-facing error message.
+	Message string `json:"message,omitempty"`
+	// A list of messages that carry the error details.
+	Details []map[string]any `json:"details,omitempty"`
+}
+
+// A tuning job.
+type TuningJob struct {
+	// Resource name of the job. In Vertex AI, for example
+	// "projects/123/locations/us-central1/tuningJobs/456". In Gemini API, the name of
+	// the tuned model, for example "tunedModels/my-model-123".
+	Name string `json:"name,omitempty"`
+	// The state of the job.
+	State JobState `json:"state,omitempty"`
+	// Only populated when the job's state is JobStateFailed or JobStateCancelled.
+	Error *JobError `json:"error,omitempty"`
+	// The base model that is being tuned.
+	BaseModel string `json:"baseModel,omitempty"`
+	// The resource name of the tuned model, to use as model in GenerateContent once
+	// the job succeeded.
+	TunedModelName string `json:"tunedModelName,omitempty"`
+	// Time when the job was created.
+	CreateTime *time.Time `json:"createTime,omitempty"`
+	// Time when the job for the first time entered the JobStateRunning state.
+	StartTime *time.Time `json:"startTime,omitempty"`
+	// Time when the job entered any of the JobStateSucceeded, JobStateFailed or
+	// JobStateCancelled states.
+	EndTime *time.Time `json:"endTime,omitempty"`
+	// Time when the job was most recently updated.
+	UpdateTime *time.Time `json:"updateTime,omitempty"`
+}
+
+// A page of tuning jobs returned by tunings.list method.
+type ListTuningJobsResponse struct {
+	// Token to retrieve the next page. Empty on the last page.
+	NextPageToken string `json:"nextPageToken,omitempty"`
+	// The tuning jobs of this page.
+	TuningJobs []*TuningJob `json:"tuningJobs,omitempty"`
+}
+
 type testTableItem struct {
 	// The name of the test. This is used to derive the replay id.
 	Name string `json:"name,omitempty"`
@@ -1526,14 +2428,19 @@ type replayFile struct {
 
 // Used to override the default configuration.
 type UploadFileConfig struct {
+	// Used to override HTTP request options.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 	// The name of the file in the destination (e.g., 'files/sample-image'. If not provided
 	// one will be generated.
 	Name string `json:"name,omitempty"`
 	// mime_type: The MIME type of the file. If not provided, it will be inferred from the
-	// file extension.
+	// first bytes of the content.
 	MIMEType string `json:"mimeType,omitempty"`
 	// Optional display name of the file.
 	DisplayName string `json:"displayName,omitempty"`
+	// Size of the chunks the content is uploaded in. Defaults to 8 MiB. Must be a
+	// multiple of 256 KiB.
+	ChunkSize int64 `json:"chunkSize,omitempty"`
 }
 
 // Configuration for upscaling an image.
@@ -1728,6 +2635,41 @@ type LiveServerMessage struct {
 	// Notification for the client that a previously issued `ToolCallMessage` with the specified
 	// `id`s should have been not executed and should be cancelled.
 	ToolCallCancellation *LiveServerToolCallCancellation `json:"toolCallCancellation,omitempty"`
+	// Update of the session resumption state.
+	SessionResumptionUpdate *LiveServerSessionResumptionUpdate `json:"sessionResumptionUpdate,omitempty"`
+}
+
+// IsTerminal reports whether the message completes the turn of the model, so that
+// the client can send the next message.
+func (m *LiveServerMessage) IsTerminal() bool {
+	return m != nil && m.ServerContent != nil && m.ServerContent.TurnComplete
+}
+
+// IsToolCall reports whether the message asks the client to execute function calls.
+func (m *LiveServerMessage) IsToolCall() bool {
+	return m != nil && m.ToolCall != nil
+}
+
+// IsSetupComplete reports whether the message acknowledges the setup of the session.
+func (m *LiveServerMessage) IsSetupComplete() bool {
+	return m != nil && m.SetupComplete != nil
+}
+
+// Update of the session resumption state. Only sent if the session was set up with
+// `session_resumption`.
+type LiveServerSessionResumptionUpdate struct {
+	// New handle that represents the state that can be resumed. Empty if the session
+	// is not resumable at this point.
+	NewHandle string `json:"newHandle,omitempty"`
+	// True if the session can be resumed at this point.
+	Resumable bool `json:"resumable,omitempty"`
+}
+
+// Configuration of session resumption.
+type SessionResumptionConfig struct {
+	// The handle of a previous session to resume. If empty, a new session is started
+	// and the server sends resumption handles for it.
+	Handle string `json:"handle,omitempty"`
 }
 
 // Message contains configuration that will apply for the duration of the streaming
@@ -1755,6 +2697,8 @@ type LiveClientSetup struct {
 	// external systems to perform an action, or set of actions, outside of
 	// knowledge and scope of the model.
 	Tools []*Tool `json:"tools,omitempty"`
+	// Configures session resumption.
+	SessionResumption *SessionResumptionConfig `json:"sessionResumption,omitempty"`
 }
 
 // Incremental update of the current conversation delivered from the client.
@@ -1833,4 +2777,21 @@ type LiveConnectConfig struct {
 	// external systems to perform an action, or set of actions, outside of
 	// knowledge and scope of the model.
 	Tools []*Tool `json:"tools,omitempty"`
+	// Optional. If non-zero, a WebSocket ping is sent at this interval to keep
+	// an idle session alive. Pongs are only processed while the session is being
+	// read from, for example by Session.Receive.
+	KeepAliveInterval time.Duration `json:"-"`
+	// Optional. How long to wait for the pong that answers a keepalive ping
+	// before closing the session. Defaults to KeepAliveInterval.
+	KeepAliveTimeout time.Duration `json:"-"`
+	// Optional. The WebSocket subprotocols offered to the server during the
+	// handshake, in order of preference. The one the server selects is returned by
+	// Session.Subprotocol.
+	Subprotocols []string `json:"-"`
+	// Optional. Configures session resumption. Set the handle to resume a previous
+	// session.
+	SessionResumption *SessionResumptionConfig `json:"sessionResumption,omitempty"`
+	// Optional. Used to override HTTP request options. Only BaseURL and
+	// ExtraHeaders apply to the WebSocket handshake.
+	HTTPOptions *HTTPOptions `json:"httpOptions,omitempty"`
 }
@@ -15,9 +15,18 @@
 package genai
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"reflect"
+	"slices"
+	"strings"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func createGenerateContentResponse(candidates []*Candidate) *GenerateContentResponse {
@@ -171,6 +180,220 @@ func TestFunctionCalls(t *testing.T) {
 			if !reflect.DeepEqual(result, tt.expectedFunctionCalls) {
 				t.Fatalf("expected function calls %v, got %v", tt.expectedFunctionCalls, result)
 			}
+
+			if len(tt.response.Candidates) > 0 {
+				candidateResult := tt.response.Candidates[0].FunctionCalls()
+				if !reflect.DeepEqual(candidateResult, tt.expectedFunctionCalls) {
+					t.Fatalf("expected first candidate function calls %v, got %v", tt.expectedFunctionCalls, candidateResult)
+				}
+			}
+		})
+	}
+}
+
+func TestCandidateFunctionCalls(t *testing.T) {
+	response := createGenerateContentResponse([]*Candidate{
+		{Content: &Content{Parts: []*Part{{FunctionCall: &FunctionCall{Name: "funcCall1", Args: map[string]any{"key1": "val1"}}}}}},
+		{Content: &Content{Parts: []*Part{
+			{Text: "calling functions"},
+			{FunctionCall: &FunctionCall{Name: "funcCall2", Args: map[string]any{"key2": "val2"}}},
+			{FunctionCall: &FunctionCall{Name: "funcCall3"}},
+		}}},
+		{Content: &Content{Parts: []*Part{{Text: "no function calls"}}}},
+		{},
+	})
+
+	tests := []struct {
+		name                  string
+		candidate             *Candidate
+		expectedFunctionCalls []*FunctionCall
+	}{
+		{
+			name:      "First Candidate",
+			candidate: response.Candidates[0],
+			expectedFunctionCalls: []*FunctionCall{
+				{Name: "funcCall1", Args: map[string]any{"key1": "val1"}},
+			},
+		},
+		{
+			name:      "Second Candidate With Mixed Parts",
+			candidate: response.Candidates[1],
+			expectedFunctionCalls: []*FunctionCall{
+				{Name: "funcCall2", Args: map[string]any{"key2": "val2"}},
+				{Name: "funcCall3"},
+			},
+		},
+		{
+			name:                  "Candidate Without FunctionCall",
+			candidate:             response.Candidates[2],
+			expectedFunctionCalls: nil,
+		},
+		{
+			name:                  "Candidate Without Content",
+			candidate:             response.Candidates[3],
+			expectedFunctionCalls: nil,
+		},
+		{
+			name:                  "Nil Candidate",
+			candidate:             nil,
+			expectedFunctionCalls: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.candidate.FunctionCalls()
+
+			if !reflect.DeepEqual(result, tt.expectedFunctionCalls) {
+				t.Fatalf("expected function calls %v, got %v", tt.expectedFunctionCalls, result)
+			}
+		})
+	}
+}
+
+func TestCandidateCodeExecution(t *testing.T) {
+	code := &ExecutableCode{Code: "print(1 + 1)", Language: LanguagePython}
+	result := &CodeExecutionResult{Outcome: OutcomeOK, Output: "2\n"}
+	mixed := &Candidate{Content: &Content{Parts: []*Part{
+		{Text: "Let me compute it."},
+		{ExecutableCode: code},
+		{CodeExecutionResult: result},
+		{Text: "The result is 2."},
+	}}}
+
+	tests := []struct {
+		name      string
+		candidate *Candidate
+		wantCodes []*ExecutableCode
+		want      []*CodeExecutionResult
+	}{
+		{
+			name:      "Mixed Parts",
+			candidate: mixed,
+			wantCodes: []*ExecutableCode{code},
+			want:      []*CodeExecutionResult{result},
+		},
+		{
+			name:      "Text Only",
+			candidate: &Candidate{Content: &Content{Parts: []*Part{{Text: "no code"}}}},
+		},
+		{
+			name:      "Candidate Without Content",
+			candidate: &Candidate{},
+		},
+		{
+			name:      "Nil Candidate",
+			candidate: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(tt.wantCodes, tt.candidate.ExecutableCodes()); diff != "" {
+				t.Errorf("ExecutableCodes() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.want, tt.candidate.CodeExecutionResults()); diff != "" {
+				t.Errorf("CodeExecutionResults() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("Response", func(t *testing.T) {
+		response := createGenerateContentResponse([]*Candidate{mixed, {}})
+		if diff := cmp.Diff([]*CodeExecutionResult{result}, response.CodeExecutionResults()); diff != "" {
+			t.Errorf("CodeExecutionResults() mismatch (-want +got):\n%s", diff)
+		}
+		if got := (&GenerateContentResponse{}).CodeExecutionResults(); got != nil {
+			t.Errorf("CodeExecutionResults() without candidates = %v, want nil", got)
+		}
+	})
+}
+
+func TestGroundingMetadata(t *testing.T) {
+	metadata := &GroundingMetadata{
+		WebSearchQueries: []string{"weather in Paris", "Paris forecast"},
+		SearchEntryPoint: &SearchEntryPoint{RenderedContent: "<div></div>"},
+	}
+	tests := []struct {
+		desc        string
+		candidates  []*Candidate
+		wantMeta    *GroundingMetadata
+		wantQueries []string
+	}{
+		{desc: "no candidates", candidates: nil},
+		{desc: "nil candidate", candidates: []*Candidate{nil}},
+		{desc: "nil metadata", candidates: []*Candidate{{FinishReason: FinishReasonStop}}},
+		{desc: "no queries", candidates: []*Candidate{{GroundingMetadata: &GroundingMetadata{}}}, wantMeta: &GroundingMetadata{}},
+		{desc: "populated", candidates: []*Candidate{{GroundingMetadata: metadata}}, wantMeta: metadata, wantQueries: metadata.WebSearchQueries},
+		{
+			desc:        "first candidate only",
+			candidates:  []*Candidate{{GroundingMetadata: metadata}, {GroundingMetadata: &GroundingMetadata{WebSearchQueries: []string{"other"}}}},
+			wantMeta:    metadata,
+			wantQueries: metadata.WebSearchQueries,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			resp := createGenerateContentResponse(tt.candidates)
+			if diff := cmp.Diff(tt.wantMeta, resp.GroundingMetadata()); diff != "" {
+				t.Errorf("GroundingMetadata() mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.wantQueries, resp.SearchQueries()); diff != "" {
+				t.Errorf("SearchQueries() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSafetyBlocked(t *testing.T) {
+	var (
+		ok         = &Candidate{FinishReason: FinishReasonStop, SafetyRatings: []*SafetyRating{{Category: HarmCategoryHarassment}}}
+		safety     = &Candidate{FinishReason: FinishReasonSafety}
+		ratingOnly = &Candidate{FinishReason: FinishReasonStop, SafetyRatings: []*SafetyRating{
+			{Category: HarmCategoryHarassment},
+			{Category: HarmCategoryDangerousContent, Blocked: true},
+		}}
+		both = &Candidate{FinishReason: FinishReasonSafety, SafetyRatings: []*SafetyRating{{Blocked: true}}}
+	)
+
+	candidateTests := []struct {
+		desc      string
+		candidate *Candidate
+		want      bool
+	}{
+		{desc: "nil", candidate: nil, want: false},
+		{desc: "empty", candidate: &Candidate{}, want: false},
+		{desc: "not blocked", candidate: ok, want: false},
+		{desc: "finish reason safety", candidate: safety, want: true},
+		{desc: "blocked rating", candidate: ratingOnly, want: true},
+		{desc: "finish reason and blocked rating", candidate: both, want: true},
+		{desc: "nil rating", candidate: &Candidate{SafetyRatings: []*SafetyRating{nil}}, want: false},
+		{desc: "other finish reason", candidate: &Candidate{FinishReason: FinishReasonRecitation}, want: false},
+	}
+	for _, tt := range candidateTests {
+		t.Run("Candidate/"+tt.desc, func(t *testing.T) {
+			if got := tt.candidate.SafetyBlocked(); got != tt.want {
+				t.Errorf("SafetyBlocked() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	responseTests := []struct {
+		desc       string
+		candidates []*Candidate
+		want       bool
+	}{
+		{desc: "no candidates", candidates: nil, want: false},
+		{desc: "one blocked", candidates: []*Candidate{safety}, want: true},
+		{desc: "one not blocked", candidates: []*Candidate{ok}, want: false},
+		{desc: "all blocked", candidates: []*Candidate{safety, ratingOnly, both}, want: true},
+		{desc: "some blocked", candidates: []*Candidate{safety, ok, ratingOnly}, want: false},
+	}
+	for _, tt := range responseTests {
+		t.Run("GenerateContentResponse/"+tt.desc, func(t *testing.T) {
+			if got := createGenerateContentResponse(tt.candidates).SafetyBlocked(); got != tt.want {
+				t.Errorf("SafetyBlocked() = %v, want %v", got, tt.want)
+			}
 		})
 	}
 }
@@ -191,6 +414,50 @@ func TestNewPartFromURI(t *testing.T) {
 	}
 }
 
+func TestNewPartFromGCS(t *testing.T) {
+	want := &Part{FileData: &FileData{FileURI: "gs://my-bucket/videos/clip.mp4", MIMEType: "video/mp4"}}
+	if diff := cmp.Diff(want, NewPartFromGCS("my-bucket", "videos/clip.mp4", "video/mp4")); diff != "" {
+		t.Errorf("NewPartFromGCS() mismatch (-want +got):\n%s", diff)
+	}
+
+	for _, tt := range []struct{ bucket, object, wantPanic string }{
+		{bucket: "gs://my-bucket", object: "clip.mp4", wantPanic: "must be a bucket name"},
+		{bucket: "my-bucket/videos", object: "clip.mp4", wantPanic: "must be a bucket name"},
+		{bucket: "", object: "clip.mp4", wantPanic: "must not be empty"},
+		{bucket: "my-bucket", object: "", wantPanic: "must not be empty"},
+	} {
+		t.Run(tt.bucket+"/"+tt.object, func(t *testing.T) {
+			defer func() {
+				r := recover()
+				if msg, _ := r.(string); !strings.Contains(msg, tt.wantPanic) {
+					t.Errorf("NewPartFromGCS(%q, %q) panic = %v, want it to contain %q", tt.bucket, tt.object, r, tt.wantPanic)
+				}
+			}()
+			NewPartFromGCS(tt.bucket, tt.object, "video/mp4")
+		})
+	}
+}
+
+func TestNewPartFromHTTP(t *testing.T) {
+	for _, uri := range []string{"https://example.com/image.png", "http://example.com/image.png"} {
+		want := &Part{FileData: &FileData{FileURI: uri, MIMEType: "image/png"}}
+		if diff := cmp.Diff(want, NewPartFromHTTP(uri, "image/png")); diff != "" {
+			t.Errorf("NewPartFromHTTP(%q) mismatch (-want +got):\n%s", uri, diff)
+		}
+	}
+
+	for _, uri := range []string{"gs://my-bucket/image.png", "ftp://example.com/image.png", "example.com/image.png", "https://", "://bad"} {
+		t.Run(uri, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("NewPartFromHTTP(%q) did not panic", uri)
+				}
+			}()
+			NewPartFromHTTP(uri, "image/png")
+		})
+	}
+}
+
 func TestNewPartFromText(t *testing.T) {
 	text := "Hello, world!"
 	expected := &Part{
@@ -298,3 +565,580 @@ func TestNewPartFromCodeExecutionResult(t *testing.T) {
 		t.Fatalf("expected %v, got %v", expected, result)
 	}
 }
+
+func TestPartDeepCopy(t *testing.T) {
+	tests := []struct {
+		desc   string
+		part   *Part
+		mutate func(*Part)
+	}{
+		{
+			desc:   "VideoMetadata",
+			part:   &Part{VideoMetadata: &VideoMetadata{StartOffset: "1s", EndOffset: "2s"}},
+			mutate: func(p *Part) { p.VideoMetadata.StartOffset = "0s" },
+		},
+		{
+			desc:   "CodeExecutionResult",
+			part:   &Part{CodeExecutionResult: &CodeExecutionResult{Outcome: OutcomeOK, Output: "42"}},
+			mutate: func(p *Part) { p.CodeExecutionResult.Output = "43" },
+		},
+		{
+			desc:   "ExecutableCode",
+			part:   &Part{ExecutableCode: &ExecutableCode{Code: "print(42)", Language: LanguagePython}},
+			mutate: func(p *Part) { p.ExecutableCode.Code = "print(43)" },
+		},
+		{
+			desc:   "FileData",
+			part:   NewPartFromURI("gs://bucket/file.pdf", "application/pdf"),
+			mutate: func(p *Part) { p.FileData.FileURI = "gs://bucket/other.pdf" },
+		},
+		{
+			desc: "FunctionCall",
+			part: NewPartFromFunctionCall("search", map[string]any{
+				"query":   "weather",
+				"filters": map[string]any{"cities": []any{"Paris", map[string]any{"name": "Rome"}}},
+			}),
+			mutate: func(p *Part) {
+				p.FunctionCall.Name = "find"
+				p.FunctionCall.Args["query"] = "news"
+				cities := p.FunctionCall.Args["filters"].(map[string]any)["cities"].([]any)
+				cities[0] = "London"
+				cities[1].(map[string]any)["name"] = "Milan"
+			},
+		},
+		{
+			desc: "FunctionResponse",
+			part: &Part{FunctionResponse: &FunctionResponse{ID: "1", Name: "search", Response: map[string]any{
+				"output": map[string]any{"temperatures": []any{20.5, 21.0}},
+			}}},
+			mutate: func(p *Part) {
+				p.FunctionResponse.Response["output"].(map[string]any)["temperatures"].([]any)[0] = 0.0
+			},
+		},
+		{
+			desc:   "InlineData",
+			part:   NewPartFromBytes([]byte{0x89, 0x50, 0x4e, 0x47}, "image/png"),
+			mutate: func(p *Part) { p.InlineData.Data[0] = 0 },
+		},
+		{
+			desc:   "Text",
+			part:   &Part{Text: "Hello", Thought: true},
+			mutate: func(p *Part) { p.Text = "Bye" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			want := tt.part.DeepCopy()
+			got := tt.part.DeepCopy()
+			if diff := cmp.Diff(tt.part, got); diff != "" {
+				t.Fatalf("DeepCopy() mismatch (-want +got):\n%s", diff)
+			}
+			tt.mutate(got)
+			if diff := cmp.Diff(want, tt.part); diff != "" {
+				t.Errorf("modifying the copy modified the original (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("nil", func(t *testing.T) {
+		var p *Part
+		if got := p.DeepCopy(); got != nil {
+			t.Errorf("DeepCopy() = %v, want nil", got)
+		}
+	})
+}
+
+func BenchmarkPartDeepCopy(b *testing.B) {
+	part := NewPartFromBytes(make([]byte, 10<<20), "video/mp4")
+	b.SetBytes(int64(len(part.InlineData.Data)))
+	for i := 0; i < b.N; i++ {
+		part.DeepCopy()
+	}
+}
+
+func TestPartAccessors(t *testing.T) {
+	call := &FunctionCall{Name: "getWeather"}
+	response := &FunctionResponse{Name: "getWeather"}
+	blob := &Blob{Data: []byte("data"), MIMEType: "image/png"}
+	fileData := &FileData{FileURI: "gs://bucket/file", MIMEType: "image/png"}
+	code := &ExecutableCode{Code: "print(1)", Language: LanguagePython}
+	result := &CodeExecutionResult{Outcome: OutcomeOK, Output: "1"}
+	part := &Part{
+		Text:                "hello",
+		FunctionCall:        call,
+		FunctionResponse:    response,
+		InlineData:          blob,
+		FileData:            fileData,
+		ExecutableCode:      code,
+		CodeExecutionResult: result,
+	}
+
+	tests := []struct {
+		desc    string
+		get     func(*Part) (any, bool)
+		want    any
+		wantNil any
+	}{
+		{desc: "AsText", get: func(p *Part) (any, bool) { return p.AsText() }, want: "hello", wantNil: ""},
+		{desc: "AsFunctionCall", get: func(p *Part) (any, bool) { return p.AsFunctionCall() }, want: call, wantNil: (*FunctionCall)(nil)},
+		{desc: "AsFunctionResponse", get: func(p *Part) (any, bool) { return p.AsFunctionResponse() }, want: response, wantNil: (*FunctionResponse)(nil)},
+		{desc: "AsInlineData", get: func(p *Part) (any, bool) { return p.AsInlineData() }, want: blob, wantNil: (*Blob)(nil)},
+		{desc: "AsFileData", get: func(p *Part) (any, bool) { return p.AsFileData() }, want: fileData, wantNil: (*FileData)(nil)},
+		{desc: "AsExecutableCode", get: func(p *Part) (any, bool) { return p.AsExecutableCode() }, want: code, wantNil: (*ExecutableCode)(nil)},
+		{desc: "AsCodeExecutionResult", get: func(p *Part) (any, bool) { return p.AsCodeExecutionResult() }, want: result, wantNil: (*CodeExecutionResult)(nil)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, ok := tt.get(part)
+			if !ok || got != tt.want {
+				t.Errorf("%s() = %v, %v, want %v, true", tt.desc, got, ok, tt.want)
+			}
+			for _, p := range []*Part{{}, nil} {
+				got, ok := tt.get(p)
+				if ok || got != tt.wantNil {
+					t.Errorf("%s() on %v = %v, %v, want %v, false", tt.desc, p, got, ok, tt.wantNil)
+				}
+			}
+		})
+	}
+}
+
+// TestPartJSON checks that each kind of part is encoded as the single field that
+// identifies it on the wire, and decodes back to the same part.
+func TestPartJSON(t *testing.T) {
+	tests := []struct {
+		desc     string
+		part     *Part
+		wantJSON string
+	}{
+		{
+			desc:     "text",
+			part:     &Part{Text: "hello"},
+			wantJSON: `{"text":"hello"}`,
+		},
+		{
+			desc:     "thought",
+			part:     &Part{Text: "thinking", Thought: true},
+			wantJSON: `{"thought":true,"text":"thinking"}`,
+		},
+		{
+			desc:     "inline data",
+			part:     &Part{InlineData: &Blob{Data: []byte("abc"), MIMEType: "image/png"}},
+			wantJSON: `{"inlineData":{"data":"YWJj","mimeType":"image/png"}}`,
+		},
+		{
+			desc:     "file data",
+			part:     &Part{FileData: &FileData{FileURI: "gs://bucket/image.png", MIMEType: "image/png"}, MediaResolution: MediaResolutionLow},
+			wantJSON: `{"fileData":{"fileUri":"gs://bucket/image.png","mimeType":"image/png"},"mediaResolution":"MEDIA_RESOLUTION_LOW"}`,
+		},
+		{
+			desc:     "function call",
+			part:     &Part{FunctionCall: &FunctionCall{ID: "call-1", Name: "getWeather", Args: map[string]any{"city": "Paris"}}},
+			wantJSON: `{"functionCall":{"id":"call-1","args":{"city":"Paris"},"name":"getWeather"}}`,
+		},
+		{
+			desc:     "function response",
+			part:     &Part{FunctionResponse: &FunctionResponse{Name: "getWeather", Response: map[string]any{"weather": "sunny"}}},
+			wantJSON: `{"functionResponse":{"name":"getWeather","response":{"weather":"sunny"}}}`,
+		},
+		{
+			desc:     "executable code",
+			part:     &Part{ExecutableCode: &ExecutableCode{Code: "print(1)", Language: LanguagePython}},
+			wantJSON: `{"executableCode":{"code":"print(1)","language":"PYTHON"}}`,
+		},
+		{
+			desc:     "code execution result",
+			part:     &Part{CodeExecutionResult: &CodeExecutionResult{Outcome: OutcomeOK, Output: "1"}},
+			wantJSON: `{"codeExecutionResult":{"outcome":"OUTCOME_OK","output":"1"}}`,
+		},
+		{
+			desc:     "video metadata",
+			part:     &Part{VideoMetadata: &VideoMetadata{StartOffset: "1s", EndOffset: "2s"}, FileData: &FileData{FileURI: "gs://bucket/video.mp4"}},
+			wantJSON: `{"videoMetadata":{"endOffset":"2s","startOffset":"1s"},"fileData":{"fileUri":"gs://bucket/video.mp4"}}`,
+		},
+		{
+			desc:     "empty",
+			part:     &Part{},
+			wantJSON: `{}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			got, err := json.Marshal(tt.part)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.wantJSON, string(got)); diff != "" {
+				t.Errorf("json.Marshal() mismatch (-want +got):\n%s", diff)
+			}
+
+			part := new(Part)
+			if err := json.Unmarshal(got, part); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+			if diff := cmp.Diff(tt.part, part); diff != "" {
+				t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCandidateThoughtText(t *testing.T) {
+	tests := []struct {
+		desc      string
+		candidate *Candidate
+		want      string
+	}{
+		{desc: "nil candidate", candidate: nil, want: ""},
+		{desc: "no content", candidate: &Candidate{}, want: ""},
+		{
+			desc: "thought parts only",
+			candidate: &Candidate{Content: &Content{Parts: []*Part{
+				{Text: "Let me think. ", Thought: true},
+				{Text: "The answer is 4."},
+				{Text: "Done.", Thought: true},
+			}}},
+			want: "Let me think. Done.",
+		},
+		{
+			desc:      "no thought parts",
+			candidate: &Candidate{Content: &Content{Parts: []*Part{{Text: "4"}}}},
+			want:      "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := tt.candidate.ThoughtText(); got != tt.want {
+				t.Errorf("ThoughtText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageMetadataTotals(t *testing.T) {
+	tests := []struct {
+		desc       string
+		usage      *GenerateContentResponseUsageMetadata
+		wantTokens int64
+		wantCost   float64
+	}{
+		{desc: "nil", usage: nil, wantTokens: 0, wantCost: 0},
+		{desc: "empty", usage: &GenerateContentResponseUsageMetadata{}, wantTokens: 0, wantCost: 0},
+		{
+			desc:       "prompt and candidates",
+			usage:      &GenerateContentResponseUsageMetadata{PromptTokenCount: Ptr[int64](600_000), CachedContentTokenCount: Ptr[int64](100_000), CandidatesTokenCount: Ptr[int64](400_000)},
+			wantTokens: 1_000_000,
+			wantCost:   0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := tt.usage.TotalTokens(); got != tt.wantTokens {
+				t.Errorf("TotalTokens() = %d, want %d", got, tt.wantTokens)
+			}
+			if got := tt.usage.EstimatedCostUSD(0.5); got != tt.wantCost {
+				t.Errorf("EstimatedCostUSD(0.5) = %v, want %v", got, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestCandidateOrdering(t *testing.T) {
+	c0 := &Candidate{Index: Ptr[int64](0), FinishMessage: "zero"}
+	c1 := &Candidate{Index: Ptr[int64](1), FinishMessage: "one"}
+	c2 := &Candidate{Index: Ptr[int64](2), FinishMessage: "two"}
+	noIndex := &Candidate{FinishMessage: "no index"}
+
+	tests := []struct {
+		desc       string
+		candidates []*Candidate
+		wantSorted []*Candidate
+		lookup     map[int]*Candidate
+	}{
+		{desc: "empty", candidates: nil, wantSorted: []*Candidate{}, lookup: map[int]*Candidate{0: nil}},
+		{desc: "sorted", candidates: []*Candidate{c0, c1, c2}, wantSorted: []*Candidate{c0, c1, c2}, lookup: map[int]*Candidate{0: c0, 2: c2, 3: nil, -1: nil}},
+		{desc: "unsorted", candidates: []*Candidate{c2, c0, c1}, wantSorted: []*Candidate{c0, c1, c2}, lookup: map[int]*Candidate{0: c0, 1: c1, 2: c2}},
+		{desc: "missing index uses position", candidates: []*Candidate{c2, noIndex}, wantSorted: []*Candidate{noIndex, c2}, lookup: map[int]*Candidate{0: nil, 1: noIndex, 2: c2}},
+		{desc: "missing index before indexed", candidates: []*Candidate{noIndex, c2, nil}, wantSorted: []*Candidate{noIndex, c2}, lookup: map[int]*Candidate{0: noIndex, 2: c2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			r := &GenerateContentResponse{Candidates: tt.candidates}
+			if diff := cmp.Diff(tt.wantSorted, r.SortedCandidates()); diff != "" {
+				t.Errorf("SortedCandidates() mismatch (-want +got):\n%s", diff)
+			}
+			for n, want := range tt.lookup {
+				got, ok := r.CandidateByIndex(n)
+				if got != want || ok != (want != nil) {
+					t.Errorf("CandidateByIndex(%d) = %v, %v, want %v, %v", n, got, ok, want, want != nil)
+				}
+			}
+		})
+	}
+}
+
+func TestCandidateNegativeIndex(t *testing.T) {
+	ac := &apiClient{clientConfig: &ClientConfig{Backend: BackendGeminiAPI}}
+	if _, err := candidateFromMldev(ac, map[string]any{"index": float64(-1)}, nil); err == nil {
+		t.Errorf("candidateFromMldev() succeeded, want error for negative index")
+	}
+	if _, err := candidateFromVertex(ac, map[string]any{"index": float64(-1)}, nil); err == nil {
+		t.Errorf("candidateFromVertex() succeeded, want error for negative index")
+	}
+	got, err := candidateFromMldev(ac, map[string]any{"index": float64(1)}, nil)
+	if err != nil {
+		t.Fatalf("candidateFromMldev() failed: %v", err)
+	}
+	if got["index"] != float64(1) {
+		t.Errorf("candidateFromMldev() index = %v, want 1", got["index"])
+	}
+}
+
+func TestDynamicRetrievalConfigValidate(t *testing.T) {
+	tests := []struct {
+		desc    string
+		config  *DynamicRetrievalConfig
+		wantErr bool
+	}{
+		{desc: "unset", config: &DynamicRetrievalConfig{}},
+		{desc: "nil", config: nil},
+		{desc: "lower bound", config: &DynamicRetrievalConfig{Mode: DynamicRetrievalConfigModeDynamic, DynamicThreshold: Ptr(0.0)}},
+		{desc: "upper bound", config: &DynamicRetrievalConfig{Mode: DynamicRetrievalConfigModeDynamic, DynamicThreshold: Ptr(1.0)}},
+		{desc: "unspecified mode", config: &DynamicRetrievalConfig{Mode: DynamicRetrievalConfigModeUnspecified, DynamicThreshold: Ptr(0.5)}},
+		{desc: "below range", config: &DynamicRetrievalConfig{DynamicThreshold: Ptr(-0.1)}, wantErr: true},
+		{desc: "above range", config: &DynamicRetrievalConfig{DynamicThreshold: Ptr(1.5)}, wantErr: true},
+		{desc: "unknown mode", config: &DynamicRetrievalConfig{Mode: "MODE_ALWAYS"}, wantErr: true},
+	}
+	converters := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	}{
+		{BackendGeminiAPI, toolToMldev},
+		{BackendVertexAI, toolToVertex},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			tool := make(map[string]any)
+			deepMarshal(&Tool{GoogleSearchRetrieval: &GoogleSearchRetrieval{DynamicRetrievalConfig: tt.config}}, &tool)
+			for _, c := range converters {
+				ac := &apiClient{clientConfig: &ClientConfig{Backend: c.backend}}
+				if _, err := c.converter(ac, tool, nil); (err != nil) != tt.wantErr {
+					t.Errorf("%s converter error = %v, wantErr %v", c.backend, err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestGoogleSearchRetrievalEmptyDynamicRetrievalConfig(t *testing.T) {
+	tests := []struct {
+		desc   string
+		config *DynamicRetrievalConfig
+		want   map[string]any
+	}{
+		{desc: "nil", config: nil, want: map[string]any{}},
+		{desc: "empty", config: &DynamicRetrievalConfig{}, want: map[string]any{}},
+		{
+			desc:   "populated",
+			config: &DynamicRetrievalConfig{Mode: DynamicRetrievalConfigModeDynamic, DynamicThreshold: Ptr(0.7)},
+			want:   map[string]any{"dynamicRetrievalConfig": map[string]any{"mode": "MODE_DYNAMIC", "dynamicThreshold": 0.7}},
+		},
+		{desc: "zero threshold", config: &DynamicRetrievalConfig{DynamicThreshold: Ptr(0.0)}, want: map[string]any{}},
+	}
+	converters := []struct {
+		backend   Backend
+		converter func(*apiClient, map[string]any, map[string]any) (map[string]any, error)
+	}{
+		{BackendGeminiAPI, googleSearchRetrievalToMldev},
+		{BackendVertexAI, googleSearchRetrievalToVertex},
+	}
+	for _, tt := range tests {
+		for _, c := range converters {
+			t.Run(fmt.Sprintf("%s %s", tt.desc, c.backend), func(t *testing.T) {
+				retrieval := make(map[string]any)
+				deepMarshal(&GoogleSearchRetrieval{DynamicRetrievalConfig: tt.config}, &retrieval)
+				ac := &apiClient{clientConfig: &ClientConfig{Backend: c.backend}}
+				got, err := c.converter(ac, retrieval, nil)
+				if err != nil {
+					t.Fatalf("converter failed: %v", err)
+				}
+				if diff := cmp.Diff(tt.want, got); diff != "" {
+					t.Errorf("converter mismatch (-want +got):\n%s", diff)
+				}
+			})
+		}
+	}
+}
+
+func TestGenerateContentResponseAudioData(t *testing.T) {
+	audio := NewPartFromBytes([]byte{0x01, 0x02, 0x03}, "audio/pcm;rate=24000")
+	image := NewPartFromBytes([]byte{0x89, 0x50}, "image/png")
+	tests := []struct {
+		desc         string
+		resp         *GenerateContentResponse
+		wantData     []byte
+		wantMIMEType string
+		wantErr      bool
+	}{
+		{
+			desc:         "single audio part",
+			resp:         &GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{{Text: "Here you go"}, audio}}}}},
+			wantData:     []byte{0x01, 0x02, 0x03},
+			wantMIMEType: "audio/pcm;rate=24000",
+		},
+		{
+			desc: "audio in second candidate",
+			resp: &GenerateContentResponse{Candidates: []*Candidate{
+				{Content: &Content{Parts: []*Part{image}}},
+				nil,
+				{Content: &Content{Parts: []*Part{audio, NewPartFromBytes([]byte{0x04}, "audio/wav")}}},
+			}},
+			wantData:     []byte{0x01, 0x02, 0x03},
+			wantMIMEType: "audio/pcm;rate=24000",
+		},
+		{desc: "no audio part", resp: &GenerateContentResponse{Candidates: []*Candidate{{Content: &Content{Parts: []*Part{image}}}}}, wantErr: true},
+		{desc: "no candidates", resp: &GenerateContentResponse{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			data, mimeType, err := tt.resp.AudioData()
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("AudioData() succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AudioData() failed: %v", err)
+			}
+			if diff := cmp.Diff(tt.wantData, data); diff != "" {
+				t.Errorf("AudioData() data mismatch (-want +got):\n%s", diff)
+			}
+			if mimeType != tt.wantMIMEType {
+				t.Errorf("AudioData() mimeType = %q, want %q", mimeType, tt.wantMIMEType)
+			}
+		})
+	}
+}
+
+func TestPartIsAudio(t *testing.T) {
+	tests := []struct {
+		part *Part
+		want bool
+	}{
+		{part: NewPartFromBytes(nil, "audio/pcm"), want: true},
+		{part: NewPartFromBytes(nil, "Audio/MP3"), want: true},
+		{part: NewPartFromBytes(nil, "image/png")},
+		{part: NewPartFromURI("gs://bucket/a.mp3", "audio/mp3")},
+		{part: NewPartFromText("audio/pcm")},
+		{part: nil},
+	}
+	for _, tt := range tests {
+		if got := tt.part.IsAudio(); got != tt.want {
+			t.Errorf("%+v.IsAudio() = %v, want %v", tt.part, got, tt.want)
+		}
+	}
+}
+
+// TestTypesJSONTagsOmitEmpty guards that every field of the public types is omitted
+// from request bodies when it is not set, so that it cannot override a server
+// default.
+func TestTypesJSONTagsOmitEmpty(t *testing.T) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "types.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || !spec.Name.IsExported() {
+			return true
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok {
+			return false
+		}
+		for _, field := range st.Fields.List {
+			if len(field.Names) == 0 || !field.Names[0].IsExported() {
+				continue
+			}
+			var tag string
+			if field.Tag != nil {
+				tag = reflect.StructTag(strings.Trim(field.Tag.Value, "`")).Get("json")
+			}
+			if _, opts, _ := strings.Cut(tag, ","); tag != "-" && !slices.Contains(strings.Split(opts, ","), "omitempty") {
+				t.Errorf("%s: %s.%s has json tag %q, want the omitempty option or \"-\"", fset.Position(field.Pos()), spec.Name.Name, field.Names[0].Name, tag)
+			}
+		}
+		return false
+	})
+}
+
+func TestLiveServerMessagePredicates(t *testing.T) {
+	tests := []struct {
+		desc                                  string
+		msg                                   *LiveServerMessage
+		wantTerminal, wantToolCall, wantSetup bool
+	}{
+		{desc: "nil", msg: nil},
+		{desc: "empty", msg: &LiveServerMessage{}},
+		{desc: "setup complete", msg: &LiveServerMessage{SetupComplete: &LiveServerSetupComplete{}}, wantSetup: true},
+		{desc: "partial content", msg: &LiveServerMessage{ServerContent: &LiveServerContent{ModelTurn: NewModelContent(NewPartFromText("Hel"))}}},
+		{desc: "interrupted", msg: &LiveServerMessage{ServerContent: &LiveServerContent{Interrupted: true}}},
+		{
+			desc:         "last content",
+			msg:          &LiveServerMessage{ServerContent: &LiveServerContent{ModelTurn: NewModelContent(NewPartFromText("lo")), TurnComplete: true}},
+			wantTerminal: true,
+		},
+		{desc: "turn complete", msg: &LiveServerMessage{ServerContent: &LiveServerContent{TurnComplete: true}}, wantTerminal: true},
+		{
+			desc:         "tool call",
+			msg:          &LiveServerMessage{ToolCall: &LiveServerToolCall{FunctionCalls: []*FunctionCall{{Name: "get_weather"}}}},
+			wantToolCall: true,
+		},
+		{desc: "tool call cancellation", msg: &LiveServerMessage{ToolCallCancellation: &LiveServerToolCallCancellation{IDs: []string{"call-1"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := tt.msg.IsTerminal(); got != tt.wantTerminal {
+				t.Errorf("IsTerminal() = %v, want %v", got, tt.wantTerminal)
+			}
+			if got := tt.msg.IsToolCall(); got != tt.wantToolCall {
+				t.Errorf("IsToolCall() = %v, want %v", got, tt.wantToolCall)
+			}
+			if got := tt.msg.IsSetupComplete(); got != tt.wantSetup {
+				t.Errorf("IsSetupComplete() = %v, want %v", got, tt.wantSetup)
+			}
+		})
+	}
+}
+
+func TestPartSizeBytes(t *testing.T) {
+	tests := []struct {
+		desc string
+		part *Part
+		want int64
+	}{
+		{desc: "nil", part: nil, want: 0},
+		{desc: "empty", part: &Part{}, want: 0},
+		{desc: "text", part: NewPartFromText("żółw"), want: 7},
+		{desc: "inline data", part: NewPartFromBytes(make([]byte, 1024), "image/png"), want: 1024},
+		{desc: "file data", part: NewPartFromURI("gs://bucket/a.mp4", "video/mp4"), want: 17},
+		{desc: "function call", part: NewPartFromFunctionCall("f", map[string]any{"a": 1}), want: 8},
+		{desc: "function call without args", part: NewPartFromFunctionCall("f", nil), want: 5},
+		{desc: "function response", part: NewPartFromFunctionResponse("f", map[string]any{"ok": true}), want: 12},
+		{desc: "executable code", part: NewPartFromExecutableCode("print(1)", LanguagePython), want: 8},
+		{desc: "code execution result", part: NewPartFromCodeExecutionResult(OutcomeOK, "1\n"), want: 2},
+		{desc: "video metadata", part: NewPartFromVideoMetadata("10s", "5s"), want: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			if got := tt.part.SizeBytes(); got != tt.want {
+				t.Errorf("SizeBytes() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}